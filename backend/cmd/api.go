@@ -2,17 +2,37 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/attachments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/authentication"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/bookmarks"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/comments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
 	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/env"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/feed"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/imageproc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/jobs"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/notifications"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/polls"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/posts"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/poststate"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/reports"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/revisions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/sanctions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/softdelete"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/storage"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/tags"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/topics"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/users"
 	"github.com/go-chi/chi/v5"
@@ -103,7 +123,8 @@ func (app *application) mount() http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:3000", "https://sakthi-dev-tech.github.io", "https://gossip-with-go-production.up.railway.app"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Accept", "Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Accept", "Content-Type", "Authorization", "If-None-Match", "If-Modified-Since", "If-Match"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -128,44 +149,272 @@ func (app *application) mount() http.Handler {
 
 	authService := authentication.NewService(queries, app.db)
 	authHandler := authentication.NewHandler(authService)
-	r.Post("/register", authHandler.CreateUser)
-	r.Post("/login", authHandler.LoginUser)
+	r.With(json.MaxBytes(app.config.limits.authBodyBytes)).Post("/register", authHandler.CreateUser)
+	r.With(json.MaxBytes(app.config.limits.authBodyBytes)).Post("/login", authHandler.LoginUser)
 
-	userService := users.NewService(queries, app.db)
+	// reads are served from the cache when one is configured, writes invalidate what they touched
+	userService := users.NewCachedService(users.NewService(queries, app.db), app.cache)
 	usersHandler := users.NewHandler(userService)
 
-	topicService := topics.NewService(queries, app.db)
+	topicService := topics.NewCachedService(topics.NewService(queries, app.db, app.config.softDelete.restoreWindow), queries, app.cache)
 	topicsHandler := topics.NewHandler(topicService)
 
-	postService := posts.NewService(queries, app.db)
+	filter := app.contentFilter()
+
+	postService := posts.NewCachedService(posts.NewService(queries, app.db, app.config.softDelete.restoreWindow, filter), queries, app.cache)
 	postsHandler := posts.NewHandler(postService)
 
-	commentService := comments.NewService(queries, app.db)
+	commentService := comments.NewCachedService(comments.NewService(queries, app.db, app.config.softDelete.restoreWindow, filter), queries, app.cache)
 	commentsHandler := comments.NewHandler(commentService)
 
+	revisionService := revisions.NewService(queries, app.db)
+	revisionsHandler := revisions.NewHandler(revisionService)
+
+	reportService := reports.NewCachedService(reports.NewService(queries, app.db), app.cache)
+	reportsHandler := reports.NewHandler(reportService)
+
+	sanctionService := sanctions.NewService(queries, app.db)
+	sanctionsHandler := sanctions.NewHandler(sanctionService)
+
+	auditService := audit.NewService(queries, app.db)
+	auditHandler := audit.NewHandler(auditService)
+
+	feedService := feed.NewCachedService(feed.NewService(queries, app.db), app.cache)
+	feedHandler := feed.NewHandler(feedService)
+
+	notificationService := notifications.NewService(queries, app.db)
+	notificationsHandler := notifications.NewHandler(notificationService)
+
+	bookmarkService := bookmarks.NewCachedService(bookmarks.NewService(queries, app.db), app.cache)
+	bookmarksHandler := bookmarks.NewHandler(bookmarkService)
+
+	pollService := polls.NewService(queries, app.db)
+	pollsHandler := polls.NewHandler(pollService)
+
+	attachmentService := attachments.NewService(queries, app.db, app.blobs, app.config.storage.maxUploadBytes, app.images)
+	attachmentsHandler := attachments.NewHandler(attachmentService)
+
+	tagService := tags.NewService(queries, app.db)
+	tagsHandler := tags.NewHandler(tagService)
+
+	contentFilterService := contentfilter.NewCachedService(contentfilter.NewService(queries, app.db), app.cache)
+	contentFilterHandler := contentfilter.NewHandler(contentFilterService)
+
 	// Protected routes - require JWT authentication
 	r.Group(func(r chi.Router) {
-		r.Use(JWTAuthMiddleware) // JWT authentication middleware
+		r.Use(JWTAuthMiddleware)           // JWT authentication middleware
+		r.Use(UserRoleMiddleware(queries)) // loads the role used for moderation checks
+		r.Use(SanctionMiddleware(queries)) // turns away banned users
 
+		// Read routes - still open to suspended users
 		r.Get("/fetchUserByUsername", usersHandler.FetchUserByUsername)
+		// reads answer conditional requests with a 304, Cache-Control is set per route from the config
+		// unlike the /fetchX routes these take what they read from the path, since browsers and caches key a GET on its URL
+		r.With(httpcache.Policy(app.config.httpCache.topics)).Get("/fetchTopics", topicsHandler.ListTopics)
+		r.With(httpcache.Policy(app.config.httpCache.posts)).Get("/topics/{id}/posts", postsHandler.ListPosts)
+		r.With(httpcache.Policy(app.config.httpCache.posts)).Get("/posts/{id}", postsHandler.GetPost)
+		r.With(httpcache.Policy(app.config.httpCache.comments)).Get("/posts/{id}/comments", commentsHandler.ListComments)
+		r.Post("/fetchRevisions", revisionsHandler.ListRevisions)
+		r.Post("/fetchRevisionDiff", revisionsHandler.DiffRevisions)
+		r.Get("/fetchSubscriptions", feedHandler.ListSubscriptions)
+		r.Post("/feed", feedHandler.Feed)
+		r.Post("/fetchSaved", bookmarksHandler.ListSaved)
+		r.Get("/fetchCollections", bookmarksHandler.ListCollections)
+		r.Post("/fetchFollowers", usersHandler.ListFollowers)
+		r.Post("/fetchFollowing", usersHandler.ListFollowing)
+		r.Post("/followingFeed", feedHandler.FollowingFeed)
+		r.Post("/fetchNotifications", notificationsHandler.ListNotifications)
+		r.Post("/fetchTags", tagsHandler.Autocomplete)
+		r.Get("/tags/{tag}/posts", tagsHandler.ListPosts)
+		r.Post("/fetchPollResults", pollsHandler.Results)
+		r.Post("/fetchAttachments", attachmentsHandler.List)
+		r.Get("/attachments/{id}", attachmentsHandler.Download)
+		r.Get("/attachments/{id}/status", attachmentsHandler.Details)
+
+		// Write routes
+		r.Group(func(r chi.Router) {
+			r.Use(RequireWriteAccess) // suspended users can read but not write
+
+			r.Post("/addTopic", topicsHandler.CreateTopic)
+			r.Put("/updateTopic", topicsHandler.UpdateTopic)
+			r.Delete("/deleteTopic", topicsHandler.DeleteTopic)
+			r.Put("/restoreTopic", topicsHandler.RestoreTopic)
+
+			r.With(json.MaxBytes(app.config.limits.contentBodyBytes)).Post("/addPost", postsHandler.CreatePost)
+			r.With(json.MaxBytes(app.config.limits.contentBodyBytes)).Put("/updatePost", postsHandler.UpdatePost)
+			r.Delete("/deletePost", postsHandler.DeletePost)
+			r.Put("/restorePost", postsHandler.RestorePost)
+			r.Put("/setPostState", postsHandler.SetState)
+			r.Post("/markTopicRead", postsHandler.MarkTopicRead)
+			r.Post("/votePoll", pollsHandler.Vote)
+			r.Put("/changePollVote", pollsHandler.ChangeVote)
+
+			// leave room on top of the file for the multipart framing
+			r.With(json.MaxBytes(app.config.storage.maxUploadBytes+json.DefaultMaxBodyBytes)).Post("/uploadAttachment", attachmentsHandler.Upload)
+
+			r.With(json.MaxBytes(app.config.limits.contentBodyBytes)).Post("/addComment", commentsHandler.CreateComment)
+			r.With(json.MaxBytes(app.config.limits.contentBodyBytes)).Put("/updateComment", commentsHandler.UpdateComment)
+			r.Delete("/deleteComment", commentsHandler.DeleteComment)
+			r.Put("/restoreComment", commentsHandler.RestoreComment)
+
+			r.Post("/reportContent", reportsHandler.FileReport)
+
+			r.Post("/subscribeTopic", feedHandler.Subscribe)
+			r.Delete("/unsubscribeTopic", feedHandler.Unsubscribe)
+
+			r.Post("/saveItem", bookmarksHandler.Save)
+			r.Delete("/unsaveItem", bookmarksHandler.Unsave)
+			r.Post("/addCollection", bookmarksHandler.CreateCollection)
+			r.Put("/renameCollection", bookmarksHandler.RenameCollection)
+			r.Delete("/deleteCollection", bookmarksHandler.DeleteCollection)
+
+			r.Post("/followUser", usersHandler.Follow)
+			r.Delete("/unfollowUser", usersHandler.Unfollow)
+			r.Put("/markNotificationsRead", notificationsHandler.MarkRead)
+		})
+
+		// Moderator routes
+		r.Group(func(r chi.Router) {
+			r.Use(RequireRole(users.RoleModerator, users.RoleAdmin))
+
+			r.Post("/moderation/fetchReportQueue", reportsHandler.ListQueue)
+			r.Post("/moderation/fetchReports", reportsHandler.ListReports)
+			r.Post("/moderation/fetchSanctions", sanctionsHandler.ListSanctions)
+			r.Post("/moderation/fetchTopicMutes", sanctionsHandler.ListTopicMutes)
+			r.Post("/moderation/fetchPendingContent", contentFilterHandler.ListPending)
+
+			r.Group(func(r chi.Router) {
+				r.Use(RequireWriteAccess)
+
+				r.Put("/moderation/resolveReports", reportsHandler.ResolveReports)
+				r.Post("/moderation/sanctionUser", sanctionsHandler.IssueSanction)
+				r.Put("/moderation/revokeSanction", sanctionsHandler.RevokeSanction)
+				r.Post("/moderation/muteUser", sanctionsHandler.MuteUser)
+				r.Delete("/moderation/unmuteUser", sanctionsHandler.UnmuteUser)
+				r.Put("/moderation/reviewContent", contentFilterHandler.ReviewContent)
+				r.Put("/moderation/restrictTag", tagsHandler.RestrictTag)
+			})
+		})
+
+		// Admin routes
+		r.Group(func(r chi.Router) {
+			r.Use(RequireRole(users.RoleAdmin))
+
+			// exposes runtime counters such as json_write_failures, along with the command line and memory stats
+			r.Handle("/debug/vars", expvar.Handler())
+
+			r.Post("/admin/fetchAuditLog", auditHandler.ListEntries)
+			r.Post("/admin/exportAuditLog", auditHandler.ExportEntries)
+			r.Get("/admin/fetchBannedWords", contentFilterHandler.ListBannedWords)
+
+			r.Group(func(r chi.Router) {
+				r.Use(RequireWriteAccess)
+
+				r.Put("/admin/updateUserRole", usersHandler.UpdateUserRole)
+				r.Post("/admin/addBannedWord", contentFilterHandler.AddBannedWord)
+				r.Delete("/admin/deleteBannedWord", contentFilterHandler.DeleteBannedWord)
+			})
+		})
+	})
+
+	return r
+}
+
+// openBlobStore
+// connect to the storage backend that uploads are kept in
+func openBlobStore(ctx context.Context, cfg storageConfig) (storage.BlobStore, error) {
+	switch cfg.backend {
+	case "local":
+		return storage.NewLocal(cfg.dir)
+	case "s3":
+		return storage.NewS3(ctx, cfg.s3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q, use local or s3", cfg.backend)
+	}
+}
+
+// openCache
+// set up the response cache, nil when caching is turned off
+func openCache(ctx context.Context, cfg cacheConfig) (*cache.Cache, error) {
+	switch cfg.backend {
+	case "none":
+		return nil, nil
+	}
+
+	// generations are kept for twice the TTL, which only keeps stale entries out when entries do expire
+	if cfg.ttl <= 0 {
+		return nil, fmt.Errorf("CACHE_TTL must be positive, got %s", cfg.ttl)
+	}
+
+	switch cfg.backend {
+	case "memory":
+		if cfg.maxEntries < 1 {
+			return nil, fmt.Errorf("CACHE_MAX_ENTRIES must be at least 1, got %d", cfg.maxEntries)
+		}
+		return cache.New(cache.NewMemory(cfg.maxEntries), cfg.ttl), nil
+	case "redis":
+		store, err := cache.NewRedis(ctx, cfg.redis)
+		if err != nil {
+			return nil, err
+		}
+		return cache.New(store, cfg.ttl), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q, use memory, redis or none", cfg.backend)
+	}
+}
 
-		r.Get("/fetchTopics", topicsHandler.ListTopics)
-		r.Post("/addTopic", topicsHandler.CreateTopic)
-		r.Put("/updateTopic", topicsHandler.UpdateTopic)
-		r.Delete("/deleteTopic", topicsHandler.DeleteTopic)
+// contentFilter
+// build the checks run against every new post and comment
+func (app *application) contentFilter() *contentfilter.Pipeline {
+	cfg := app.config.contentFilter
 
-		r.Post("/fetchPosts", postsHandler.ListPosts)
-		r.Post("/addPost", postsHandler.CreatePost)
-		r.Put("/updatePost", postsHandler.UpdatePost)
-		r.Delete("/deletePost", postsHandler.DeletePost)
+	return contentfilter.New(
+		contentfilter.BannedWords{},
+		contentfilter.LinkLimit{MaxLinks: int(cfg.newAccountMaxLinks), AccountAge: cfg.newAccountAge},
+		contentfilter.Duplicates{Window: cfg.duplicateWindow, MaxRepeats: cfg.duplicateMaxRepeats, MinLength: 40},
+	)
+}
 
-		r.Post("/fetchComments", commentsHandler.ListComments)
-		r.Post("/addComment", commentsHandler.CreateComment)
-		r.Put("/updateComment", commentsHandler.UpdateComment)
-		r.Delete("/deleteComment", commentsHandler.DeleteComment)
+// startJobs
+// launch the background jobs, they stop once ctx is cancelled
+func (app *application) startJobs(ctx context.Context) {
+	queries := repo.New(app.db)
+
+	// the jobs archive and purge in bulk, which the cache has to hear about
+	topicService := topics.NewCachedService(topics.NewService(queries, app.db, app.config.softDelete.restoreWindow), queries, app.cache)
+	postService := posts.NewCachedService(posts.NewService(queries, app.db, app.config.softDelete.restoreWindow, nil), queries, app.cache)
+	commentService := comments.NewCachedService(comments.NewService(queries, app.db, app.config.softDelete.restoreWindow, nil), queries, app.cache)
+	revisionService := revisions.NewService(queries, app.db)
+
+	go jobs.Run(ctx, "purge-deleted", app.config.softDelete.purgeInterval, func(ctx context.Context) error {
+		cutoff := softdelete.Cutoff(app.config.softDelete.retention)
+		_, err := jobs.PurgeDeleted(ctx, queries, cutoff,
+			jobs.Purge{Table: "comments", Purger: commentService},
+			jobs.Purge{Table: "posts", Purger: postService},
+			jobs.Purge{Table: "topics", Purger: topicService},
+			jobs.Purge{Table: "revisions", Purger: revisionService},
+		)
+		return err
 	})
 
-	return r
+	attachmentService := attachments.NewService(queries, app.db, app.blobs, app.config.storage.maxUploadBytes, nil)
+	go jobs.Run(ctx, "collect-orphaned-uploads", app.config.storage.gcInterval, func(ctx context.Context) error {
+		_, err := attachmentService.CollectOrphans(ctx, time.Now().UTC().Add(-app.config.storage.orphanTTL))
+		return err
+	})
+
+	// images left in processing by a full queue or a restart are queued again
+	app.images.Start(ctx)
+	go jobs.Run(ctx, "resume-image-processing", app.config.images.resumeInterval, func(ctx context.Context) error {
+		return app.images.ResumeStalled(ctx, time.Now().UTC().Add(-app.config.images.resumeInterval))
+	})
+
+	// auto-archiving is off unless an inactivity period is configured
+	if app.config.archive.inactivity > 0 {
+		go jobs.Run(ctx, "archive-inactive", app.config.archive.interval, func(ctx context.Context) error {
+			return jobs.ArchiveInactive(ctx, postService, poststate.Cutoff(app.config.archive.inactivity))
+		})
+	}
 }
 
 // run
@@ -187,15 +436,81 @@ func (app *application) run(h http.Handler) error {
 type application struct {
 	config config
 	db     *pgxpool.Pool
+	blobs  storage.BlobStore
+	images *attachments.Processor
+	cache  *cache.Cache
 }
 
 type config struct {
-	addr string // port
-	db   dbConfig
+	addr          string // port
+	db            dbConfig
+	limits        limitsConfig
+	softDelete    softDeleteConfig
+	contentFilter contentFilterConfig
+	archive       archiveConfig
+	storage       storageConfig
+	images        imagesConfig
+	httpCache     httpCacheConfig
+	cache         cacheConfig
 }
 
 type dbConfig struct {
-	dsn string // domain string
+	dsn         string // domain string
+	autoMigrate bool   // apply pending migrations before the server starts
+}
+
+// request body caps in bytes, applied per route with json.MaxBytes
+type limitsConfig struct {
+	authBodyBytes    int64 // register and login
+	contentBodyBytes int64 // creating and updating posts and comments
+}
+
+type softDeleteConfig struct {
+	restoreWindow time.Duration // how long owners can restore what they deleted
+	retention     time.Duration // how long deleted rows are kept before being purged
+	purgeInterval time.Duration // how often the purge job runs
+}
+
+type storageConfig struct {
+	backend        string // "local" or "s3"
+	dir            string // where the local backend keeps files
+	s3             storage.S3Config
+	maxUploadBytes int64
+	orphanTTL      time.Duration // how long an upload may stay unattached before it is removed
+	gcInterval     time.Duration // how often orphaned uploads are collected
+}
+
+type imagesConfig struct {
+	limits         imageproc.Limits
+	workers        int           // images processed at the same time
+	queueSize      int           // images waiting before new ones are left for the resume job
+	resumeInterval time.Duration // how often images stuck in processing are queued again
+}
+
+// Cache-Control sent by the read routes, clients revalidate with the ETag either way
+type httpCacheConfig struct {
+	topics   string // the topic listing
+	posts    string // post listings and post pages
+	comments string // comment listings
+}
+
+type cacheConfig struct {
+	backend    string        // "memory", "redis" or "none"
+	ttl        time.Duration // longest an entry is served, writes invalidate what they touch long before
+	maxEntries int           // entries the memory backend holds before dropping the least recently used
+	redis      cache.RedisConfig
+}
+
+type archiveConfig struct {
+	inactivity time.Duration // posts with no activity for this long are archived, 0 turns it off
+	interval   time.Duration // how often the archive job runs
+}
+
+type contentFilterConfig struct {
+	newAccountAge       time.Duration // accounts younger than this have their links limited
+	newAccountMaxLinks  int64         // links allowed per post or comment from a new account
+	duplicateWindow     time.Duration // how far back to look for the same text
+	duplicateMaxRepeats int64         // earlier copies allowed before the text is held for review
 }
 
 type UserClaims struct {
@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/authentication"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/backup"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/comments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/forumimport"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/jobs"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/posts"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/revisions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/topics"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/users"
+	"github.com/jackc/pgx/v5"
+)
+
+// command is a subcommand of the server binary, run as `server <name> [flags]`
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, api *application, args []string) error
+}
+
+// commands
+// every subcommand, in the order they are listed by `server help`
+var commands = []command{
+	{"serve", "start the HTTP server (the default when no command is given)", serve},
+	{"migrate", "apply or roll back database migrations: migrate [up|down|status|version]", migrateCommand},
+	{"create-admin", "create an admin account, or promote an existing user to admin", createAdmin},
+	{"reset-password", "set a new password for an account", resetPassword},
+	{"purge-deleted", "hard delete soft deleted topics, posts and comments past the retention period", purgeDeleted},
+	{"reindex-search", "render and hash the text of every post and comment again", reindexSearch},
+	{"repair-counters", "recount the comments of every post and the posts of every topic", repairCounters},
+	{"export", "write the forum to a JSON Lines or tar export", exportData},
+	{"import", "check an export and load it into this database, safe to run again if it stops halfway", importData},
+	{"import-forum", "import another forum from a Discourse or phpBB dump, or the intermediate format", importForum},
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: server [command] [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run `server <command> -h` for the flags of a command")
+}
+
+// newFlagSet returns the flags of a subcommand, parse errors are returned instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+func migrateCommand(ctx context.Context, api *application, args []string) error {
+	return migrate(ctx, api.db, args)
+}
+
+// createAdmin
+// create the account with a hashed password when it does not exist yet, then give it the admin role
+func createAdmin(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("create-admin")
+	username := fs.String("username", "", "account to create or promote (required)")
+	password := fs.String("password", "", "password for a new account, read from stdin when left out")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return fmt.Errorf("-username is required")
+	}
+
+	queries := repo.New(api.db)
+	userService := users.NewService(queries, api.db)
+
+	var userID int64
+	profile, err := userService.FetchUserByUsername(ctx, *username, 0)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		if *password == "" {
+			if *password, err = readPassword(); err != nil {
+				return err
+			}
+		}
+
+		user, err := authentication.NewService(queries, api.db).CreateUser(ctx, repo.CreateUserParams{
+			Username: *username,
+			Password: *password,
+		})
+		if err != nil {
+			return err
+		}
+		slog.Info("created user", "id", user.ID, "username", user.Username)
+		userID = user.ID
+
+	case err != nil:
+		return err
+
+	case profile.Role == users.RoleAdmin:
+		slog.Info("user is already an admin", "id", profile.ID, "username", profile.Username)
+		return nil
+
+	default:
+		if *password != "" {
+			slog.Warn("the account already exists, -password was ignored, use reset-password to change it")
+		}
+		userID = profile.ID
+	}
+
+	// no admin ID, the audit entry is written without an actor like every change made from the command line
+	user, err := userService.UpdateUserRole(ctx, userID, users.RoleAdmin, 0)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("user is now an admin", "id", user.ID, "username", user.Username)
+	return nil
+}
+
+func resetPassword(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("reset-password")
+	username := fs.String("username", "", "account to reset (required)")
+	password := fs.String("password", "", "the new password, read from stdin when left out")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return fmt.Errorf("-username is required")
+	}
+
+	if *password == "" {
+		var err error
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+
+	user, err := authentication.NewService(repo.New(api.db), api.db).ResetPassword(ctx, *username, *password)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("password reset", "id", user.ID, "username", user.Username)
+	return nil
+}
+
+// purgeDeleted
+// run the purge job once, with the retention period from the environment unless -older-than is given
+func purgeDeleted(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("purge-deleted")
+	olderThan := fs.Duration("older-than", api.config.softDelete.retention, "purge rows deleted longer ago than this")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	queries := repo.New(api.db)
+	restoreWindow := api.config.softDelete.restoreWindow
+
+	cutoff := time.Now().UTC().Add(-*olderThan)
+	n, err := jobs.PurgeDeleted(ctx, queries, cutoff,
+		jobs.Purge{Table: "comments", Purger: comments.NewService(queries, api.db, restoreWindow, nil)},
+		jobs.Purge{Table: "posts", Purger: posts.NewService(queries, api.db, restoreWindow, nil)},
+		jobs.Purge{Table: "topics", Purger: topics.NewService(queries, api.db, restoreWindow)},
+		jobs.Purge{Table: "revisions", Purger: revisions.NewService(queries, api.db)},
+	)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("purge finished", "rows", n, "cutoff", cutoff)
+	return clearCache(ctx, api)
+}
+
+// reindexSearch
+// rebuild the cached HTML and the duplicate detection hash of every post and comment
+// Run it after changing the markdown renderer or the hashing rules
+func reindexSearch(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("reindex-search")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	queries := repo.New(api.db)
+	restoreWindow := api.config.softDelete.restoreWindow
+
+	n, err := posts.NewService(queries, api.db, restoreWindow, nil).Reindex(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("reindexed posts", "rows", n)
+
+	n, err = comments.NewService(queries, api.db, restoreWindow, nil).Reindex(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("reindexed comments", "rows", n)
+
+	return nil
+}
+
+// repairCounters
+// recompute comment_count, last_activity_at, post_count and last_post_at from the rows they count
+// The triggers keep them right, this is for databases edited by hand or restored from an old dump
+func repairCounters(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("repair-counters")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	queries := repo.New(api.db)
+	restoreWindow := api.config.softDelete.restoreWindow
+
+	n, err := posts.NewService(queries, api.db, restoreWindow, nil).RepairCounters(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("repaired post counters", "rows", n)
+
+	n, err = topics.NewService(queries, api.db, restoreWindow).RepairCounters(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("repaired topic counters", "rows", n)
+
+	return clearCache(ctx, api)
+}
+
+func exportData(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("export")
+	out := fs.String("out", "-", "file to write the export to, - for stdout")
+	archive := fs.String("archive", backup.ArchiveJSONL, "jsonl or tar")
+	withPasswords := fs.Bool("with-passwords", false, "include password hashes so accounts can log in straight after an import")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *withPasswords {
+		slog.Warn("the export includes password hashes, keep it somewhere safe")
+	}
+
+	counts, err := backup.NewService(repo.New(api.db), api.db).Export(ctx, w, backup.ExportOptions{
+		IncludePasswords: *withPasswords,
+		Archive:          *archive,
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("export finished", countAttrs(counts)...)
+	return nil
+}
+
+// importData
+// check the whole export first so a broken file is rejected before anything is written, then import it
+func importData(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("import")
+	in := fs.String("in", "-", "export to read, - for stdin")
+	check := fs.Bool("check", false, "only check the export, without importing it")
+	mergeUsers := fs.Bool("merge-users", false, "put exported users on the local accounts with the same username instead of renaming them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// the export is read twice, so stdin is copied to a temporary file first
+	var f *os.File
+	if *in == "-" {
+		tmp, err := os.CreateTemp("", "gossip-import-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if _, err := io.Copy(tmp, os.Stdin); err != nil {
+			return err
+		}
+		f = tmp
+	} else {
+		opened, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer opened.Close()
+		f = opened
+	}
+
+	service := backup.NewService(repo.New(api.db), api.db)
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	counts, err := service.Validate(ctx, f)
+	if err != nil {
+		return err
+	}
+	slog.Info("export is valid", countAttrs(counts)...)
+	if *check {
+		return nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	report, err := service.Import(ctx, f, backup.ImportOptions{MergeUsers: *mergeUsers})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("import finished", countAttrs(report.Imported)...)
+	for _, renamed := range report.RenamedUsers {
+		slog.Warn("username was taken by a local account, the exported user was imported under a new name", "username", renamed.From, "imported_as", renamed.To)
+	}
+	if len(report.MergedUsers) > 0 {
+		slog.Warn("exported users were merged into local accounts with the same username", "count", len(report.MergedUsers), "usernames", report.MergedUsers)
+	}
+	if len(report.Skipped) > 0 {
+		slog.Info("skipped records imported by an earlier run", countAttrs(report.Skipped)...)
+	}
+	return clearCache(ctx, api)
+}
+
+// importForum
+// read another forum through its adapter, convert it into an export and import that like any other
+// With -out the adapter's output is written in the intermediate format instead, which needs no database
+func importForum(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("import-forum")
+	from := fs.String("from", "", "forum, discourse or phpbb (required)")
+	in := fs.String("in", "-", "dump to read, - for stdin")
+	out := fs.String("out", "", "only write what was read in the intermediate format to this file")
+	reportPath := fs.String("report", "", "write the list of placeholders and skipped records to this JSON file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var data []byte
+	var err error
+	if *in == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		return err
+	}
+
+	var forum forumimport.Forum
+	switch *from {
+	case forumimport.SourceForum:
+		forum, err = forumimport.ReadForum(bytes.NewReader(data))
+	case forumimport.SourceDiscourse:
+		forum, err = forumimport.ReadDiscourse(bytes.NewReader(data))
+	case forumimport.SourcePhpBB:
+		forum, err = forumimport.ReadPhpBB(bytes.NewReader(data))
+	default:
+		return forumimport.ErrUnknownSource
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := forumimport.WriteForum(f, forum); err != nil {
+			return err
+		}
+		slog.Info("wrote intermediate format", "users", len(forum.Users), "categories", len(forum.Categories),
+			"threads", len(forum.Threads), "replies", len(forum.Replies), "skipped", len(forum.Skipped))
+		return writeForumReport(*reportPath, forumimport.Report{Skipped: forum.Skipped})
+	}
+
+	tmp, err := os.CreateTemp("", "gossip-forum-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// the same dump always converts to the same export ID, so importing it again skips what is already there
+	sum := sha256.Sum256(data)
+	// only the banned words apply, the other checks look at authors that don't exist until the import
+	queries := repo.New(api.db)
+	filter := contentfilter.New(contentfilter.BannedWords{})
+	report, err := forumimport.Convert(ctx, tmp, forum, *from, *from+"-"+hex.EncodeToString(sum[:16]), queries, filter)
+	if err != nil {
+		return err
+	}
+	slog.Info("converted forum", "users", report.Converted[forumimport.TypeUser], "categories", report.Converted[forumimport.TypeCategory],
+		"threads", report.Converted[forumimport.TypeThread], "replies", report.Converted[forumimport.TypeReply],
+		"placeholders", len(report.Placeholders), "renamed", len(report.Renamed), "skipped", len(report.Skipped))
+	if err := writeForumReport(*reportPath, report); err != nil {
+		return err
+	}
+
+	service := backup.NewService(queries, api.db)
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := service.Validate(ctx, tmp); err != nil {
+		return err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	// the conversion already picked usernames no local account has, so this only renames one taken in the meantime
+	imported, err := service.Import(ctx, tmp, backup.ImportOptions{})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("import finished", countAttrs(imported.Imported)...)
+	for _, renamed := range imported.RenamedUsers {
+		slog.Warn("username was taken by a local account, the forum user was imported under a new name", "username", renamed.From, "imported_as", renamed.To)
+	}
+	if len(imported.Skipped) > 0 {
+		slog.Info("skipped records imported by an earlier run", countAttrs(imported.Skipped)...)
+	}
+	return clearCache(ctx, api)
+}
+
+// clearCache
+// drop every cached response after a command wrote to the database without going through the cached services
+// A memory cache belongs to the running server and can't be reached from here, its entries show the change once they expire
+func clearCache(ctx context.Context, api *application) error {
+	if api.config.cache.backend == "memory" {
+		return nil
+	}
+
+	responses, err := openCache(ctx, api.config.cache)
+	if err != nil {
+		return fmt.Errorf("opening the response cache to clear it: %w", err)
+	}
+	responses.Invalidate(ctx, cache.All)
+	return nil
+}
+
+// writeForumReport saves the report of a forum import, or logs the skipped records when there is no file to write to
+func writeForumReport(path string, report forumimport.Report) error {
+	if path == "" {
+		for _, s := range report.Skipped {
+			slog.Warn("skipped record", "type", s.Type, "id", s.ID, "reason", s.Reason)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// countAttrs turns record counts into log attributes, in the order the kinds appear in an export
+func countAttrs(counts backup.Counts) []any {
+	var attrs []any
+	for _, kind := range backup.Kinds {
+		if n, ok := counts[kind]; ok {
+			attrs = append(attrs, kind, n)
+		}
+	}
+	return attrs
+}
+
+// readPassword reads a password from the first line of stdin, so it stays out of the shell history
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password is required")
+	}
+	return password, nil
+}
@@ -2,10 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log/slog"
 	"os"
+	"time"
 
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/attachments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/env"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/imageproc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/storage"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 )
@@ -21,13 +30,93 @@ func main() {
 	cfg := config{
 		addr: ":" + env.GetString("PORT", "8080"),
 		db: dbConfig{
-			dsn: env.GetString("DATABASE_URL", "host=localhost user=postgres password=Sakthi2004 dbname=Gossip-With-Go sslmode=disable"),
+			dsn:         env.GetString("DATABASE_URL", "host=localhost user=postgres password=Sakthi2004 dbname=Gossip-With-Go sslmode=disable"),
+			autoMigrate: env.GetBool("AUTO_MIGRATE", false),
+		},
+		limits: limitsConfig{
+			authBodyBytes:    env.GetInt64("MAX_AUTH_BODY_BYTES", 4<<10),      // 4 KB
+			contentBodyBytes: env.GetInt64("MAX_CONTENT_BODY_BYTES", 256<<10), // 256 KB
+		},
+		softDelete: softDeleteConfig{
+			restoreWindow: env.GetDuration("RESTORE_WINDOW", 72*time.Hour),
+			retention:     env.GetDuration("DELETED_RETENTION", 30*24*time.Hour),
+			purgeInterval: env.GetDuration("PURGE_INTERVAL", time.Hour),
+		},
+		contentFilter: contentFilterConfig{
+			newAccountAge:       env.GetDuration("NEW_ACCOUNT_AGE", 72*time.Hour),
+			newAccountMaxLinks:  env.GetInt64("NEW_ACCOUNT_MAX_LINKS", 2),
+			duplicateWindow:     env.GetDuration("DUPLICATE_WINDOW", 24*time.Hour),
+			duplicateMaxRepeats: env.GetInt64("DUPLICATE_MAX_REPEATS", 2),
+		},
+		storage: storageConfig{
+			backend: env.GetString("STORAGE_BACKEND", "local"),
+			dir:     env.GetString("STORAGE_DIR", "./uploads"),
+			s3: storage.S3Config{
+				Endpoint:  env.GetString("S3_ENDPOINT", "localhost:9000"),
+				Bucket:    env.GetString("S3_BUCKET", "gossip-uploads"),
+				AccessKey: env.GetString("S3_ACCESS_KEY", ""),
+				SecretKey: env.GetString("S3_SECRET_KEY", ""),
+				Region:    env.GetString("S3_REGION", ""),
+				UseSSL:    env.GetBool("S3_USE_SSL", false),
+			},
+			maxUploadBytes: env.GetInt64("MAX_UPLOAD_BYTES", 10<<20), // 10 MB
+			orphanTTL:      env.GetDuration("ORPHAN_UPLOAD_TTL", 24*time.Hour),
+			gcInterval:     env.GetDuration("ORPHAN_GC_INTERVAL", time.Hour),
+		},
+		images: imagesConfig{
+			limits: imageproc.Limits{
+				MaxPixels: env.GetInt64("IMAGE_MAX_PIXELS", 40_000_000),
+				MaxSide:   int(env.GetInt64("IMAGE_MAX_SIDE", 12_000)),
+			},
+			workers:        int(env.GetInt64("IMAGE_WORKERS", 2)),
+			queueSize:      int(env.GetInt64("IMAGE_QUEUE_SIZE", 64)),
+			resumeInterval: env.GetDuration("IMAGE_RESUME_INTERVAL", 5*time.Minute),
+		},
+		archive: archiveConfig{
+			inactivity: env.GetDuration("ARCHIVE_AFTER", 0),
+			interval:   env.GetDuration("ARCHIVE_INTERVAL", time.Hour),
+		},
+		httpCache: httpCacheConfig{
+			topics:   env.GetString("CACHE_CONTROL_TOPICS", httpcache.DefaultPolicy),
+			posts:    env.GetString("CACHE_CONTROL_POSTS", httpcache.DefaultPolicy),
+			comments: env.GetString("CACHE_CONTROL_COMMENTS", httpcache.DefaultPolicy),
+		},
+		cache: cacheConfig{
+			backend:    env.GetString("CACHE_BACKEND", "memory"),
+			ttl:        env.GetDuration("CACHE_TTL", 5*time.Minute),
+			maxEntries: int(env.GetInt64("CACHE_MAX_ENTRIES", 10_000)),
+			redis: cache.RedisConfig{
+				Addr:     env.GetString("REDIS_ADDR", "localhost:6379"),
+				Password: env.GetString("REDIS_PASSWORD", ""),
+				DB:       int(env.GetInt64("REDIS_DB", 0)),
+				Prefix:   env.GetString("REDIS_PREFIX", "gossip:"),
+			},
 		},
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	// `server <command> [flags]`, with no command the HTTP server is started
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	// the server logs to stdout as before, the other commands keep it free for their own output such as an export
+	logOutput := os.Stdout
+	if command != "serve" {
+		logOutput = os.Stderr
+	}
+	logger := slog.New(slog.NewTextHandler(logOutput, nil))
 	slog.SetDefault(logger) // for a more structured logging
 
+	cmd, ok := findCommand(command)
+	if !ok {
+		printUsage()
+		if command == "help" || command == "-h" || command == "--help" {
+			return
+		}
+		os.Exit(2)
+	}
+
 	// Database - migrated to using connection pool for better concurrency and reduced costs
 	pool, err := pgxpool.New(ctx, cfg.db.dsn)
 	if err != nil {
@@ -37,13 +126,54 @@ func main() {
 
 	logger.Info("connected to database pool", "dsn", cfg.db.dsn)
 
-	api := application{
+	api := &application{
 		config: cfg,
 		db:     pool,
 	}
 
-	if err := api.run(api.mount()); err != nil {
-		slog.Error("server has failed", "error", err)
+	if err := cmd.run(ctx, api, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		slog.Error(command+" has failed", "error", err)
+		pool.Close()
 		os.Exit(1)
 	}
 }
+
+// serve
+// start the HTTP server along with the background jobs, this is what the binary does when no command is given
+func serve(ctx context.Context, api *application, args []string) error {
+	fs := newFlagSet("serve")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if api.config.db.autoMigrate {
+		if err := migrate(ctx, api.db, nil); err != nil {
+			return err
+		}
+	}
+
+	blobs, err := openBlobStore(ctx, api.config.storage)
+	if err != nil {
+		return err
+	}
+	api.blobs = blobs
+
+	slog.Info("opened blob store", "backend", api.config.storage.backend)
+
+	responses, err := openCache(ctx, api.config.cache)
+	if err != nil {
+		return err
+	}
+	api.cache = responses
+
+	slog.Info("opened response cache", "backend", api.config.cache.backend)
+
+	api.images = attachments.NewProcessor(repo.New(api.db), blobs, api.config.images.limits, api.config.images.workers, api.config.images.queueSize)
+
+	api.startJobs(ctx)
+
+	return api.run(api.mount())
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/sanctions"
+	"github.com/jackc/pgx/v5"
+)
+
+// Attach the user's current role to the request context
+// The role is looked up on every request instead of being stored in the JWT so that changes apply immediately
+func UserRoleMiddleware(queries *repo.Queries) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+			if !ok {
+				http.Error(w, "Unauthorised Access", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := queries.FetchUserByID(r.Context(), userID)
+			if err != nil {
+				// the account behind a still valid token has been removed
+				if errors.Is(err, pgx.ErrNoRows) {
+					http.Error(w, "Unauthorised Access", http.StatusUnauthorized)
+					return
+				}
+				log.Println(err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), appctx.RoleKey, user.Role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Restrict a group of routes to the given roles, must run after UserRoleMiddleware
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(appctx.RoleKey).(string)
+			if !slices.Contains(roles, role) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Reject banned users outright and remember suspensions so RequireWriteAccess can turn them away from writes
+// Checked per request so a ban applies to tokens that were issued before it
+func SanctionMiddleware(queries *repo.Queries) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+			if !ok {
+				http.Error(w, "Unauthorised Access", http.StatusUnauthorized)
+				return
+			}
+
+			sanction, err := queries.GetActiveSanction(r.Context(), userID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				log.Println(err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if sanction.Kind == sanctions.KindBan {
+				http.Error(w, sanctions.Message(sanction), http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), appctx.SanctionKey, sanction)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Turn suspended users away from routes that change anything, must run after SanctionMiddleware
+func RequireWriteAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sanction, ok := r.Context().Value(appctx.SanctionKey).(repo.UserSanction); ok {
+			http.Error(w, sanctions.Message(sanction), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
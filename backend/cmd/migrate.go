@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+const migrateUsage = "usage: server migrate [up|down|status|version]"
+
+// migrate
+// run one of the migrate subcommands against the database
+func migrate(ctx context.Context, pool *pgxpool.Pool, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("too many arguments\n%s", migrateUsage)
+	}
+
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	provider, err := migrations.NewProvider(db)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		results, err := provider.Up(ctx)
+		for _, r := range results {
+			slog.Info("applied migration", "version", r.Source.Version, "file", r.Source.Path, "duration", r.Duration)
+		}
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			slog.Info("database is already up to date")
+		}
+
+	case "down":
+		r, err := provider.Down(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("rolled back migration", "version", r.Source.Version, "file", r.Source.Path, "duration", r.Duration)
+
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+		for _, s := range statuses {
+			appliedAt := "-"
+			if s.State == goose.StateApplied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, appliedAt, s.Source.Path)
+		}
+		return w.Flush()
+
+	case "version":
+		current, target, err := provider.GetVersions(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("current version %d, latest available %d\n", current, target)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", command, migrateUsage)
+	}
+
+	return nil
+}
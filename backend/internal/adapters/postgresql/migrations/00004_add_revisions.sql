@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Roles used for moderation, everyone starts out as a regular user
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
+
+-- updated_at stays NULL until the first edit so readers can tell edited content apart
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS edit_count INT NOT NULL DEFAULT 0;
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS edit_count INT NOT NULL DEFAULT 0;
+
+-- Snapshot of a post or comment taken right before it gets edited
+-- revision is the edit_count of the row at the time, so revision 0 is the original text
+-- title is only set for posts
+CREATE TABLE IF NOT EXISTS revisions (
+    id BIGSERIAL PRIMARY KEY,
+    target_type TEXT NOT NULL CHECK (target_type IN ('post', 'comment')),
+    target_id BIGINT NOT NULL,
+    revision INT NOT NULL,
+    title TEXT,
+    content TEXT NOT NULL,
+    edited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (target_type, target_id, revision)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS revisions;
+ALTER TABLE comments DROP COLUMN IF EXISTS edit_count, DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS edit_count, DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
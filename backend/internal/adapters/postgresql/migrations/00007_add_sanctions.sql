@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Site wide sanctions, a ban blocks the account entirely while a suspension leaves it read only
+-- expires_at is NULL for sanctions that never expire
+CREATE TABLE IF NOT EXISTS user_sanctions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('ban', 'suspension')),
+    reason TEXT NOT NULL,
+    issued_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoked_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sanctions_user_id ON user_sanctions(user_id);
+
+-- Stops a user from posting or commenting in a single topic
+CREATE TABLE IF NOT EXISTS topic_mutes (
+    topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    muted_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (topic_id, user_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS topic_mutes;
+DROP TABLE IF EXISTS user_sanctions;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- request_id ties an entry back to the access logs, before/after hold the row on either side of the change
+ALTER TABLE audit_log
+    ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS before_state JSONB,
+    ADD COLUMN IF NOT EXISTS after_state JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+
+-- The audit log is append-only
+-- The one exception is the actor being cleared by ON DELETE SET NULL when their account is removed
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.actor_id IS NULL
+        AND (NEW.id, NEW.request_id, NEW.action, NEW.target_type, NEW.target_id, NEW.details, NEW.before_state, NEW.after_state, NEW.created_at)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.request_id, OLD.action, OLD.target_type, OLD.target_id, OLD.details, OLD.before_state, OLD.after_state, OLD.created_at) THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+DROP INDEX IF EXISTS idx_audit_log_actor;
+ALTER TABLE audit_log
+    DROP COLUMN IF EXISTS after_state,
+    DROP COLUMN IF EXISTS before_state,
+    DROP COLUMN IF EXISTS request_id;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Words checked by the content filter, stored in lowercase
+-- block rejects the submission, mask replaces the word with asterisks, flag holds it back for review
+CREATE TABLE IF NOT EXISTS banned_words (
+    id BIGSERIAL PRIMARY KEY,
+    word TEXT NOT NULL UNIQUE,
+    action TEXT NOT NULL CHECK (action IN ('block', 'mask', 'flag')),
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Flagged content stays pending until a moderator approves it
+-- content_hash is a hash of the normalised text, used to spot the same text being posted over and over
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'pending')),
+    ADD COLUMN IF NOT EXISTS flag_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS content_hash TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'pending')),
+    ADD COLUMN IF NOT EXISTS flag_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS content_hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_posts_user_content_hash ON posts(user_id, content_hash);
+CREATE INDEX IF NOT EXISTS idx_comments_user_content_hash ON comments(user_id, content_hash);
+CREATE INDEX IF NOT EXISTS idx_posts_pending ON posts(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_comments_pending ON comments(created_at) WHERE status = 'pending';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_comments_pending;
+DROP INDEX IF EXISTS idx_posts_pending;
+DROP INDEX IF EXISTS idx_comments_user_content_hash;
+DROP INDEX IF EXISTS idx_posts_user_content_hash;
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS content_hash,
+    DROP COLUMN IF EXISTS flag_reason,
+    DROP COLUMN IF EXISTS status;
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS content_hash,
+    DROP COLUMN IF EXISTS flag_reason,
+    DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS banned_words;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Topics a user follows, their posts make up the user's home feed
+CREATE TABLE IF NOT EXISTS topic_subscriptions (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, topic_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_topic_subscriptions_topic ON topic_subscriptions(topic_id);
+
+-- When a user last opened a topic, posts created after it count as unread
+CREATE TABLE IF NOT EXISTS topic_visits (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    topic_id BIGINT NOT NULL REFERENCES topics(id) ON DELETE CASCADE,
+    last_visited_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, topic_id)
+);
+
+-- Backs both the feed and the unread counts
+CREATE INDEX IF NOT EXISTS idx_posts_topic_created ON posts(topic_id, created_at DESC, id DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_posts_topic_created;
+DROP TABLE IF EXISTS topic_visits;
+DROP TABLE IF EXISTS topic_subscriptions;
+-- +goose StatementEnd
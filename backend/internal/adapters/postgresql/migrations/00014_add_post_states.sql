@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- pinned posts are listed first, locked posts take no new comments
+-- archived posts are read-only for everyone, archived_at records when that happened
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_posts_topic_pinned ON posts(topic_id, pinned DESC, created_at DESC);
+
+-- the auto-archive job looks for the latest comment on each post
+CREATE INDEX IF NOT EXISTS idx_comments_post_created ON comments(post_id, created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_comments_post_created;
+DROP INDEX IF EXISTS idx_posts_topic_pinned;
+ALTER TABLE posts DROP COLUMN IF EXISTS archived_at, DROP COLUMN IF EXISTS locked, DROP COLUMN IF EXISTS pinned;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A post carries at most one poll, created in the same transaction as the post
+CREATE TABLE IF NOT EXISTS polls (
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL UNIQUE REFERENCES posts(id) ON DELETE CASCADE,
+    question TEXT NOT NULL DEFAULT '',
+    multiple_choice BOOLEAN NOT NULL DEFAULT false,
+    hide_results BOOLEAN NOT NULL DEFAULT false, -- counts stay hidden until the poll closes
+    closes_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id BIGSERIAL PRIMARY KEY,
+    poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    label TEXT NOT NULL,
+    UNIQUE (poll_id, position),
+    UNIQUE (poll_id, id) -- lets votes check that the option belongs to the poll
+);
+
+-- One ballot per user and poll, the ballot holds one or more choices
+CREATE TABLE IF NOT EXISTS poll_ballots (
+    poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP,
+    PRIMARY KEY (poll_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    option_id BIGINT NOT NULL,
+    PRIMARY KEY (poll_id, user_id, option_id),
+    FOREIGN KEY (poll_id, user_id) REFERENCES poll_ballots(poll_id, user_id) ON DELETE CASCADE,
+    FOREIGN KEY (poll_id, option_id) REFERENCES poll_options(poll_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_option ON poll_votes(option_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_ballots;
+DROP TABLE IF EXISTS poll_options;
+DROP TABLE IF EXISTS polls;
+-- +goose StatementEnd
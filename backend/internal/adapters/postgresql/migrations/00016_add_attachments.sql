@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Metadata for uploaded files, the bytes themselves live in the blob store under storage_key
+-- Uploads start out unattached and get linked to a post or comment when that is created
+CREATE TABLE IF NOT EXISTS attachments (
+    id BIGSERIAL PRIMARY KEY,
+    storage_key TEXT NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    target_type TEXT CHECK (target_type IN ('post', 'comment')),
+    target_id BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    attached_at TIMESTAMP,
+    CHECK ((target_type IS NULL) = (target_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_target ON attachments(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_unattached ON attachments(created_at) WHERE target_id IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS attachments;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Remembers which row every imported record became, so an import that stopped halfway can simply be run again
+-- source identifies the export the records came from
+CREATE TABLE IF NOT EXISTS import_mappings (
+    source TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    old_id BIGINT NOT NULL,
+    new_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (source, kind, old_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS import_mappings;
+-- +goose StatementEnd
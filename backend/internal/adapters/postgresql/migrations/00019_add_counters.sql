@@ -0,0 +1,120 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Counts of what readers can see, so listings no longer have to count comments and posts themselves
+-- Only published rows that are not deleted are counted, last_activity_at is the latest of the post and its comments
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS comment_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP NOT NULL DEFAULT now();
+
+ALTER TABLE topics
+    ADD COLUMN IF NOT EXISTS post_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_post_at TIMESTAMP;
+
+UPDATE posts p SET comment_count = s.comment_count, last_activity_at = s.last_activity_at
+FROM (
+    SELECT p2.id, COUNT(c.id)::INT AS comment_count, GREATEST(p2.created_at, MAX(c.created_at)) AS last_activity_at
+    FROM posts p2
+    LEFT JOIN comments c ON c.post_id = p2.id AND c.deleted_at IS NULL AND c.status = 'published'
+    GROUP BY p2.id
+) s
+WHERE s.id = p.id;
+
+UPDATE topics t SET post_count = s.post_count, last_post_at = s.last_post_at
+FROM (
+    SELECT t2.id, COUNT(p.id)::INT AS post_count, MAX(p.created_at) AS last_post_at
+    FROM topics t2
+    LEFT JOIN posts p ON p.topic_id = t2.id AND p.deleted_at IS NULL AND p.status = 'published'
+    GROUP BY t2.id
+) s
+WHERE s.id = t.id;
+
+-- The triggers keep the counters in step with every insert, delete, restore, approval and purge in the same transaction
+-- Adding is a plain increment, the latest time is only searched for again when the row removed was the latest one
+CREATE OR REPLACE FUNCTION comments_update_post_counters() RETURNS trigger AS $$
+DECLARE
+    was_counted BOOLEAN := TG_OP <> 'INSERT' AND OLD.deleted_at IS NULL AND OLD.status = 'published';
+    is_counted BOOLEAN := TG_OP <> 'DELETE' AND NEW.deleted_at IS NULL AND NEW.status = 'published';
+BEGIN
+    IF was_counted AND is_counted AND OLD.post_id = NEW.post_id THEN
+        RETURN NULL;
+    END IF;
+
+    IF was_counted THEN
+        UPDATE posts p SET
+            comment_count = p.comment_count - 1,
+            last_activity_at = CASE
+                WHEN p.last_activity_at > OLD.created_at THEN p.last_activity_at
+                ELSE GREATEST(p.created_at, (
+                    SELECT MAX(c.created_at) FROM comments c
+                    WHERE c.post_id = p.id AND c.deleted_at IS NULL AND c.status = 'published'
+                ))
+            END
+        WHERE p.id = OLD.post_id;
+    END IF;
+
+    IF is_counted THEN
+        UPDATE posts SET
+            comment_count = comment_count + 1,
+            last_activity_at = GREATEST(last_activity_at, NEW.created_at)
+        WHERE id = NEW.post_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS comments_update_post_counters ON comments;
+CREATE TRIGGER comments_update_post_counters
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_at, status, post_id ON comments
+    FOR EACH ROW EXECUTE FUNCTION comments_update_post_counters();
+
+CREATE OR REPLACE FUNCTION posts_update_topic_counters() RETURNS trigger AS $$
+DECLARE
+    was_counted BOOLEAN := TG_OP <> 'INSERT' AND OLD.deleted_at IS NULL AND OLD.status = 'published';
+    is_counted BOOLEAN := TG_OP <> 'DELETE' AND NEW.deleted_at IS NULL AND NEW.status = 'published';
+BEGIN
+    IF was_counted AND is_counted AND OLD.topic_id = NEW.topic_id THEN
+        RETURN NULL;
+    END IF;
+
+    IF was_counted THEN
+        UPDATE topics t SET
+            post_count = t.post_count - 1,
+            last_post_at = CASE
+                WHEN t.last_post_at > OLD.created_at THEN t.last_post_at
+                ELSE (
+                    SELECT MAX(p.created_at) FROM posts p
+                    WHERE p.topic_id = t.id AND p.deleted_at IS NULL AND p.status = 'published'
+                )
+            END
+        WHERE t.id = OLD.topic_id;
+    END IF;
+
+    IF is_counted THEN
+        UPDATE topics SET
+            post_count = post_count + 1,
+            last_post_at = GREATEST(last_post_at, NEW.created_at)
+        WHERE id = NEW.topic_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS posts_update_topic_counters ON posts;
+CREATE TRIGGER posts_update_topic_counters
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_at, status, topic_id ON posts
+    FOR EACH ROW EXECUTE FUNCTION posts_update_topic_counters();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS posts_update_topic_counters ON posts;
+DROP FUNCTION IF EXISTS posts_update_topic_counters();
+DROP TRIGGER IF EXISTS comments_update_post_counters ON comments;
+DROP FUNCTION IF EXISTS comments_update_post_counters();
+ALTER TABLE topics DROP COLUMN IF EXISTS last_post_at, DROP COLUMN IF EXISTS post_count;
+ALTER TABLE posts DROP COLUMN IF EXISTS last_activity_at, DROP COLUMN IF EXISTS comment_count;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- updated_at stays NULL until the first edit, like on posts and comments
+-- it is part of what tells a client whether the topic listing changed since it last asked
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE topics DROP COLUMN IF EXISTS updated_at;
+-- +goose StatementEnd
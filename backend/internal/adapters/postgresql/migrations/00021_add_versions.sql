@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- version goes up by one with every edit, an edit made against an older version is refused instead of overwriting the newer one
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE comments DROP COLUMN IF EXISTS version;
+ALTER TABLE posts DROP COLUMN IF EXISTS version;
+ALTER TABLE topics DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/markdown"
+	"github.com/pressly/goose/v3"
+)
+
+// rows rendered per query by the backfill
+const backfillBatchSize = 500
+
+// backfillContentHTML renders the markdown of posts and comments written before 00003 added content_html
+// The HTML comes from Go, so this one cannot be a SQL migration
+// It runs outside a transaction and in batches, rows rendered by an interrupted run are skipped the next time
+func backfillContentHTML() *goose.Migration {
+	return goose.NewGoMigration(22,
+		&goose.GoFunc{RunDB: func(ctx context.Context, db *sql.DB) error {
+			for _, table := range []string{"posts", "comments"} {
+				if err := backfillTable(ctx, db, table); err != nil {
+					return fmt.Errorf("backfill %s: %w", table, err)
+				}
+			}
+			return nil
+		}},
+		// the column stays in place when rolling back, there is nothing to undo
+		&goose.GoFunc{RunDB: func(ctx context.Context, db *sql.DB) error { return nil }},
+	)
+}
+
+func backfillTable(ctx context.Context, db *sql.DB, table string) error {
+	var afterID int64
+	for {
+		rows, err := db.QueryContext(ctx,
+			"SELECT id, content FROM "+table+" WHERE content_html = '' AND content <> '' AND id > $1 ORDER BY id LIMIT $2",
+			afterID, backfillBatchSize)
+		if err != nil {
+			return err
+		}
+
+		type pending struct {
+			id      int64
+			content string
+		}
+		var batch []pending
+		for rows.Next() {
+			var p pending
+			if err := rows.Scan(&p.id, &p.content); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, p := range batch {
+			html, err := markdown.Render(p.content)
+			if err != nil {
+				return err
+			}
+			if _, err := db.ExecContext(ctx, "UPDATE "+table+" SET content_html = $2 WHERE id = $1", p.id, html); err != nil {
+				return err
+			}
+			afterID = p.id
+		}
+	}
+}
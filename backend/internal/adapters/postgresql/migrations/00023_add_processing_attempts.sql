@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- a worker claims an image before processing it, and gives up on it after a few attempts instead of retrying forever
+ALTER TABLE attachments
+    ADD COLUMN IF NOT EXISTS processing_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS processing_claimed_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE attachments
+    DROP COLUMN IF EXISTS processing_claimed_at,
+    DROP COLUMN IF EXISTS processing_attempts;
+-- +goose StatementEnd
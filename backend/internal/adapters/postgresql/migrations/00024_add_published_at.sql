@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- published_at is set the first time a post or comment is shown, a held edit of published content is told apart from new content by it
+-- rows already published count from their creation, held rows are taken as never published
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+UPDATE posts SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+UPDATE comments SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+-- status is whether the text a revision holds was published, rejecting a held edit goes back to the latest one that was
+ALTER TABLE revisions ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE revisions DROP COLUMN IF EXISTS status;
+ALTER TABLE comments DROP COLUMN IF EXISTS published_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS published_at;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Files holds the goose migrations so the server binary can apply them without the source tree
+//
+//go:embed *.sql
+var Files embed.FS
+
+// NewProvider returns a goose provider for the embedded migrations, along with the ones written in Go
+// Every run holds a Postgres advisory lock, so instances starting together apply each migration once
+func NewProvider(db *sql.DB) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	return goose.NewProvider(goose.DialectPostgres, db, Files,
+		goose.WithSessionLocker(locker),
+		goose.WithGoMigrations(backfillContentHTML()),
+	)
+}
@@ -8,25 +8,224 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Comment struct {
+type AttachmentVariant struct {
+	AttachmentID int64  `json:"attachment_id"`
+	Name         string `json:"name"`
+	StorageKey   string `json:"storage_key"`
+	ContentType  string `json:"content_type"`
+	SizeBytes    int64  `json:"size_bytes"`
+	Width        int32  `json:"width"`
+	Height       int32  `json:"height"`
+}
+
+type Attachment struct {
+	ID                  int64            `json:"id"`
+	StorageKey          string           `json:"storage_key"`
+	UserID              int64            `json:"user_id"`
+	Filename            string           `json:"filename"`
+	ContentType         string           `json:"content_type"`
+	SizeBytes           int64            `json:"size_bytes"`
+	TargetType          pgtype.Text      `json:"target_type"`
+	TargetID            pgtype.Int8      `json:"target_id"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	AttachedAt          pgtype.Timestamp `json:"attached_at"`
+	Status              string           `json:"status"`
+	Width               pgtype.Int4      `json:"width"`
+	Height              pgtype.Int4      `json:"height"`
+	ProcessingError     string           `json:"processing_error"`
+	ProcessingAttempts  int32            `json:"processing_attempts"`
+	ProcessingClaimedAt pgtype.Timestamp `json:"processing_claimed_at"`
+}
+
+type AuditLog struct {
+	ID          int64            `json:"id"`
+	ActorID     pgtype.Int8      `json:"actor_id"`
+	Action      string           `json:"action"`
+	TargetType  string           `json:"target_type"`
+	TargetID    int64            `json:"target_id"`
+	Details     []byte           `json:"details"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	RequestID   string           `json:"request_id"`
+	BeforeState []byte           `json:"before_state"`
+	AfterState  []byte           `json:"after_state"`
+}
+
+type BannedWord struct {
+	ID        int64            `json:"id"`
+	Word      string           `json:"word"`
+	Action    string           `json:"action"`
+	CreatedBy pgtype.Int8      `json:"created_by"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type BookmarkCollection struct {
 	ID        int64            `json:"id"`
-	Content   string           `json:"content"`
 	UserID    int64            `json:"user_id"`
-	Username  string           `json:"username"`
-	PostID    int64            `json:"post_id"`
+	Name      string           `json:"name"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
 
+type Bookmark struct {
+	ID           int64            `json:"id"`
+	UserID       int64            `json:"user_id"`
+	TargetType   string           `json:"target_type"`
+	TargetID     int64            `json:"target_id"`
+	CollectionID pgtype.Int8      `json:"collection_id"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type Comment struct {
+	ID          int64            `json:"id"`
+	Content     string           `json:"content"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	PostID      int64            `json:"post_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	ContentHtml string           `json:"content_html"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	EditCount   int32            `json:"edit_count"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	Status      string           `json:"status"`
+	FlagReason  string           `json:"flag_reason"`
+	ContentHash string           `json:"content_hash"`
+	Version     int32            `json:"version"`
+	PublishedAt pgtype.Timestamp `json:"published_at"`
+}
+
+type ImportMapping struct {
+	Source    string           `json:"source"`
+	Kind      string           `json:"kind"`
+	OldID     int64            `json:"old_id"`
+	NewID     int64            `json:"new_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Notification struct {
+	ID         int64            `json:"id"`
+	UserID     int64            `json:"user_id"`
+	Kind       string           `json:"kind"`
+	ActorID    pgtype.Int8      `json:"actor_id"`
+	TargetType string           `json:"target_type"`
+	TargetID   int64            `json:"target_id"`
+	ReadAt     pgtype.Timestamp `json:"read_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type PollBallot struct {
+	PollID    int64            `json:"poll_id"`
+	UserID    int64            `json:"user_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type PollOption struct {
+	ID       int64  `json:"id"`
+	PollID   int64  `json:"poll_id"`
+	Position int32  `json:"position"`
+	Label    string `json:"label"`
+}
+
+type PollVote struct {
+	PollID   int64 `json:"poll_id"`
+	UserID   int64 `json:"user_id"`
+	OptionID int64 `json:"option_id"`
+}
+
+type Poll struct {
+	ID             int64            `json:"id"`
+	PostID         int64            `json:"post_id"`
+	Question       string           `json:"question"`
+	MultipleChoice bool             `json:"multiple_choice"`
+	HideResults    bool             `json:"hide_results"`
+	ClosesAt       pgtype.Timestamp `json:"closes_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type PostTag struct {
+	PostID int64 `json:"post_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
 type Post struct {
+	ID             int64            `json:"id"`
+	Title          string           `json:"title"`
+	Content        string           `json:"content"`
+	UserID         int64            `json:"user_id"`
+	Username       string           `json:"username"`
+	TopicID        int64            `json:"topic_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	ContentHtml    string           `json:"content_html"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	EditCount      int32            `json:"edit_count"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy      pgtype.Int8      `json:"deleted_by"`
+	Status         string           `json:"status"`
+	FlagReason     string           `json:"flag_reason"`
+	ContentHash    string           `json:"content_hash"`
+	Pinned         bool             `json:"pinned"`
+	Locked         bool             `json:"locked"`
+	ArchivedAt     pgtype.Timestamp `json:"archived_at"`
+	CommentCount   int32            `json:"comment_count"`
+	LastActivityAt pgtype.Timestamp `json:"last_activity_at"`
+	Version        int32            `json:"version"`
+	PublishedAt    pgtype.Timestamp `json:"published_at"`
+}
+
+type Report struct {
+	ID         int64            `json:"id"`
+	TargetType string           `json:"target_type"`
+	TargetID   int64            `json:"target_id"`
+	Reason     string           `json:"reason"`
+	Note       string           `json:"note"`
+	ReporterID int64            `json:"reporter_id"`
+	Status     string           `json:"status"`
+	Resolution pgtype.Text      `json:"resolution"`
+	ResolvedBy pgtype.Int8      `json:"resolved_by"`
+	ResolvedAt pgtype.Timestamp `json:"resolved_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type Revision struct {
+	ID         int64            `json:"id"`
+	TargetType string           `json:"target_type"`
+	TargetID   int64            `json:"target_id"`
+	Revision   int32            `json:"revision"`
+	Title      pgtype.Text      `json:"title"`
+	Content    string           `json:"content"`
+	EditedBy   pgtype.Int8      `json:"edited_by"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	Status     string           `json:"status"`
+}
+
+type Tag struct {
 	ID        int64            `json:"id"`
-	Title     string           `json:"title"`
-	Content   string           `json:"content"`
+	Name      string           `json:"name"`
+	TopicID   pgtype.Int8      `json:"topic_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TopicMute struct {
+	TopicID   int64            `json:"topic_id"`
+	UserID    int64            `json:"user_id"`
+	Reason    string           `json:"reason"`
+	MutedBy   pgtype.Int8      `json:"muted_by"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TopicSubscription struct {
 	UserID    int64            `json:"user_id"`
-	Username  string           `json:"username"`
 	TopicID   int64            `json:"topic_id"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
 
+type TopicVisit struct {
+	UserID        int64            `json:"user_id"`
+	TopicID       int64            `json:"topic_id"`
+	LastVisitedAt pgtype.Timestamp `json:"last_visited_at"`
+}
+
 type Topic struct {
 	ID          int64            `json:"id"`
 	Name        string           `json:"name"`
@@ -34,6 +233,40 @@ type Topic struct {
 	UserID      int64            `json:"user_id"`
 	Username    string           `json:"username"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	PostCount   int32            `json:"post_count"`
+	LastPostAt  pgtype.Timestamp `json:"last_post_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	Version     int32            `json:"version"`
+}
+
+type UserFollow struct {
+	FollowerID int64            `json:"follower_id"`
+	FolloweeID int64            `json:"followee_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type UserSanction struct {
+	ID        int64            `json:"id"`
+	UserID    int64            `json:"user_id"`
+	Kind      string           `json:"kind"`
+	Reason    string           `json:"reason"`
+	IssuedBy  pgtype.Int8      `json:"issued_by"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+	RevokedBy pgtype.Int8      `json:"revoked_by"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type UserWarning struct {
+	ID         int64            `json:"id"`
+	UserID     int64            `json:"user_id"`
+	IssuedBy   pgtype.Int8      `json:"issued_by"`
+	Reason     string           `json:"reason"`
+	TargetType string           `json:"target_type"`
+	TargetID   int64            `json:"target_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
 }
 
 type User struct {
@@ -41,4 +274,5 @@ type User struct {
 	Username  string           `json:"username"`
 	Password  string           `json:"password"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
+	Role      string           `json:"role"`
 }
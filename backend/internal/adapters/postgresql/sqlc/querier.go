@@ -6,23 +6,203 @@ package repo
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddPostTag(ctx context.Context, arg AddPostTagParams) error
+	ApproveComment(ctx context.Context, id int64) (Comment, error)
+	ApprovePost(ctx context.Context, id int64) (Post, error)
+	// a post is active when it or any of its live comments was written or edited after the cutoff
+	// pinned posts are left alone since they are usually long running announcements
+	ArchiveInactivePosts(ctx context.Context, cutoff pgtype.Timestamp) ([]int64, error)
+	// only the uploader's own unattached uploads can be linked
+	AttachUploads(ctx context.Context, arg AttachUploadsParams) ([]Attachment, error)
+	// only one worker gets the upload, a claim made before the cutoff is taken to have died with its worker
+	ClaimAttachmentProcessing(ctx context.Context, arg ClaimAttachmentProcessingParams) (Attachment, error)
+	CountPollBallots(ctx context.Context, pollID int64) (int64, error)
+	CountRecentDuplicates(ctx context.Context, arg CountRecentDuplicatesParams) (int64, error)
+	CountUnreadNotifications(ctx context.Context, userID int64) (int64, error)
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error)
+	CreateAuditLogEntry(ctx context.Context, arg CreateAuditLogEntryParams) error
+	CreateBookmarkCollection(ctx context.Context, arg CreateBookmarkCollectionParams) (BookmarkCollection, error)
 	CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error)
+	CreateImportMapping(ctx context.Context, arg CreateImportMappingParams) error
+	CreatePoll(ctx context.Context, arg CreatePollParams) (Poll, error)
+	CreatePollBallot(ctx context.Context, arg CreatePollBallotParams) (PollBallot, error)
+	CreatePollOption(ctx context.Context, arg CreatePollOptionParams) (PollOption, error)
+	CreatePollVote(ctx context.Context, arg CreatePollVoteParams) error
 	CreatePost(ctx context.Context, arg CreatePostParams) (Post, error)
+	CreateReport(ctx context.Context, arg CreateReportParams) (Report, error)
+	CreateRevision(ctx context.Context, arg CreateRevisionParams) error
+	CreateSanction(ctx context.Context, arg CreateSanctionParams) (UserSanction, error)
 	CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic, error)
+	CreateTopicSubscription(ctx context.Context, arg CreateTopicSubscriptionParams) (TopicSubscription, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteComment(ctx context.Context, id int64) (Comment, error)
-	DeletePost(ctx context.Context, id int64) (Post, error)
-	DeleteTopic(ctx context.Context, id int64) (Topic, error)
+	CreateUserFollow(ctx context.Context, arg CreateUserFollowParams) (UserFollow, error)
+	CreateUserWarning(ctx context.Context, arg CreateUserWarningParams) (UserWarning, error)
+	DeleteBallotChoices(ctx context.Context, arg DeleteBallotChoicesParams) error
+	DeleteBannedWord(ctx context.Context, id int64) (BannedWord, error)
+	DeleteBookmark(ctx context.Context, arg DeleteBookmarkParams) (Bookmark, error)
+	DeleteBookmarkCollection(ctx context.Context, arg DeleteBookmarkCollectionParams) (BookmarkCollection, error)
+	DeleteComment(ctx context.Context, arg DeleteCommentParams) (Comment, error)
+	// checks again that the upload is still orphaned in case it was attached since it was listed
+	DeleteOrphanedAttachment(ctx context.Context, id int64) (int64, error)
+	DeletePost(ctx context.Context, arg DeletePostParams) (Post, error)
+	DeletePostTags(ctx context.Context, postID int64) error
+	DeleteTopic(ctx context.Context, arg DeleteTopicParams) (Topic, error)
+	DeleteTopicMute(ctx context.Context, arg DeleteTopicMuteParams) (TopicMute, error)
+	DeleteTopicSubscription(ctx context.Context, arg DeleteTopicSubscriptionParams) (TopicSubscription, error)
+	DeleteUserFollow(ctx context.Context, arg DeleteUserFollowParams) (UserFollow, error)
+	ExportBookmarkCollections(ctx context.Context, arg ExportBookmarkCollectionsParams) ([]BookmarkCollection, error)
+	ExportBookmarks(ctx context.Context, arg ExportBookmarksParams) ([]Bookmark, error)
+	ExportComments(ctx context.Context, arg ExportCommentsParams) ([]Comment, error)
+	ExportPollBallots(ctx context.Context, pollID int64) ([]ExportPollBallotsRow, error)
+	ExportPolls(ctx context.Context, arg ExportPollsParams) ([]Poll, error)
+	ExportPosts(ctx context.Context, arg ExportPostsParams) ([]ExportPostsRow, error)
+	ExportRevisions(ctx context.Context, arg ExportRevisionsParams) ([]Revision, error)
+	ExportTags(ctx context.Context, arg ExportTagsParams) ([]Tag, error)
+	ExportTopicSubscriptions(ctx context.Context, arg ExportTopicSubscriptionsParams) ([]TopicSubscription, error)
+	ExportTopics(ctx context.Context, arg ExportTopicsParams) ([]Topic, error)
+	ExportUserFollows(ctx context.Context, arg ExportUserFollowsParams) ([]UserFollow, error)
+	// password hashes are only selected when asked for
+	ExportUsers(ctx context.Context, arg ExportUsersParams) ([]ExportUsersRow, error)
+	FailAttachmentProcessing(ctx context.Context, arg FailAttachmentProcessingParams) error
+	FetchUserByID(ctx context.Context, id int64) (User, error)
 	FetchUserByUsername(ctx context.Context, username string) (User, error)
-	ListComments(ctx context.Context, postID int64) ([]Comment, error)
-	ListPosts(ctx context.Context, topicID int64) ([]Post, error)
+	FinishAttachmentProcessing(ctx context.Context, arg FinishAttachmentProcessingParams) (Attachment, error)
+	GetActiveSanction(ctx context.Context, userID int64) (UserSanction, error)
+	GetAttachment(ctx context.Context, id int64) (Attachment, error)
+	GetAttachmentVariant(ctx context.Context, arg GetAttachmentVariantParams) (AttachmentVariant, error)
+	GetBookmarkCollection(ctx context.Context, arg GetBookmarkCollectionParams) (BookmarkCollection, error)
+	GetComment(ctx context.Context, id int64) (Comment, error)
+	GetCommentForUpdate(ctx context.Context, id int64) (Comment, error)
+	GetImportMapping(ctx context.Context, arg GetImportMappingParams) (int64, error)
+	GetLatestPublishedRevision(ctx context.Context, arg GetLatestPublishedRevisionParams) (Revision, error)
+	GetPoll(ctx context.Context, id int64) (Poll, error)
+	GetPollByPostID(ctx context.Context, postID int64) (Poll, error)
+	GetPost(ctx context.Context, id int64) (Post, error)
+	GetPostCommentsVersion(ctx context.Context, arg GetPostCommentsVersionParams) (GetPostCommentsVersionRow, error)
+	// everything the post page shows apart from the comments, hidden posts and posts in deleted topics are left out
+	GetPostDetail(ctx context.Context, arg GetPostDetailParams) (GetPostDetailRow, error)
+	GetPostForUpdate(ctx context.Context, id int64) (Post, error)
+	// what the post page shows changes whenever one of these does, a hidden post has no row just like in GetPostDetail
+	GetPostVersion(ctx context.Context, arg GetPostVersionParams) (GetPostVersionRow, error)
+	GetRevision(ctx context.Context, arg GetRevisionParams) (Revision, error)
+	GetSanctionForUpdate(ctx context.Context, id int64) (UserSanction, error)
+	GetTopic(ctx context.Context, id int64) (Topic, error)
+	GetTopicForUpdate(ctx context.Context, id int64) (Topic, error)
+	GetTopicPostsVersion(ctx context.Context, arg GetTopicPostsVersionParams) (GetTopicPostsVersionRow, error)
+	// the listing changes whenever one of these does, timestamps of deleted topics count so deleting one moves them
+	GetTopicsVersion(ctx context.Context, userID int64) (GetTopicsVersionRow, error)
+	GetUserProfile(ctx context.Context, arg GetUserProfileParams) (GetUserProfileRow, error)
+	ImportBookmark(ctx context.Context, arg ImportBookmarkParams) (int64, error)
+	// an existing collection with the same name is reused as is
+	ImportBookmarkCollection(ctx context.Context, arg ImportBookmarkCollectionParams) (BookmarkCollection, error)
+	ImportComment(ctx context.Context, arg ImportCommentParams) (Comment, error)
+	// returns no rows when the post already has a poll, which means it was imported before
+	ImportPoll(ctx context.Context, arg ImportPollParams) (Poll, error)
+	ImportPollBallot(ctx context.Context, arg ImportPollBallotParams) error
+	// activity starts at the original created_at, the comments imported after it move it forward
+	// published rows count as published since their creation
+	ImportPost(ctx context.Context, arg ImportPostParams) (Post, error)
+	ImportRevision(ctx context.Context, arg ImportRevisionParams) (int64, error)
+	// an existing tag keeps its own restriction
+	ImportTag(ctx context.Context, arg ImportTagParams) (Tag, error)
+	// an existing topic with the same name is reused as is
+	ImportTopic(ctx context.Context, arg ImportTopicParams) (Topic, error)
+	ImportTopicSubscription(ctx context.Context, arg ImportTopicSubscriptionParams) (int64, error)
+	// the importer picks a free username first, so a clash means the name was taken in the meantime and fails the batch
+	ImportUser(ctx context.Context, arg ImportUserParams) (User, error)
+	ImportUserFollow(ctx context.Context, arg ImportUserFollowParams) (int64, error)
+	IsMutedInTopic(ctx context.Context, arg IsMutedInTopicParams) (bool, error)
+	ListAttachmentVariants(ctx context.Context, attachmentID int64) ([]AttachmentVariant, error)
+	ListAttachments(ctx context.Context, arg ListAttachmentsParams) ([]Attachment, error)
+	ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error)
+	ListBallotChoices(ctx context.Context, arg ListBallotChoicesParams) ([]int64, error)
+	ListBannedWords(ctx context.Context) ([]BannedWord, error)
+	ListBookmarkCollections(ctx context.Context, userID int64) ([]BookmarkCollection, error)
+	ListBookmarks(ctx context.Context, arg ListBookmarksParams) ([]ListBookmarksRow, error)
+	// deleting a post or its topic hides the comments along with it
+	ListComments(ctx context.Context, arg ListCommentsParams) ([]ListCommentsRow, error)
+	ListCommentsForReindex(ctx context.Context, arg ListCommentsForReindexParams) ([]ListCommentsForReindexRow, error)
+	// oldest first, the way a thread is read
+	ListCommentsPage(ctx context.Context, arg ListCommentsPageParams) ([]ListCommentsPageRow, error)
+	ListFeed(ctx context.Context, arg ListFeedParams) ([]Post, error)
+	ListFollowedComments(ctx context.Context, arg ListFollowedCommentsParams) ([]ListFollowedCommentsRow, error)
+	ListFollowedPosts(ctx context.Context, arg ListFollowedPostsParams) ([]Post, error)
+	ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error)
+	ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error)
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	ListOpenReportsForTarget(ctx context.Context, arg ListOpenReportsForTargetParams) ([]Report, error)
+	// uploads never attached to anything, or whose post or comment has been purged
+	ListOrphanedAttachments(ctx context.Context, arg ListOrphanedAttachmentsParams) ([]Attachment, error)
+	ListPendingComments(ctx context.Context, arg ListPendingCommentsParams) ([]Comment, error)
+	ListPendingPosts(ctx context.Context, arg ListPendingPostsParams) ([]Post, error)
+	ListPollOptions(ctx context.Context, pollID int64) ([]PollOption, error)
+	ListPollResults(ctx context.Context, pollID int64) ([]ListPollResultsRow, error)
+	ListPostTags(ctx context.Context, postID int64) ([]string, error)
+	ListPosts(ctx context.Context, arg ListPostsParams) ([]ListPostsRow, error)
+	ListPostsByTag(ctx context.Context, arg ListPostsByTagParams) ([]ListPostsByTagRow, error)
+	ListPostsForReindex(ctx context.Context, arg ListPostsForReindexParams) ([]ListPostsForReindexRow, error)
+	ListReportQueue(ctx context.Context, arg ListReportQueueParams) ([]ListReportQueueRow, error)
+	ListRevisions(ctx context.Context, arg ListRevisionsParams) ([]Revision, error)
+	ListSanctionsForUser(ctx context.Context, userID int64) ([]UserSanction, error)
+	// which comments of a post the user saved, laid over a comment listing shared by everyone
+	ListSavedCommentIDs(ctx context.Context, arg ListSavedCommentIDsParams) ([]int64, error)
+	// which posts of a topic the user saved, laid over a post listing shared by everyone
+	ListSavedPostIDs(ctx context.Context, arg ListSavedPostIDsParams) ([]int64, error)
+	// uploads still waiting for processing, e.g. because the server restarted before the queue was drained
+	ListStalledAttachments(ctx context.Context, arg ListStalledAttachmentsParams) ([]int64, error)
+	ListTopicMutes(ctx context.Context, topicID int64) ([]TopicMute, error)
+	// the part of ListTopicsForUser that differs between users, laid over a listing shared by everyone
+	ListTopicStates(ctx context.Context, userID int64) ([]ListTopicStatesRow, error)
+	ListTopicSubscriptions(ctx context.Context, userID int64) ([]Topic, error)
 	ListTopics(ctx context.Context) ([]Topic, error)
+	ListTopicsForUser(ctx context.Context, userID int64) ([]ListTopicsForUserRow, error)
+	MarkNotificationsRead(ctx context.Context, arg MarkNotificationsReadParams) (int64, error)
+	NotifyFollowers(ctx context.Context, arg NotifyFollowersParams) (int64, error)
+	PurgeDeletedComments(ctx context.Context, cutoff pgtype.Timestamp) (int64, error)
+	PurgeDeletedPosts(ctx context.Context, cutoff pgtype.Timestamp) (int64, error)
+	PurgeDeletedTopics(ctx context.Context, cutoff pgtype.Timestamp) (int64, error)
+	PurgeOrphanedRevisions(ctx context.Context) (int64, error)
+	RecordTopicVisit(ctx context.Context, arg RecordTopicVisitParams) error
+	// lets the next resume pick the upload up again after a failed attempt
+	ReleaseAttachmentProcessing(ctx context.Context, id int64) error
+	RenameBookmarkCollection(ctx context.Context, arg RenameBookmarkCollectionParams) (BookmarkCollection, error)
+	// recounts every post and only writes the ones that drifted, the count is how many were wrong
+	RepairPostCounters(ctx context.Context) (int64, error)
+	RepairTopicCounters(ctx context.Context) (int64, error)
+	ResolveReports(ctx context.Context, arg ResolveReportsParams) ([]Report, error)
+	RestoreComment(ctx context.Context, id int64) (Comment, error)
+	RestorePost(ctx context.Context, id int64) (Post, error)
+	RestoreTopic(ctx context.Context, id int64) (Topic, error)
+	RestrictTag(ctx context.Context, arg RestrictTagParams) (Tag, error)
+	RevertComment(ctx context.Context, arg RevertCommentParams) (Comment, error)
+	// puts back the text of a held post's latest published revision, counting as an edit so the rejected text is kept as a revision
+	RevertPost(ctx context.Context, arg RevertPostParams) (Post, error)
+	RevokeSanction(ctx context.Context, arg RevokeSanctionParams) (UserSanction, error)
+	// tag names never contain LIKE wildcards so the prefix can be matched as is
+	SearchTags(ctx context.Context, arg SearchTagsParams) ([]SearchTagsRow, error)
+	SetCommentIndex(ctx context.Context, arg SetCommentIndexParams) error
+	// leaves updated_at alone, rebuilding derived columns is not an edit
+	SetPostIndex(ctx context.Context, arg SetPostIndexParams) error
+	// archiving keeps the original archived_at if the post was already archived
+	SetPostState(ctx context.Context, arg SetPostStateParams) (Post, error)
+	// locks the ballot so two changes from the same user can't interleave
+	TouchPollBallot(ctx context.Context, arg TouchPollBallotParams) (PollBallot, error)
 	UpdateComment(ctx context.Context, arg UpdateCommentParams) (Comment, error)
 	UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, error)
 	UpdateTopic(ctx context.Context, arg UpdateTopicParams) (Topic, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertAttachmentVariant(ctx context.Context, arg UpsertAttachmentVariantParams) (AttachmentVariant, error)
+	UpsertBannedWord(ctx context.Context, arg UpsertBannedWordParams) (BannedWord, error)
+	UpsertBookmark(ctx context.Context, arg UpsertBookmarkParams) (Bookmark, error)
+	// the no-op update makes RETURNING hand back tags that already exist
+	UpsertTag(ctx context.Context, name string) (Tag, error)
+	UpsertTopicMute(ctx context.Context, arg UpsertTopicMuteParams) (TopicMute, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -1,41 +1,906 @@
 -- name: ListTopics :many
-SELECT * FROM topics;
+SELECT * FROM topics WHERE deleted_at IS NULL;
 
 -- name: ListPosts :many
-SELECT * FROM posts WHERE topic_id = $1;
+SELECT
+    p.*,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'post' AND b.target_id = p.id) AS saved,
+    ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name)::text[] AS tags,
+    EXISTS(SELECT 1 FROM polls pl WHERE pl.post_id = p.id) AS has_poll
+FROM posts p
+JOIN topics tp ON tp.id = p.topic_id AND tp.deleted_at IS NULL
+WHERE p.topic_id = sqlc.arg(topic_id) AND p.deleted_at IS NULL AND p.status = 'published'
+ORDER BY p.pinned DESC, p.created_at DESC, p.id DESC;
 
 -- name: ListComments :many
-SELECT * FROM comments WHERE post_id = $1;
+-- deleting a post or its topic hides the comments along with it
+SELECT
+    c.*,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'comment' AND b.target_id = c.id) AS saved
+FROM comments c
+JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE c.post_id = sqlc.arg(post_id) AND c.deleted_at IS NULL AND c.status = 'published';
+
+-- name: ListSavedPostIDs :many
+-- which posts of a topic the user saved, laid over a post listing shared by everyone
+SELECT b.target_id FROM bookmarks b
+JOIN posts p ON p.id = b.target_id
+WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'post' AND p.topic_id = sqlc.arg(topic_id);
+
+-- name: ListSavedCommentIDs :many
+-- which comments of a post the user saved, laid over a comment listing shared by everyone
+SELECT b.target_id FROM bookmarks b
+JOIN comments c ON c.id = b.target_id
+WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'comment' AND c.post_id = sqlc.arg(post_id);
+
+-- name: GetPostDetail :one
+-- everything the post page shows apart from the comments, hidden posts and posts in deleted topics are left out
+SELECT
+    p.*,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'post' AND b.target_id = p.id) AS saved,
+    ARRAY(SELECT tg.name FROM post_tags pt JOIN tags tg ON tg.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY tg.name)::text[] AS tags,
+    EXISTS(SELECT 1 FROM polls pl WHERE pl.post_id = p.id) AS has_poll,
+    t.name AS topic_name,
+    t.description AS topic_description,
+    t.post_count AS topic_post_count,
+    u.role AS author_role,
+    u.created_at AS author_created_at,
+    (SELECT COUNT(*) FROM posts ap WHERE ap.user_id = u.id AND ap.deleted_at IS NULL AND ap.status = 'published')::bigint AS author_post_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.followee_id = u.id)::bigint AS author_follower_count,
+    EXISTS(SELECT 1 FROM user_follows f WHERE f.follower_id = sqlc.arg(user_id) AND f.followee_id = u.id) AS author_followed_by_viewer
+FROM posts p
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+JOIN users u ON u.id = p.user_id
+WHERE p.id = sqlc.arg(id) AND p.deleted_at IS NULL AND p.status = 'published';
+
+-- name: ListCommentsPage :many
+-- oldest first, the way a thread is read
+SELECT
+    c.*,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'comment' AND b.target_id = c.id) AS saved
+FROM comments c
+JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE c.post_id = sqlc.arg(post_id) AND c.deleted_at IS NULL AND c.status = 'published'
+ORDER BY c.created_at, c.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetTopicsVersion :one
+-- the listing changes whenever one of these does, timestamps of deleted topics count so deleting one moves them
+SELECT
+    COUNT(*) FILTER (WHERE t.deleted_at IS NULL)::bigint AS topic_count,
+    COALESCE(SUM(t.post_count) FILTER (WHERE t.deleted_at IS NULL), 0)::bigint AS post_count,
+    (SELECT COUNT(*) FROM topic_subscriptions s WHERE s.user_id = sqlc.arg(user_id))::bigint AS subscription_count,
+    GREATEST(
+        MAX(t.created_at), MAX(t.updated_at), MAX(t.deleted_at), MAX(t.last_post_at),
+        (SELECT MAX(s.created_at) FROM topic_subscriptions s WHERE s.user_id = sqlc.arg(user_id)),
+        (SELECT MAX(v.last_visited_at) FROM topic_visits v WHERE v.user_id = sqlc.arg(user_id))
+    )::timestamp AS last_modified
+FROM topics t;
+
+-- name: GetTopicPostsVersion :one
+SELECT
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published')::bigint AS post_count,
+    COALESCE(SUM(p.comment_count) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published'), 0)::bigint AS comment_count,
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published' AND p.pinned)::bigint AS pinned_count,
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published' AND p.locked)::bigint AS locked_count,
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published' AND p.archived_at IS NOT NULL)::bigint AS archived_count,
+    (
+        SELECT COUNT(*) FROM bookmarks b JOIN posts bp ON bp.id = b.target_id
+        WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'post' AND bp.topic_id = sqlc.arg(topic_id)
+    )::bigint AS saved_count,
+    GREATEST(
+        MAX(p.created_at), MAX(p.updated_at), MAX(p.deleted_at), MAX(p.last_activity_at), MAX(p.archived_at),
+        (
+            SELECT MAX(b.created_at) FROM bookmarks b JOIN posts bp ON bp.id = b.target_id
+            WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'post' AND bp.topic_id = sqlc.arg(topic_id)
+        )
+    )::timestamp AS last_modified
+FROM posts p
+WHERE p.topic_id = sqlc.arg(topic_id);
+
+-- name: GetPostCommentsVersion :one
+SELECT
+    COUNT(*) FILTER (WHERE c.deleted_at IS NULL AND c.status = 'published')::bigint AS comment_count,
+    (
+        SELECT COUNT(*) FROM bookmarks b JOIN comments bc ON bc.id = b.target_id
+        WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'comment' AND bc.post_id = sqlc.arg(post_id)
+    )::bigint AS saved_count,
+    GREATEST(
+        MAX(c.created_at), MAX(c.updated_at), MAX(c.deleted_at),
+        (
+            SELECT MAX(b.created_at) FROM bookmarks b JOIN comments bc ON bc.id = b.target_id
+            WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'comment' AND bc.post_id = sqlc.arg(post_id)
+        )
+    )::timestamp AS last_modified
+FROM comments c
+WHERE c.post_id = sqlc.arg(post_id);
+
+-- name: GetPostVersion :one
+-- what the post page shows changes whenever one of these does, a hidden post has no row just like in GetPostDetail
+SELECT
+    p.version,
+    p.comment_count::bigint AS comment_count,
+    (p.pinned::int + 2 * p.locked::int + 4 * (p.archived_at IS NOT NULL)::int)::bigint AS state,
+    t.post_count::bigint AS topic_post_count,
+    (CASE u.role WHEN 'admin' THEN 2 WHEN 'moderator' THEN 1 ELSE 0 END)::bigint AS author_role,
+    (SELECT COUNT(*) FROM posts ap WHERE ap.user_id = u.id AND ap.deleted_at IS NULL AND ap.status = 'published')::bigint AS author_post_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.followee_id = u.id)::bigint AS author_follower_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.follower_id = sqlc.arg(user_id) AND f.followee_id = u.id)::bigint AS author_followed_by_viewer,
+    (
+        SELECT COUNT(*) FROM bookmarks b
+        WHERE b.user_id = sqlc.arg(user_id)
+          AND ((b.target_type = 'post' AND b.target_id = p.id)
+            OR (b.target_type = 'comment' AND b.target_id IN (SELECT c.id FROM comments c WHERE c.post_id = p.id)))
+    )::bigint AS saved_count,
+    GREATEST(
+        p.created_at, p.updated_at, p.last_activity_at, p.archived_at, t.updated_at,
+        (SELECT MAX(GREATEST(c.created_at, c.updated_at, c.deleted_at)) FROM comments c WHERE c.post_id = p.id),
+        (
+            SELECT MAX(b.created_at) FROM bookmarks b
+            WHERE b.user_id = sqlc.arg(user_id)
+              AND ((b.target_type = 'post' AND b.target_id = p.id)
+                OR (b.target_type = 'comment' AND b.target_id IN (SELECT c.id FROM comments c WHERE c.post_id = p.id)))
+        )
+    )::timestamp AS last_modified
+FROM posts p
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+JOIN users u ON u.id = p.user_id
+WHERE p.id = sqlc.arg(id) AND p.deleted_at IS NULL AND p.status = 'published';
 
 -- name: FetchUserByUsername :one
 SELECT * FROM users WHERE username = $1;
 
+-- name: FetchUserByID :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: GetPost :one
+SELECT * FROM posts WHERE id = $1;
+
+-- name: GetPostForUpdate :one
+SELECT * FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;
+
+-- name: GetComment :one
+SELECT * FROM comments WHERE id = $1;
+
+-- name: GetCommentForUpdate :one
+SELECT * FROM comments WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;
+
 -- name: CreateTopic :one
 INSERT INTO topics (name, description, user_id, username) VALUES ($1, $2, $3, $4) RETURNING *;
 
 -- name: CreatePost :one
-INSERT INTO posts (title, content, topic_id, user_id, username) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+INSERT INTO posts (title, content, content_html, topic_id, user_id, username, status, flag_reason, content_hash, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $7 = 'published' THEN now() END) RETURNING *;
 
 -- name: CreateComment :one
-INSERT INTO comments (content, post_id, user_id, username) VALUES ($1, $2, $3, $4) RETURNING *;
+INSERT INTO comments (content, content_html, post_id, user_id, username, status, flag_reason, content_hash, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $6 = 'published' THEN now() END) RETURNING *;
 
 -- name: CreateUser :one
 INSERT INTO users (username, password) VALUES ($1, $2) RETURNING *;
 
 -- name: UpdateTopic :one
-UPDATE topics SET name = $2, description = $3 WHERE id = $1 RETURNING *;
+UPDATE topics SET name = $2, description = $3, updated_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
 
 -- name: UpdatePost :one
-UPDATE posts SET title = $2, content = $3 WHERE id = $1 RETURNING *;
+UPDATE posts SET title = $2, content = $3, content_html = $4, status = $5, flag_reason = $6, content_hash = $7, updated_at = now(), edit_count = edit_count + 1, version = version + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
 
 -- name: UpdateComment :one
-UPDATE comments SET content = $2 WHERE id = $1 RETURNING *;
+UPDATE comments SET content = $2, content_html = $3, status = $4, flag_reason = $5, content_hash = $6, updated_at = now(), edit_count = edit_count + 1, version = version + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = $2 WHERE id = $1 RETURNING *;
+
+-- name: GetTopic :one
+SELECT * FROM topics WHERE id = $1;
+
+-- name: GetTopicForUpdate :one
+SELECT * FROM topics WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;
 
 -- name: DeleteTopic :one
-DELETE FROM topics WHERE id = $1 RETURNING *;
+UPDATE topics SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
 
 -- name: DeletePost :one
-DELETE FROM posts WHERE id = $1 RETURNING *;
+UPDATE posts SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
 
 -- name: DeleteComment :one
-DELETE FROM comments WHERE id = $1 RETURNING *;
\ No newline at end of file
+UPDATE comments SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: RestoreTopic :one
+UPDATE topics SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 RETURNING *;
+
+-- name: RestorePost :one
+UPDATE posts SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 RETURNING *;
+
+-- name: RestoreComment :one
+UPDATE comments SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 RETURNING *;
+
+-- name: PurgeDeletedTopics :execrows
+DELETE FROM topics WHERE deleted_at < sqlc.arg(cutoff);
+
+-- name: PurgeDeletedPosts :execrows
+DELETE FROM posts WHERE deleted_at < sqlc.arg(cutoff);
+
+-- name: PurgeDeletedComments :execrows
+DELETE FROM comments WHERE deleted_at < sqlc.arg(cutoff);
+
+-- name: CreateRevision :exec
+INSERT INTO revisions (target_type, target_id, revision, title, content, edited_by, status) VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetLatestPublishedRevision :one
+SELECT * FROM revisions WHERE target_type = $1 AND target_id = $2 AND status = 'published' ORDER BY revision DESC LIMIT 1;
+
+-- name: ListRevisions :many
+SELECT * FROM revisions WHERE target_type = $1 AND target_id = $2 ORDER BY revision DESC;
+
+-- name: GetRevision :one
+SELECT * FROM revisions WHERE target_type = $1 AND target_id = $2 AND revision = $3;
+
+-- name: PurgeOrphanedRevisions :execrows
+DELETE FROM revisions r
+WHERE (r.target_type = 'post' AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = r.target_id))
+   OR (r.target_type = 'comment' AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.id = r.target_id));
+
+-- name: CreateReport :one
+INSERT INTO reports (target_type, target_id, reason, note, reporter_id) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: ListReportQueue :many
+SELECT
+    target_type,
+    target_id,
+    COUNT(*) AS report_count,
+    array_agg(DISTINCT reason)::text[] AS reasons,
+    MIN(created_at)::timestamp AS first_reported_at,
+    MAX(created_at)::timestamp AS last_reported_at
+FROM reports
+WHERE status = 'open'
+GROUP BY target_type, target_id
+ORDER BY report_count DESC, first_reported_at
+LIMIT $1 OFFSET $2;
+
+-- name: ListOpenReportsForTarget :many
+SELECT * FROM reports WHERE target_type = $1 AND target_id = $2 AND status = 'open' ORDER BY created_at;
+
+-- name: ResolveReports :many
+UPDATE reports SET status = 'resolved', resolution = $3, resolved_by = $4, resolved_at = now()
+WHERE target_type = $1 AND target_id = $2 AND status = 'open'
+RETURNING *;
+
+-- name: CreateUserWarning :one
+INSERT INTO user_warnings (user_id, issued_by, reason, target_type, target_id) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (actor_id, request_id, action, target_type, target_id, details, before_state, after_state) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListAuditLog :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg(actor_id)::BIGINT IS NULL OR actor_id = sqlc.narg(actor_id))
+  AND (sqlc.narg(target_type)::TEXT IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id)::BIGINT IS NULL OR target_id = sqlc.narg(target_id))
+  AND (sqlc.narg(created_from)::TIMESTAMP IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::TIMESTAMP IS NULL OR created_at < sqlc.narg(created_to))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CreateSanction :one
+INSERT INTO user_sanctions (user_id, kind, reason, issued_by, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetActiveSanction :one
+SELECT * FROM user_sanctions
+WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())
+ORDER BY kind = 'ban' DESC, expires_at DESC NULLS FIRST
+LIMIT 1;
+
+-- name: ListSanctionsForUser :many
+SELECT * FROM user_sanctions WHERE user_id = $1 ORDER BY created_at DESC;
+
+-- name: GetSanctionForUpdate :one
+SELECT * FROM user_sanctions WHERE id = $1 FOR UPDATE;
+
+-- name: RevokeSanction :one
+UPDATE user_sanctions SET revoked_at = now(), revoked_by = $2 WHERE id = $1 AND revoked_at IS NULL RETURNING *;
+
+-- name: UpsertTopicMute :one
+INSERT INTO topic_mutes (topic_id, user_id, reason, muted_by, expires_at) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (topic_id, user_id) DO UPDATE
+SET reason = EXCLUDED.reason, muted_by = EXCLUDED.muted_by, expires_at = EXCLUDED.expires_at, created_at = now()
+RETURNING *;
+
+-- name: DeleteTopicMute :one
+DELETE FROM topic_mutes WHERE topic_id = $1 AND user_id = $2 RETURNING *;
+
+-- name: ListTopicMutes :many
+SELECT * FROM topic_mutes WHERE topic_id = $1 AND (expires_at IS NULL OR expires_at > now()) ORDER BY created_at DESC;
+
+-- name: IsMutedInTopic :one
+SELECT EXISTS (
+    SELECT 1 FROM topic_mutes
+    WHERE topic_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > now())
+) AS muted;
+
+-- name: ListBannedWords :many
+SELECT * FROM banned_words ORDER BY word;
+
+-- name: UpsertBannedWord :one
+INSERT INTO banned_words (word, action, created_by) VALUES ($1, $2, $3)
+ON CONFLICT (word) DO UPDATE SET action = EXCLUDED.action
+RETURNING *;
+
+-- name: DeleteBannedWord :one
+DELETE FROM banned_words WHERE id = $1 RETURNING *;
+
+-- name: CountRecentDuplicates :one
+SELECT (
+    (SELECT COUNT(*) FROM posts p WHERE p.user_id = sqlc.arg(user_id) AND p.content_hash = sqlc.arg(content_hash) AND p.created_at > sqlc.arg(since) AND p.deleted_at IS NULL)
+    + (SELECT COUNT(*) FROM comments c WHERE c.user_id = sqlc.arg(user_id) AND c.content_hash = sqlc.arg(content_hash) AND c.created_at > sqlc.arg(since) AND c.deleted_at IS NULL)
+)::bigint AS duplicates;
+
+-- name: ListPendingPosts :many
+SELECT * FROM posts WHERE status = 'pending' AND deleted_at IS NULL ORDER BY created_at LIMIT $1 OFFSET $2;
+
+-- name: ListPendingComments :many
+SELECT * FROM comments WHERE status = 'pending' AND deleted_at IS NULL ORDER BY created_at LIMIT $1 OFFSET $2;
+
+-- name: ApprovePost :one
+UPDATE posts SET status = 'published', published_at = COALESCE(published_at, now()) WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING *;
+
+-- name: ApproveComment :one
+UPDATE comments SET status = 'published', published_at = COALESCE(published_at, now()) WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING *;
+
+-- name: RevertPost :one
+-- puts back the text of a held post's latest published revision, counting as an edit so the rejected text is kept as a revision
+UPDATE posts SET title = $2, content = $3, content_html = $4, content_hash = $5, status = 'published', flag_reason = NULL, updated_at = now(), edit_count = edit_count + 1, version = version + 1
+WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING *;
+
+-- name: RevertComment :one
+UPDATE comments SET content = $2, content_html = $3, content_hash = $4, status = 'published', flag_reason = NULL, updated_at = now(), edit_count = edit_count + 1, version = version + 1
+WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING *;
+
+-- name: ListTopicsForUser :many
+SELECT
+    t.*,
+    EXISTS(SELECT 1 FROM topic_subscriptions s WHERE s.topic_id = t.id AND s.user_id = sqlc.arg(user_id)) AS subscribed,
+    (
+        SELECT COUNT(*) FROM posts p
+        WHERE p.topic_id = t.id AND p.deleted_at IS NULL AND p.status = 'published'
+          AND p.user_id <> sqlc.arg(user_id)
+          AND p.created_at > COALESCE(v.last_visited_at, '-infinity'::timestamp)
+    )::bigint AS unread_count
+FROM topics t
+LEFT JOIN topic_visits v ON v.topic_id = t.id AND v.user_id = sqlc.arg(user_id)
+WHERE t.deleted_at IS NULL;
+
+-- name: ListTopicStates :many
+-- the part of ListTopicsForUser that differs between users, laid over a listing shared by everyone
+SELECT
+    t.id AS topic_id,
+    EXISTS(SELECT 1 FROM topic_subscriptions s WHERE s.topic_id = t.id AND s.user_id = sqlc.arg(user_id)) AS subscribed,
+    (
+        SELECT COUNT(*) FROM posts p
+        WHERE p.topic_id = t.id AND p.deleted_at IS NULL AND p.status = 'published'
+          AND p.user_id <> sqlc.arg(user_id)
+          AND p.created_at > COALESCE(v.last_visited_at, '-infinity'::timestamp)
+    )::bigint AS unread_count
+FROM topics t
+LEFT JOIN topic_visits v ON v.topic_id = t.id AND v.user_id = sqlc.arg(user_id)
+WHERE t.deleted_at IS NULL;
+
+-- name: RecordTopicVisit :exec
+INSERT INTO topic_visits (user_id, topic_id)
+SELECT sqlc.arg(user_id)::BIGINT, id FROM topics WHERE id = sqlc.arg(topic_id)
+ON CONFLICT (user_id, topic_id) DO UPDATE SET last_visited_at = now();
+
+-- name: CreateTopicSubscription :one
+INSERT INTO topic_subscriptions (user_id, topic_id) VALUES ($1, $2)
+ON CONFLICT (user_id, topic_id) DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING *;
+
+-- name: DeleteTopicSubscription :one
+DELETE FROM topic_subscriptions WHERE user_id = $1 AND topic_id = $2 RETURNING *;
+
+-- name: ListTopicSubscriptions :many
+SELECT t.* FROM topics t
+JOIN topic_subscriptions s ON s.topic_id = t.id
+WHERE s.user_id = $1 AND t.deleted_at IS NULL
+ORDER BY t.name;
+
+-- name: ListFeed :many
+SELECT p.* FROM posts p
+JOIN topic_subscriptions s ON s.topic_id = p.topic_id AND s.user_id = sqlc.arg(user_id)
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND p.status = 'published'
+  AND (sqlc.narg(cursor_created_at)::TIMESTAMP IS NULL OR (p.created_at, p.id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::BIGINT))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpsertBookmark :one
+INSERT INTO bookmarks (user_id, target_type, target_id, collection_id) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, target_type, target_id) DO UPDATE SET collection_id = EXCLUDED.collection_id
+RETURNING *;
+
+-- name: DeleteBookmark :one
+DELETE FROM bookmarks WHERE user_id = $1 AND target_type = $2 AND target_id = $3 RETURNING *;
+
+-- name: ListBookmarks :many
+SELECT
+    b.*,
+    COALESCE(p.title, cp.title, '')::text AS title,
+    COALESCE(p.content_html, c.content_html, '')::text AS content_html,
+    COALESCE(p.username, c.username, '')::text AS author,
+    COALESCE(p.topic_id, cp.topic_id, 0)::bigint AS topic_id,
+    COALESCE(p.id, c.post_id, 0)::bigint AS post_id
+FROM bookmarks b
+LEFT JOIN posts p ON b.target_type = 'post' AND p.id = b.target_id AND p.deleted_at IS NULL AND p.status = 'published'
+LEFT JOIN comments c ON b.target_type = 'comment' AND c.id = b.target_id AND c.deleted_at IS NULL AND c.status = 'published'
+LEFT JOIN posts cp ON cp.id = c.post_id AND cp.deleted_at IS NULL AND cp.status = 'published'
+LEFT JOIN topics t ON t.id = COALESCE(p.topic_id, cp.topic_id) AND t.deleted_at IS NULL
+WHERE b.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(collection_id)::BIGINT IS NULL OR b.collection_id = sqlc.narg(collection_id))
+  AND (p.id IS NOT NULL OR cp.id IS NOT NULL)
+  AND t.id IS NOT NULL
+ORDER BY b.created_at DESC, b.id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CreateBookmarkCollection :one
+INSERT INTO bookmark_collections (user_id, name) VALUES ($1, $2) RETURNING *;
+
+-- name: GetBookmarkCollection :one
+SELECT * FROM bookmark_collections WHERE id = $1 AND user_id = $2;
+
+-- name: ListBookmarkCollections :many
+SELECT * FROM bookmark_collections WHERE user_id = $1 ORDER BY name;
+
+-- name: RenameBookmarkCollection :one
+UPDATE bookmark_collections SET name = $3 WHERE id = $1 AND user_id = $2 RETURNING *;
+
+-- name: DeleteBookmarkCollection :one
+DELETE FROM bookmark_collections WHERE id = $1 AND user_id = $2 RETURNING *;
+
+-- name: GetUserProfile :one
+SELECT
+    u.id,
+    u.username,
+    u.role,
+    u.created_at,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.followee_id = u.id)::bigint AS follower_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.follower_id = u.id)::bigint AS following_count,
+    EXISTS(SELECT 1 FROM user_follows f WHERE f.follower_id = sqlc.arg(viewer_id) AND f.followee_id = u.id) AS followed_by_viewer
+FROM users u
+WHERE u.username = sqlc.arg(username);
+
+-- name: CreateUserFollow :one
+INSERT INTO user_follows (follower_id, followee_id) VALUES ($1, $2) RETURNING *;
+
+-- name: DeleteUserFollow :one
+DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2 RETURNING *;
+
+-- name: ListFollowers :many
+SELECT u.id, u.username, f.created_at AS followed_at
+FROM user_follows f
+JOIN users u ON u.id = f.follower_id
+WHERE f.followee_id = $1
+ORDER BY f.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListFollowing :many
+SELECT u.id, u.username, f.created_at AS followed_at
+FROM user_follows f
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = $1
+ORDER BY f.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListFollowedPosts :many
+SELECT p.* FROM posts p
+JOIN user_follows f ON f.followee_id = p.user_id AND f.follower_id = sqlc.arg(user_id)
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND p.status = 'published'
+  AND (sqlc.narg(cursor_created_at)::TIMESTAMP IS NULL OR (p.created_at, p.id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::BIGINT))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListFollowedComments :many
+SELECT c.*, p.topic_id, p.title AS post_title FROM comments c
+JOIN user_follows f ON f.followee_id = c.user_id AND f.follower_id = sqlc.arg(user_id)
+JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL AND p.status = 'published'
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE c.deleted_at IS NULL AND c.status = 'published'
+  AND (sqlc.narg(cursor_created_at)::TIMESTAMP IS NULL OR (c.created_at, c.id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::BIGINT))
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: NotifyFollowers :execrows
+INSERT INTO notifications (user_id, kind, actor_id, target_type, target_id)
+SELECT f.follower_id, sqlc.arg(kind)::TEXT, f.followee_id, sqlc.arg(target_type)::TEXT, sqlc.arg(target_id)::BIGINT
+FROM user_follows f
+WHERE f.followee_id = sqlc.arg(actor_id);
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg(user_id) AND (NOT sqlc.arg(unread_only)::BOOLEAN OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) AS unread_count FROM notifications WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationsRead :execrows
+UPDATE notifications SET read_at = now()
+WHERE user_id = sqlc.arg(user_id) AND read_at IS NULL
+  AND (cardinality(sqlc.arg(ids)::BIGINT[]) = 0 OR id = ANY(sqlc.arg(ids)::BIGINT[]));
+
+-- name: UpsertTag :one
+-- the no-op update makes RETURNING hand back tags that already exist
+INSERT INTO tags (name) VALUES ($1)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: RestrictTag :one
+INSERT INTO tags (name, topic_id) VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET topic_id = EXCLUDED.topic_id
+RETURNING *;
+
+-- name: DeletePostTags :exec
+DELETE FROM post_tags WHERE post_id = $1;
+
+-- name: AddPostTag :exec
+INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: ListPostTags :many
+SELECT t.name FROM post_tags pt
+JOIN tags t ON t.id = pt.tag_id
+WHERE pt.post_id = $1
+ORDER BY t.name;
+
+-- name: SearchTags :many
+-- tag names never contain LIKE wildcards so the prefix can be matched as is
+SELECT t.*, COUNT(pt.post_id)::bigint AS post_count
+FROM tags t
+LEFT JOIN post_tags pt ON pt.tag_id = t.id
+WHERE t.name LIKE sqlc.arg(prefix)::text || '%'
+  AND (t.topic_id IS NULL OR t.topic_id = sqlc.narg(topic_id)::BIGINT)
+GROUP BY t.id
+ORDER BY post_count DESC, t.name
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListPostsByTag :many
+SELECT
+    p.*,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = sqlc.arg(user_id) AND b.target_type = 'post' AND b.target_id = p.id) AS saved,
+    ARRAY(SELECT t2.name FROM post_tags pt2 JOIN tags t2 ON t2.id = pt2.tag_id WHERE pt2.post_id = p.id ORDER BY t2.name)::text[] AS tags
+FROM posts p
+JOIN post_tags pt ON pt.post_id = p.id
+JOIN tags t ON t.id = pt.tag_id AND t.name = sqlc.arg(tag)
+JOIN topics tp ON tp.id = p.topic_id AND tp.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND p.status = 'published'
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: SetPostState :one
+-- archiving keeps the original archived_at if the post was already archived
+UPDATE posts SET
+    pinned = sqlc.arg(pinned),
+    locked = sqlc.arg(locked),
+    archived_at = CASE WHEN sqlc.arg(archived)::BOOLEAN THEN COALESCE(archived_at, now()) ELSE NULL END
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ArchiveInactivePosts :many
+-- a post is active when it or any of its live comments was written or edited after the cutoff
+-- pinned posts are left alone since they are usually long running announcements
+UPDATE posts p SET archived_at = now()
+WHERE p.archived_at IS NULL AND p.deleted_at IS NULL AND NOT p.pinned
+  AND GREATEST(
+      p.created_at,
+      p.updated_at,
+      (SELECT MAX(GREATEST(c.created_at, c.updated_at)) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL)
+  ) < sqlc.arg(cutoff)::TIMESTAMP
+RETURNING p.id;
+
+-- name: CreatePoll :one
+INSERT INTO polls (post_id, question, multiple_choice, hide_results, closes_at) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: CreatePollOption :one
+INSERT INTO poll_options (poll_id, position, label) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: GetPoll :one
+SELECT * FROM polls WHERE id = $1;
+
+-- name: GetPollByPostID :one
+SELECT * FROM polls WHERE post_id = $1;
+
+-- name: ListPollResults :many
+SELECT o.*, COUNT(v.user_id)::bigint AS votes
+FROM poll_options o
+LEFT JOIN poll_votes v ON v.option_id = o.id
+WHERE o.poll_id = $1
+GROUP BY o.id
+ORDER BY o.position;
+
+-- name: CountPollBallots :one
+SELECT COUNT(*)::bigint AS ballots FROM poll_ballots WHERE poll_id = $1;
+
+-- name: ListBallotChoices :many
+SELECT option_id FROM poll_votes WHERE poll_id = $1 AND user_id = $2 ORDER BY option_id;
+
+-- name: CreatePollBallot :one
+INSERT INTO poll_ballots (poll_id, user_id) VALUES ($1, $2) RETURNING *;
+
+-- name: TouchPollBallot :one
+-- locks the ballot so two changes from the same user can't interleave
+UPDATE poll_ballots SET updated_at = now() WHERE poll_id = $1 AND user_id = $2 RETURNING *;
+
+-- name: DeleteBallotChoices :exec
+DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2;
+
+-- name: CreatePollVote :exec
+INSERT INTO poll_votes (poll_id, user_id, option_id) VALUES ($1, $2, $3);
+
+-- name: CreateAttachment :one
+INSERT INTO attachments (storage_key, user_id, filename, content_type, size_bytes, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetAttachment :one
+SELECT * FROM attachments WHERE id = $1;
+
+-- name: AttachUploads :many
+-- only the uploader's own unattached uploads can be linked
+UPDATE attachments SET target_type = sqlc.arg(target_type), target_id = sqlc.arg(target_id), attached_at = now()
+WHERE id = ANY(sqlc.arg(ids)::BIGINT[]) AND user_id = sqlc.arg(user_id) AND target_id IS NULL
+RETURNING *;
+
+-- name: ListAttachments :many
+SELECT * FROM attachments WHERE target_type = $1 AND target_id = $2 ORDER BY id;
+
+-- name: ListOrphanedAttachments :many
+-- uploads never attached to anything, or whose post or comment has been purged
+SELECT a.* FROM attachments a
+WHERE (a.target_id IS NULL AND a.created_at < sqlc.arg(cutoff)::TIMESTAMP)
+   OR (a.target_type = 'post' AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = a.target_id))
+   OR (a.target_type = 'comment' AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.id = a.target_id))
+ORDER BY a.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteOrphanedAttachment :execrows
+-- checks again that the upload is still orphaned in case it was attached since it was listed
+DELETE FROM attachments a
+WHERE a.id = $1 AND (
+    a.target_id IS NULL
+    OR (a.target_type = 'post' AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = a.target_id))
+    OR (a.target_type = 'comment' AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.id = a.target_id))
+);
+
+-- name: FinishAttachmentProcessing :one
+UPDATE attachments SET status = 'ready', content_type = $2, size_bytes = $3, width = $4, height = $5
+WHERE id = $1 AND status = 'processing'
+RETURNING *;
+
+-- name: ClaimAttachmentProcessing :one
+-- only one worker gets the upload, a claim made before the cutoff is taken to have died with its worker
+UPDATE attachments SET processing_attempts = processing_attempts + 1, processing_claimed_at = now()
+WHERE id = $1 AND status = 'processing'
+  AND (processing_claimed_at IS NULL OR processing_claimed_at < sqlc.arg(cutoff)::TIMESTAMP)
+RETURNING *;
+
+-- name: ReleaseAttachmentProcessing :exec
+-- lets the next resume pick the upload up again after a failed attempt
+UPDATE attachments SET processing_claimed_at = NULL WHERE id = $1 AND status = 'processing';
+
+-- name: FailAttachmentProcessing :exec
+UPDATE attachments SET status = 'failed', processing_error = $2 WHERE id = $1 AND status = 'processing';
+
+-- name: ListStalledAttachments :many
+-- uploads still waiting for processing, e.g. because the server restarted before the queue was drained
+SELECT id FROM attachments
+WHERE status = 'processing' AND created_at < sqlc.arg(cutoff)::TIMESTAMP
+  AND (processing_claimed_at IS NULL OR processing_claimed_at < sqlc.arg(cutoff)::TIMESTAMP)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpsertAttachmentVariant :one
+INSERT INTO attachment_variants (attachment_id, name, storage_key, content_type, size_bytes, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (attachment_id, name) DO UPDATE SET
+    storage_key = EXCLUDED.storage_key,
+    content_type = EXCLUDED.content_type,
+    size_bytes = EXCLUDED.size_bytes,
+    width = EXCLUDED.width,
+    height = EXCLUDED.height
+RETURNING *;
+
+-- name: GetAttachmentVariant :one
+SELECT * FROM attachment_variants WHERE attachment_id = $1 AND name = $2;
+
+-- name: ListAttachmentVariants :many
+SELECT * FROM attachment_variants WHERE attachment_id = $1 ORDER BY width;
+
+-- name: UpdateUserPassword :one
+UPDATE users SET password = $2 WHERE id = $1 RETURNING *;
+
+-- name: ListPostsForReindex :many
+SELECT id, content FROM posts
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: SetPostIndex :exec
+-- leaves updated_at alone, rebuilding derived columns is not an edit
+UPDATE posts SET content_html = $2, content_hash = $3 WHERE id = $1;
+
+-- name: ListCommentsForReindex :many
+SELECT id, content FROM comments
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: SetCommentIndex :exec
+UPDATE comments SET content_html = $2, content_hash = $3 WHERE id = $1;
+
+-- name: RepairPostCounters :execrows
+-- recounts every post and only writes the ones that drifted, the count is how many were wrong
+UPDATE posts p SET comment_count = s.comment_count, last_activity_at = s.last_activity_at
+FROM (
+    SELECT p2.id, COUNT(c.id)::INT AS comment_count, GREATEST(p2.created_at, MAX(c.created_at)) AS last_activity_at
+    FROM posts p2
+    LEFT JOIN comments c ON c.post_id = p2.id AND c.deleted_at IS NULL AND c.status = 'published'
+    GROUP BY p2.id
+) s
+WHERE s.id = p.id AND (p.comment_count, p.last_activity_at) IS DISTINCT FROM (s.comment_count, s.last_activity_at);
+
+-- name: RepairTopicCounters :execrows
+UPDATE topics t SET post_count = s.post_count, last_post_at = s.last_post_at
+FROM (
+    SELECT t2.id, COUNT(p.id)::INT AS post_count, MAX(p.created_at) AS last_post_at
+    FROM topics t2
+    LEFT JOIN posts p ON p.topic_id = t2.id AND p.deleted_at IS NULL AND p.status = 'published'
+    GROUP BY t2.id
+) s
+WHERE s.id = t.id AND (t.post_count, t.last_post_at) IS DISTINCT FROM (s.post_count, s.last_post_at);
+
+-- name: ExportUsers :many
+-- password hashes are only selected when asked for
+SELECT
+    id, username, role, created_at,
+    (CASE WHEN sqlc.arg(include_passwords)::BOOLEAN THEN password ELSE '' END)::TEXT AS password
+FROM users
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportTopics :many
+SELECT * FROM topics
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportTags :many
+SELECT * FROM tags
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportPosts :many
+SELECT
+    p.*,
+    ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name)::text[] AS tags
+FROM posts p
+WHERE p.id > sqlc.arg(after_id)
+ORDER BY p.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportPolls :many
+SELECT * FROM polls
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListPollOptions :many
+SELECT * FROM poll_options WHERE poll_id = $1 ORDER BY position;
+
+-- name: ExportPollBallots :many
+SELECT
+    b.user_id, b.created_at, b.updated_at,
+    ARRAY(SELECT v.option_id FROM poll_votes v WHERE v.poll_id = b.poll_id AND v.user_id = b.user_id ORDER BY v.option_id)::bigint[] AS option_ids
+FROM poll_ballots b
+WHERE b.poll_id = $1
+ORDER BY b.user_id;
+
+-- name: ExportComments :many
+SELECT * FROM comments
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportRevisions :many
+SELECT * FROM revisions
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportUserFollows :many
+SELECT * FROM user_follows
+WHERE (follower_id, followee_id) > (sqlc.arg(after_follower_id)::BIGINT, sqlc.arg(after_followee_id)::BIGINT)
+ORDER BY follower_id, followee_id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportTopicSubscriptions :many
+SELECT * FROM topic_subscriptions
+WHERE (user_id, topic_id) > (sqlc.arg(after_user_id)::BIGINT, sqlc.arg(after_topic_id)::BIGINT)
+ORDER BY user_id, topic_id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportBookmarkCollections :many
+SELECT * FROM bookmark_collections
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ExportBookmarks :many
+SELECT * FROM bookmarks
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetImportMapping :one
+SELECT new_id FROM import_mappings WHERE source = $1 AND kind = $2 AND old_id = $3;
+
+-- name: CreateImportMapping :exec
+INSERT INTO import_mappings (source, kind, old_id, new_id) VALUES ($1, $2, $3, $4);
+
+-- name: ImportUser :one
+-- the importer picks a free username first, so a clash means the name was taken in the meantime and fails the batch
+INSERT INTO users (username, password, role, created_at) VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ImportTopic :one
+-- an existing topic with the same name is reused as is
+INSERT INTO topics (name, description, user_id, username, created_at, deleted_at, deleted_by) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: ImportTag :one
+-- an existing tag keeps its own restriction
+INSERT INTO tags (name, topic_id, created_at) VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: ImportPost :one
+-- activity starts at the original created_at, the comments imported after it move it forward
+-- published rows count as published since their creation
+INSERT INTO posts (title, content, content_html, user_id, username, topic_id, created_at, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, last_activity_at, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $7, CASE WHEN $12 = 'published' THEN $7 END)
+RETURNING *;
+
+-- name: ImportPoll :one
+-- returns no rows when the post already has a poll, which means it was imported before
+INSERT INTO polls (post_id, question, multiple_choice, hide_results, closes_at, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (post_id) DO NOTHING
+RETURNING *;
+
+-- name: ImportPollBallot :exec
+INSERT INTO poll_ballots (poll_id, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4);
+
+-- name: ImportComment :one
+INSERT INTO comments (content, content_html, user_id, username, post_id, created_at, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, CASE WHEN $11 = 'published' THEN $6 END)
+RETURNING *;
+
+-- name: ImportRevision :execrows
+INSERT INTO revisions (target_type, target_id, revision, title, content, edited_by, created_at, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (target_type, target_id, revision) DO NOTHING;
+
+-- name: ImportUserFollow :execrows
+INSERT INTO user_follows (follower_id, followee_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;
+
+-- name: ImportTopicSubscription :execrows
+INSERT INTO topic_subscriptions (user_id, topic_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;
+
+-- name: ImportBookmarkCollection :one
+-- an existing collection with the same name is reused as is
+INSERT INTO bookmark_collections (user_id, name, created_at) VALUES ($1, $2, $3)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: ImportBookmark :execrows
+INSERT INTO bookmarks (user_id, target_type, target_id, collection_id, created_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING;
@@ -7,25 +7,374 @@ package repo
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addPostTag = `-- name: AddPostTag :exec
+INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type AddPostTagParams struct {
+	PostID int64 `json:"post_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+func (q *Queries) AddPostTag(ctx context.Context, arg AddPostTagParams) error {
+	_, err := q.db.Exec(ctx, addPostTag, arg.PostID, arg.TagID)
+	return err
+}
+
+const approveComment = `-- name: ApproveComment :one
+UPDATE comments SET status = 'published', published_at = COALESCE(published_at, now()) WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at
+`
+
+func (q *Queries) ApproveComment(ctx context.Context, id int64) (Comment, error) {
+	row := q.db.QueryRow(ctx, approveComment, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const approvePost = `-- name: ApprovePost :one
+UPDATE posts SET status = 'published', published_at = COALESCE(published_at, now()) WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
+`
+
+func (q *Queries) ApprovePost(ctx context.Context, id int64) (Post, error) {
+	row := q.db.QueryRow(ctx, approvePost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const archiveInactivePosts = `-- name: ArchiveInactivePosts :many
+UPDATE posts p SET archived_at = now()
+WHERE p.archived_at IS NULL AND p.deleted_at IS NULL AND NOT p.pinned
+  AND GREATEST(
+      p.created_at,
+      p.updated_at,
+      (SELECT MAX(GREATEST(c.created_at, c.updated_at)) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL)
+  ) < $1::TIMESTAMP
+RETURNING p.id
+`
+
+// a post is active when it or any of its live comments was written or edited after the cutoff
+// pinned posts are left alone since they are usually long running announcements
+func (q *Queries) ArchiveInactivePosts(ctx context.Context, cutoff pgtype.Timestamp) ([]int64, error) {
+	rows, err := q.db.Query(ctx, archiveInactivePosts, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const attachUploads = `-- name: AttachUploads :many
+UPDATE attachments SET target_type = $1, target_id = $2, attached_at = now()
+WHERE id = ANY($3::BIGINT[]) AND user_id = $4 AND target_id IS NULL
+RETURNING id, storage_key, user_id, filename, content_type, size_bytes, target_type, target_id, created_at, attached_at, status, width, height, processing_error, processing_attempts, processing_claimed_at
+`
+
+type AttachUploadsParams struct {
+	TargetType pgtype.Text `json:"target_type"`
+	TargetID   pgtype.Int8 `json:"target_id"`
+	Ids        []int64     `json:"ids"`
+	UserID     int64       `json:"user_id"`
+}
+
+// only the uploader's own unattached uploads can be linked
+func (q *Queries) AttachUploads(ctx context.Context, arg AttachUploadsParams) ([]Attachment, error) {
+	rows, err := q.db.Query(ctx, attachUploads,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Ids,
+		arg.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.StorageKey,
+			&i.UserID,
+			&i.Filename,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.TargetType,
+			&i.TargetID,
+			&i.CreatedAt,
+			&i.AttachedAt,
+			&i.Status,
+			&i.Width,
+			&i.Height,
+			&i.ProcessingError,
+			&i.ProcessingAttempts,
+			&i.ProcessingClaimedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimAttachmentProcessing = `-- name: ClaimAttachmentProcessing :one
+UPDATE attachments SET processing_attempts = processing_attempts + 1, processing_claimed_at = now()
+WHERE id = $1 AND status = 'processing'
+  AND (processing_claimed_at IS NULL OR processing_claimed_at < $2::TIMESTAMP)
+RETURNING id, storage_key, user_id, filename, content_type, size_bytes, target_type, target_id, created_at, attached_at, status, width, height, processing_error, processing_attempts, processing_claimed_at
+`
+
+type ClaimAttachmentProcessingParams struct {
+	ID     int64            `json:"id"`
+	Cutoff pgtype.Timestamp `json:"cutoff"`
+}
+
+// only one worker gets the upload, a claim made before the cutoff is taken to have died with its worker
+func (q *Queries) ClaimAttachmentProcessing(ctx context.Context, arg ClaimAttachmentProcessingParams) (Attachment, error) {
+	row := q.db.QueryRow(ctx, claimAttachmentProcessing, arg.ID, arg.Cutoff)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.StorageKey,
+		&i.UserID,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CreatedAt,
+		&i.AttachedAt,
+		&i.Status,
+		&i.Width,
+		&i.Height,
+		&i.ProcessingError,
+		&i.ProcessingAttempts,
+		&i.ProcessingClaimedAt,
+	)
+	return i, err
+}
+
+const countPollBallots = `-- name: CountPollBallots :one
+SELECT COUNT(*)::bigint AS ballots FROM poll_ballots WHERE poll_id = $1
+`
+
+func (q *Queries) CountPollBallots(ctx context.Context, pollID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countPollBallots, pollID)
+	var ballots int64
+	err := row.Scan(&ballots)
+	return ballots, err
+}
+
+const countRecentDuplicates = `-- name: CountRecentDuplicates :one
+SELECT (
+    (SELECT COUNT(*) FROM posts p WHERE p.user_id = $1 AND p.content_hash = $2 AND p.created_at > $3 AND p.deleted_at IS NULL)
+    + (SELECT COUNT(*) FROM comments c WHERE c.user_id = $1 AND c.content_hash = $2 AND c.created_at > $3 AND c.deleted_at IS NULL)
+)::bigint AS duplicates
+`
+
+type CountRecentDuplicatesParams struct {
+	UserID      int64            `json:"user_id"`
+	ContentHash string           `json:"content_hash"`
+	Since       pgtype.Timestamp `json:"since"`
+}
+
+func (q *Queries) CountRecentDuplicates(ctx context.Context, arg CountRecentDuplicatesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countRecentDuplicates, arg.UserID, arg.ContentHash, arg.Since)
+	var duplicates int64
+	err := row.Scan(&duplicates)
+	return duplicates, err
+}
+
+const countUnreadNotifications = `-- name: CountUnreadNotifications :one
+SELECT COUNT(*) AS unread_count FROM notifications WHERE user_id = $1 AND read_at IS NULL
+`
+
+func (q *Queries) CountUnreadNotifications(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnreadNotifications, userID)
+	var unreadCount int64
+	err := row.Scan(&unreadCount)
+	return unreadCount, err
+}
+
+const createAttachment = `-- name: CreateAttachment :one
+INSERT INTO attachments (storage_key, user_id, filename, content_type, size_bytes, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, storage_key, user_id, filename, content_type, size_bytes, target_type, target_id, created_at, attached_at, status, width, height, processing_error, processing_attempts, processing_claimed_at
+`
+
+type CreateAttachmentParams struct {
+	StorageKey  string `json:"storage_key"`
+	UserID      int64  `json:"user_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Status      string `json:"status"`
+}
+
+func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error) {
+	row := q.db.QueryRow(ctx, createAttachment,
+		arg.StorageKey,
+		arg.UserID,
+		arg.Filename,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Status,
+	)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.StorageKey,
+		&i.UserID,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CreatedAt,
+		&i.AttachedAt,
+		&i.Status,
+		&i.Width,
+		&i.Height,
+		&i.ProcessingError,
+		&i.ProcessingAttempts,
+		&i.ProcessingClaimedAt,
+	)
+	return i, err
+}
+
+const createAuditLogEntry = `-- name: CreateAuditLogEntry :exec
+INSERT INTO audit_log (actor_id, request_id, action, target_type, target_id, details, before_state, after_state) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateAuditLogEntryParams struct {
+	ActorID     pgtype.Int8 `json:"actor_id"`
+	RequestID   string      `json:"request_id"`
+	Action      string      `json:"action"`
+	TargetType  string      `json:"target_type"`
+	TargetID    int64       `json:"target_id"`
+	Details     []byte      `json:"details"`
+	BeforeState []byte      `json:"before_state"`
+	AfterState  []byte      `json:"after_state"`
+}
+
+func (q *Queries) CreateAuditLogEntry(ctx context.Context, arg CreateAuditLogEntryParams) error {
+	_, err := q.db.Exec(ctx, createAuditLogEntry,
+		arg.ActorID,
+		arg.RequestID,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Details,
+		arg.BeforeState,
+		arg.AfterState,
+	)
+	return err
+}
+
+const createBookmarkCollection = `-- name: CreateBookmarkCollection :one
+INSERT INTO bookmark_collections (user_id, name) VALUES ($1, $2) RETURNING id, user_id, name, created_at
+`
+
+type CreateBookmarkCollectionParams struct {
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) CreateBookmarkCollection(ctx context.Context, arg CreateBookmarkCollectionParams) (BookmarkCollection, error) {
+	row := q.db.QueryRow(ctx, createBookmarkCollection, arg.UserID, arg.Name)
+	var i BookmarkCollection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createComment = `-- name: CreateComment :one
-INSERT INTO comments (content, post_id, user_id, username) VALUES ($1, $2, $3, $4) RETURNING id, content, user_id, username, post_id, created_at
+INSERT INTO comments (content, content_html, post_id, user_id, username, status, flag_reason, content_hash, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $6 = 'published' THEN now() END) RETURNING id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at
 `
 
 type CreateCommentParams struct {
-	Content  string `json:"content"`
-	PostID   int64  `json:"post_id"`
-	UserID   int64  `json:"user_id"`
-	Username string `json:"username"`
+	Content     string `json:"content"`
+	ContentHtml string `json:"content_html"`
+	PostID      int64  `json:"post_id"`
+	UserID      int64  `json:"user_id"`
+	Username    string `json:"username"`
+	Status      string `json:"status"`
+	FlagReason  string `json:"flag_reason"`
+	ContentHash string `json:"content_hash"`
 }
 
 func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
 	row := q.db.QueryRow(ctx, createComment,
 		arg.Content,
+		arg.ContentHtml,
 		arg.PostID,
 		arg.UserID,
 		arg.Username,
+		arg.Status,
+		arg.FlagReason,
+		arg.ContentHash,
 	)
 	var i Comment
 	err := row.Scan(
@@ -35,29 +384,160 @@ func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (C
 		&i.Username,
 		&i.PostID,
 		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const createImportMapping = `-- name: CreateImportMapping :exec
+INSERT INTO import_mappings (source, kind, old_id, new_id) VALUES ($1, $2, $3, $4)
+`
+
+type CreateImportMappingParams struct {
+	Source string `json:"source"`
+	Kind   string `json:"kind"`
+	OldID  int64  `json:"old_id"`
+	NewID  int64  `json:"new_id"`
+}
+
+func (q *Queries) CreateImportMapping(ctx context.Context, arg CreateImportMappingParams) error {
+	_, err := q.db.Exec(ctx, createImportMapping,
+		arg.Source,
+		arg.Kind,
+		arg.OldID,
+		arg.NewID,
+	)
+	return err
+}
+
+const createPoll = `-- name: CreatePoll :one
+INSERT INTO polls (post_id, question, multiple_choice, hide_results, closes_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, post_id, question, multiple_choice, hide_results, closes_at, created_at
+`
+
+type CreatePollParams struct {
+	PostID         int64            `json:"post_id"`
+	Question       string           `json:"question"`
+	MultipleChoice bool             `json:"multiple_choice"`
+	HideResults    bool             `json:"hide_results"`
+	ClosesAt       pgtype.Timestamp `json:"closes_at"`
+}
+
+func (q *Queries) CreatePoll(ctx context.Context, arg CreatePollParams) (Poll, error) {
+	row := q.db.QueryRow(ctx, createPoll,
+		arg.PostID,
+		arg.Question,
+		arg.MultipleChoice,
+		arg.HideResults,
+		arg.ClosesAt,
+	)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.Question,
+		&i.MultipleChoice,
+		&i.HideResults,
+		&i.ClosesAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPollBallot = `-- name: CreatePollBallot :one
+INSERT INTO poll_ballots (poll_id, user_id) VALUES ($1, $2) RETURNING poll_id, user_id, created_at, updated_at
+`
+
+type CreatePollBallotParams struct {
+	PollID int64 `json:"poll_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) CreatePollBallot(ctx context.Context, arg CreatePollBallotParams) (PollBallot, error) {
+	row := q.db.QueryRow(ctx, createPollBallot, arg.PollID, arg.UserID)
+	var i PollBallot
+	err := row.Scan(
+		&i.PollID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createPollOption = `-- name: CreatePollOption :one
+INSERT INTO poll_options (poll_id, position, label) VALUES ($1, $2, $3) RETURNING id, poll_id, position, label
+`
+
+type CreatePollOptionParams struct {
+	PollID   int64  `json:"poll_id"`
+	Position int32  `json:"position"`
+	Label    string `json:"label"`
+}
+
+func (q *Queries) CreatePollOption(ctx context.Context, arg CreatePollOptionParams) (PollOption, error) {
+	row := q.db.QueryRow(ctx, createPollOption, arg.PollID, arg.Position, arg.Label)
+	var i PollOption
+	err := row.Scan(
+		&i.ID,
+		&i.PollID,
+		&i.Position,
+		&i.Label,
 	)
 	return i, err
 }
 
+const createPollVote = `-- name: CreatePollVote :exec
+INSERT INTO poll_votes (poll_id, user_id, option_id) VALUES ($1, $2, $3)
+`
+
+type CreatePollVoteParams struct {
+	PollID   int64 `json:"poll_id"`
+	UserID   int64 `json:"user_id"`
+	OptionID int64 `json:"option_id"`
+}
+
+func (q *Queries) CreatePollVote(ctx context.Context, arg CreatePollVoteParams) error {
+	_, err := q.db.Exec(ctx, createPollVote, arg.PollID, arg.UserID, arg.OptionID)
+	return err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (title, content, topic_id, user_id, username) VALUES ($1, $2, $3, $4, $5) RETURNING id, title, content, user_id, username, topic_id, created_at
+INSERT INTO posts (title, content, content_html, topic_id, user_id, username, status, flag_reason, content_hash, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CASE WHEN $7 = 'published' THEN now() END) RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
 `
 
 type CreatePostParams struct {
-	Title    string `json:"title"`
-	Content  string `json:"content"`
-	TopicID  int64  `json:"topic_id"`
-	UserID   int64  `json:"user_id"`
-	Username string `json:"username"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	ContentHtml string `json:"content_html"`
+	TopicID     int64  `json:"topic_id"`
+	UserID      int64  `json:"user_id"`
+	Username    string `json:"username"`
+	Status      string `json:"status"`
+	FlagReason  string `json:"flag_reason"`
+	ContentHash string `json:"content_hash"`
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
 	row := q.db.QueryRow(ctx, createPost,
 		arg.Title,
 		arg.Content,
+		arg.ContentHtml,
 		arg.TopicID,
 		arg.UserID,
 		arg.Username,
+		arg.Status,
+		arg.FlagReason,
+		arg.ContentHash,
 	)
 	var i Post
 	err := row.Scan(
@@ -68,12 +548,126 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Username,
 		&i.TopicID,
 		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const createReport = `-- name: CreateReport :one
+INSERT INTO reports (target_type, target_id, reason, note, reporter_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, target_type, target_id, reason, note, reporter_id, status, resolution, resolved_by, resolved_at, created_at
+`
+
+type CreateReportParams struct {
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note"`
+	ReporterID int64  `json:"reporter_id"`
+}
+
+func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
+	row := q.db.QueryRow(ctx, createReport,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Reason,
+		arg.Note,
+		arg.ReporterID,
+	)
+	var i Report
+	err := row.Scan(
+		&i.ID,
+		&i.TargetType,
+		&i.TargetID,
+		&i.Reason,
+		&i.Note,
+		&i.ReporterID,
+		&i.Status,
+		&i.Resolution,
+		&i.ResolvedBy,
+		&i.ResolvedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRevision = `-- name: CreateRevision :exec
+INSERT INTO revisions (target_type, target_id, revision, title, content, edited_by, status) VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateRevisionParams struct {
+	TargetType string      `json:"target_type"`
+	TargetID   int64       `json:"target_id"`
+	Revision   int32       `json:"revision"`
+	Title      pgtype.Text `json:"title"`
+	Content    string      `json:"content"`
+	EditedBy   pgtype.Int8 `json:"edited_by"`
+	Status     string      `json:"status"`
+}
+
+func (q *Queries) CreateRevision(ctx context.Context, arg CreateRevisionParams) error {
+	_, err := q.db.Exec(ctx, createRevision,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Revision,
+		arg.Title,
+		arg.Content,
+		arg.EditedBy,
+		arg.Status,
+	)
+	return err
+}
+
+const createSanction = `-- name: CreateSanction :one
+INSERT INTO user_sanctions (user_id, kind, reason, issued_by, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, kind, reason, issued_by, expires_at, revoked_at, revoked_by, created_at
+`
+
+type CreateSanctionParams struct {
+	UserID    int64            `json:"user_id"`
+	Kind      string           `json:"kind"`
+	Reason    string           `json:"reason"`
+	IssuedBy  pgtype.Int8      `json:"issued_by"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+func (q *Queries) CreateSanction(ctx context.Context, arg CreateSanctionParams) (UserSanction, error) {
+	row := q.db.QueryRow(ctx, createSanction,
+		arg.UserID,
+		arg.Kind,
+		arg.Reason,
+		arg.IssuedBy,
+		arg.ExpiresAt,
+	)
+	var i UserSanction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.Reason,
+		&i.IssuedBy,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.RevokedBy,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const createTopic = `-- name: CreateTopic :one
-INSERT INTO topics (name, description, user_id, username) VALUES ($1, $2, $3, $4) RETURNING id, name, description, user_id, username, created_at
+INSERT INTO topics (name, description, user_id, username) VALUES ($1, $2, $3, $4) RETURNING id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version
 `
 
 type CreateTopicParams struct {
@@ -83,13 +677,4269 @@ type CreateTopicParams struct {
 	Username    string `json:"username"`
 }
 
-func (q *Queries) CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic, error) {
-	row := q.db.QueryRow(ctx, createTopic,
-		arg.Name,
-		arg.Description,
-		arg.UserID,
-		arg.Username,
-	)
+func (q *Queries) CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic, error) {
+	row := q.db.QueryRow(ctx, createTopic,
+		arg.Name,
+		arg.Description,
+		arg.UserID,
+		arg.Username,
+	)
+	var i Topic
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.UserID,
+		&i.Username,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PostCount,
+		&i.LastPostAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createTopicSubscription = `-- name: CreateTopicSubscription :one
+INSERT INTO topic_subscriptions (user_id, topic_id) VALUES ($1, $2)
+ON CONFLICT (user_id, topic_id) DO UPDATE SET user_id = EXCLUDED.user_id
+RETURNING user_id, topic_id, created_at
+`
+
+type CreateTopicSubscriptionParams struct {
+	UserID  int64 `json:"user_id"`
+	TopicID int64 `json:"topic_id"`
+}
+
+func (q *Queries) CreateTopicSubscription(ctx context.Context, arg CreateTopicSubscriptionParams) (TopicSubscription, error) {
+	row := q.db.QueryRow(ctx, createTopicSubscription, arg.UserID, arg.TopicID)
+	var i TopicSubscription
+	err := row.Scan(&i.UserID, &i.TopicID, &i.CreatedAt)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (username, password) VALUES ($1, $2) RETURNING id, username, password, created_at, role
+`
+
+type CreateUserParams struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createUser, arg.Username, arg.Password)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const createUserFollow = `-- name: CreateUserFollow :one
+INSERT INTO user_follows (follower_id, followee_id) VALUES ($1, $2) RETURNING follower_id, followee_id, created_at
+`
+
+type CreateUserFollowParams struct {
+	FollowerID int64 `json:"follower_id"`
+	FolloweeID int64 `json:"followee_id"`
+}
+
+func (q *Queries) CreateUserFollow(ctx context.Context, arg CreateUserFollowParams) (UserFollow, error) {
+	row := q.db.QueryRow(ctx, createUserFollow, arg.FollowerID, arg.FolloweeID)
+	var i UserFollow
+	err := row.Scan(&i.FollowerID, &i.FolloweeID, &i.CreatedAt)
+	return i, err
+}
+
+const createUserWarning = `-- name: CreateUserWarning :one
+INSERT INTO user_warnings (user_id, issued_by, reason, target_type, target_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, user_id, issued_by, reason, target_type, target_id, created_at
+`
+
+type CreateUserWarningParams struct {
+	UserID     int64       `json:"user_id"`
+	IssuedBy   pgtype.Int8 `json:"issued_by"`
+	Reason     string      `json:"reason"`
+	TargetType string      `json:"target_type"`
+	TargetID   int64       `json:"target_id"`
+}
+
+func (q *Queries) CreateUserWarning(ctx context.Context, arg CreateUserWarningParams) (UserWarning, error) {
+	row := q.db.QueryRow(ctx, createUserWarning,
+		arg.UserID,
+		arg.IssuedBy,
+		arg.Reason,
+		arg.TargetType,
+		arg.TargetID,
+	)
+	var i UserWarning
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.IssuedBy,
+		&i.Reason,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteBallotChoices = `-- name: DeleteBallotChoices :exec
+DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2
+`
+
+type DeleteBallotChoicesParams struct {
+	PollID int64 `json:"poll_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteBallotChoices(ctx context.Context, arg DeleteBallotChoicesParams) error {
+	_, err := q.db.Exec(ctx, deleteBallotChoices, arg.PollID, arg.UserID)
+	return err
+}
+
+const deleteBannedWord = `-- name: DeleteBannedWord :one
+DELETE FROM banned_words WHERE id = $1 RETURNING id, word, action, created_by, created_at
+`
+
+func (q *Queries) DeleteBannedWord(ctx context.Context, id int64) (BannedWord, error) {
+	row := q.db.QueryRow(ctx, deleteBannedWord, id)
+	var i BannedWord
+	err := row.Scan(
+		&i.ID,
+		&i.Word,
+		&i.Action,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteBookmark = `-- name: DeleteBookmark :one
+DELETE FROM bookmarks WHERE user_id = $1 AND target_type = $2 AND target_id = $3 RETURNING id, user_id, target_type, target_id, collection_id, created_at
+`
+
+type DeleteBookmarkParams struct {
+	UserID     int64  `json:"user_id"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+}
+
+func (q *Queries) DeleteBookmark(ctx context.Context, arg DeleteBookmarkParams) (Bookmark, error) {
+	row := q.db.QueryRow(ctx, deleteBookmark, arg.UserID, arg.TargetType, arg.TargetID)
+	var i Bookmark
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CollectionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteBookmarkCollection = `-- name: DeleteBookmarkCollection :one
+DELETE FROM bookmark_collections WHERE id = $1 AND user_id = $2 RETURNING id, user_id, name, created_at
+`
+
+type DeleteBookmarkCollectionParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteBookmarkCollection(ctx context.Context, arg DeleteBookmarkCollectionParams) (BookmarkCollection, error) {
+	row := q.db.QueryRow(ctx, deleteBookmarkCollection, arg.ID, arg.UserID)
+	var i BookmarkCollection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteComment = `-- name: DeleteComment :one
+UPDATE comments SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at
+`
+
+type DeleteCommentParams struct {
+	ID        int64       `json:"id"`
+	DeletedBy pgtype.Int8 `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteComment(ctx context.Context, arg DeleteCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, deleteComment, arg.ID, arg.DeletedBy)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const deleteOrphanedAttachment = `-- name: DeleteOrphanedAttachment :execrows
+DELETE FROM attachments a
+WHERE a.id = $1 AND (
+    a.target_id IS NULL
+    OR (a.target_type = 'post' AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = a.target_id))
+    OR (a.target_type = 'comment' AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.id = a.target_id))
+)
+`
+
+// checks again that the upload is still orphaned in case it was attached since it was listed
+func (q *Queries) DeleteOrphanedAttachment(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOrphanedAttachment, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deletePost = `-- name: DeletePost :one
+UPDATE posts SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
+`
+
+type DeletePostParams struct {
+	ID        int64       `json:"id"`
+	DeletedBy pgtype.Int8 `json:"deleted_by"`
+}
+
+func (q *Queries) DeletePost(ctx context.Context, arg DeletePostParams) (Post, error) {
+	row := q.db.QueryRow(ctx, deletePost, arg.ID, arg.DeletedBy)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const deletePostTags = `-- name: DeletePostTags :exec
+DELETE FROM post_tags WHERE post_id = $1
+`
+
+func (q *Queries) DeletePostTags(ctx context.Context, postID int64) error {
+	_, err := q.db.Exec(ctx, deletePostTags, postID)
+	return err
+}
+
+const deleteTopic = `-- name: DeleteTopic :one
+UPDATE topics SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version
+`
+
+type DeleteTopicParams struct {
+	ID        int64       `json:"id"`
+	DeletedBy pgtype.Int8 `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteTopic(ctx context.Context, arg DeleteTopicParams) (Topic, error) {
+	row := q.db.QueryRow(ctx, deleteTopic, arg.ID, arg.DeletedBy)
+	var i Topic
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.UserID,
+		&i.Username,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PostCount,
+		&i.LastPostAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const deleteTopicMute = `-- name: DeleteTopicMute :one
+DELETE FROM topic_mutes WHERE topic_id = $1 AND user_id = $2 RETURNING topic_id, user_id, reason, muted_by, expires_at, created_at
+`
+
+type DeleteTopicMuteParams struct {
+	TopicID int64 `json:"topic_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteTopicMute(ctx context.Context, arg DeleteTopicMuteParams) (TopicMute, error) {
+	row := q.db.QueryRow(ctx, deleteTopicMute, arg.TopicID, arg.UserID)
+	var i TopicMute
+	err := row.Scan(
+		&i.TopicID,
+		&i.UserID,
+		&i.Reason,
+		&i.MutedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteTopicSubscription = `-- name: DeleteTopicSubscription :one
+DELETE FROM topic_subscriptions WHERE user_id = $1 AND topic_id = $2 RETURNING user_id, topic_id, created_at
+`
+
+type DeleteTopicSubscriptionParams struct {
+	UserID  int64 `json:"user_id"`
+	TopicID int64 `json:"topic_id"`
+}
+
+func (q *Queries) DeleteTopicSubscription(ctx context.Context, arg DeleteTopicSubscriptionParams) (TopicSubscription, error) {
+	row := q.db.QueryRow(ctx, deleteTopicSubscription, arg.UserID, arg.TopicID)
+	var i TopicSubscription
+	err := row.Scan(&i.UserID, &i.TopicID, &i.CreatedAt)
+	return i, err
+}
+
+const deleteUserFollow = `-- name: DeleteUserFollow :one
+DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2 RETURNING follower_id, followee_id, created_at
+`
+
+type DeleteUserFollowParams struct {
+	FollowerID int64 `json:"follower_id"`
+	FolloweeID int64 `json:"followee_id"`
+}
+
+func (q *Queries) DeleteUserFollow(ctx context.Context, arg DeleteUserFollowParams) (UserFollow, error) {
+	row := q.db.QueryRow(ctx, deleteUserFollow, arg.FollowerID, arg.FolloweeID)
+	var i UserFollow
+	err := row.Scan(&i.FollowerID, &i.FolloweeID, &i.CreatedAt)
+	return i, err
+}
+
+const exportBookmarkCollections = `-- name: ExportBookmarkCollections :many
+SELECT id, user_id, name, created_at FROM bookmark_collections
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportBookmarkCollectionsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportBookmarkCollections(ctx context.Context, arg ExportBookmarkCollectionsParams) ([]BookmarkCollection, error) {
+	rows, err := q.db.Query(ctx, exportBookmarkCollections, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookmarkCollection
+	for rows.Next() {
+		var i BookmarkCollection
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportBookmarks = `-- name: ExportBookmarks :many
+SELECT id, user_id, target_type, target_id, collection_id, created_at FROM bookmarks
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportBookmarksParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportBookmarks(ctx context.Context, arg ExportBookmarksParams) ([]Bookmark, error) {
+	rows, err := q.db.Query(ctx, exportBookmarks, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Bookmark
+	for rows.Next() {
+		var i Bookmark
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.CollectionID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportComments = `-- name: ExportComments :many
+SELECT id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at FROM comments
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportCommentsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportComments(ctx context.Context, arg ExportCommentsParams) ([]Comment, error) {
+	rows, err := q.db.Query(ctx, exportComments, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Version,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportPollBallots = `-- name: ExportPollBallots :many
+SELECT
+    b.user_id, b.created_at, b.updated_at,
+    ARRAY(SELECT v.option_id FROM poll_votes v WHERE v.poll_id = b.poll_id AND v.user_id = b.user_id ORDER BY v.option_id)::bigint[] AS option_ids
+FROM poll_ballots b
+WHERE b.poll_id = $1
+ORDER BY b.user_id
+`
+
+type ExportPollBallotsRow struct {
+	UserID    int64            `json:"user_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	OptionIds []int64          `json:"option_ids"`
+}
+
+func (q *Queries) ExportPollBallots(ctx context.Context, pollID int64) ([]ExportPollBallotsRow, error) {
+	rows, err := q.db.Query(ctx, exportPollBallots, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportPollBallotsRow
+	for rows.Next() {
+		var i ExportPollBallotsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OptionIds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportPolls = `-- name: ExportPolls :many
+SELECT id, post_id, question, multiple_choice, hide_results, closes_at, created_at FROM polls
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportPollsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportPolls(ctx context.Context, arg ExportPollsParams) ([]Poll, error) {
+	rows, err := q.db.Query(ctx, exportPolls, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Poll
+	for rows.Next() {
+		var i Poll
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.Question,
+			&i.MultipleChoice,
+			&i.HideResults,
+			&i.ClosesAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportPosts = `-- name: ExportPosts :many
+SELECT
+    p.id, p.title, p.content, p.user_id, p.username, p.topic_id, p.created_at, p.content_html, p.updated_at, p.edit_count, p.deleted_at, p.deleted_by, p.status, p.flag_reason, p.content_hash, p.pinned, p.locked, p.archived_at, p.comment_count, p.last_activity_at, p.version, p.published_at,
+    ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name)::text[] AS tags
+FROM posts p
+WHERE p.id > $1
+ORDER BY p.id
+LIMIT $2
+`
+
+type ExportPostsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+type ExportPostsRow struct {
+	ID             int64            `json:"id"`
+	Title          string           `json:"title"`
+	Content        string           `json:"content"`
+	UserID         int64            `json:"user_id"`
+	Username       string           `json:"username"`
+	TopicID        int64            `json:"topic_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	ContentHtml    string           `json:"content_html"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	EditCount      int32            `json:"edit_count"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy      pgtype.Int8      `json:"deleted_by"`
+	Status         string           `json:"status"`
+	FlagReason     string           `json:"flag_reason"`
+	ContentHash    string           `json:"content_hash"`
+	Pinned         bool             `json:"pinned"`
+	Locked         bool             `json:"locked"`
+	ArchivedAt     pgtype.Timestamp `json:"archived_at"`
+	CommentCount   int32            `json:"comment_count"`
+	LastActivityAt pgtype.Timestamp `json:"last_activity_at"`
+	Version        int32            `json:"version"`
+	PublishedAt    pgtype.Timestamp `json:"published_at"`
+	Tags           []string         `json:"tags"`
+}
+
+func (q *Queries) ExportPosts(ctx context.Context, arg ExportPostsParams) ([]ExportPostsRow, error) {
+	rows, err := q.db.Query(ctx, exportPosts, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportPostsRow
+	for rows.Next() {
+		var i ExportPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.TopicID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Pinned,
+			&i.Locked,
+			&i.ArchivedAt,
+			&i.CommentCount,
+			&i.LastActivityAt,
+			&i.Version,
+			&i.PublishedAt,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportRevisions = `-- name: ExportRevisions :many
+SELECT id, target_type, target_id, revision, title, content, edited_by, created_at, status FROM revisions
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportRevisionsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportRevisions(ctx context.Context, arg ExportRevisionsParams) ([]Revision, error) {
+	rows, err := q.db.Query(ctx, exportRevisions, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Revision
+	for rows.Next() {
+		var i Revision
+		if err := rows.Scan(
+			&i.ID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Revision,
+			&i.Title,
+			&i.Content,
+			&i.EditedBy,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportTags = `-- name: ExportTags :many
+SELECT id, name, topic_id, created_at FROM tags
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportTagsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportTags(ctx context.Context, arg ExportTagsParams) ([]Tag, error) {
+	rows, err := q.db.Query(ctx, exportTags, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TopicID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportTopicSubscriptions = `-- name: ExportTopicSubscriptions :many
+SELECT user_id, topic_id, created_at FROM topic_subscriptions
+WHERE (user_id, topic_id) > ($1::BIGINT, $2::BIGINT)
+ORDER BY user_id, topic_id
+LIMIT $3
+`
+
+type ExportTopicSubscriptionsParams struct {
+	AfterUserID  int64 `json:"after_user_id"`
+	AfterTopicID int64 `json:"after_topic_id"`
+	RowLimit     int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportTopicSubscriptions(ctx context.Context, arg ExportTopicSubscriptionsParams) ([]TopicSubscription, error) {
+	rows, err := q.db.Query(ctx, exportTopicSubscriptions, arg.AfterUserID, arg.AfterTopicID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TopicSubscription
+	for rows.Next() {
+		var i TopicSubscription
+		if err := rows.Scan(&i.UserID, &i.TopicID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportTopics = `-- name: ExportTopics :many
+SELECT id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version FROM topics
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportTopicsParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportTopics(ctx context.Context, arg ExportTopicsParams) ([]Topic, error) {
+	rows, err := q.db.Query(ctx, exportTopics, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Topic
+	for rows.Next() {
+		var i Topic
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.UserID,
+			&i.Username,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PostCount,
+			&i.LastPostAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUserFollows = `-- name: ExportUserFollows :many
+SELECT follower_id, followee_id, created_at FROM user_follows
+WHERE (follower_id, followee_id) > ($1::BIGINT, $2::BIGINT)
+ORDER BY follower_id, followee_id
+LIMIT $3
+`
+
+type ExportUserFollowsParams struct {
+	AfterFollowerID int64 `json:"after_follower_id"`
+	AfterFolloweeID int64 `json:"after_followee_id"`
+	RowLimit        int32 `json:"row_limit"`
+}
+
+func (q *Queries) ExportUserFollows(ctx context.Context, arg ExportUserFollowsParams) ([]UserFollow, error) {
+	rows, err := q.db.Query(ctx, exportUserFollows, arg.AfterFollowerID, arg.AfterFolloweeID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserFollow
+	for rows.Next() {
+		var i UserFollow
+		if err := rows.Scan(&i.FollowerID, &i.FolloweeID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportUsers = `-- name: ExportUsers :many
+SELECT
+    id, username, role, created_at,
+    (CASE WHEN $1::BOOLEAN THEN password ELSE '' END)::TEXT AS password
+FROM users
+WHERE id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ExportUsersParams struct {
+	IncludePasswords bool  `json:"include_passwords"`
+	AfterID          int64 `json:"after_id"`
+	RowLimit         int32 `json:"row_limit"`
+}
+
+type ExportUsersRow struct {
+	ID        int64            `json:"id"`
+	Username  string           `json:"username"`
+	Role      string           `json:"role"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	Password  string           `json:"password"`
+}
+
+// password hashes are only selected when asked for
+func (q *Queries) ExportUsers(ctx context.Context, arg ExportUsersParams) ([]ExportUsersRow, error) {
+	rows, err := q.db.Query(ctx, exportUsers, arg.IncludePasswords, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportUsersRow
+	for rows.Next() {
+		var i ExportUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Password,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const failAttachmentProcessing = `-- name: FailAttachmentProcessing :exec
+UPDATE attachments SET status = 'failed', processing_error = $2 WHERE id = $1 AND status = 'processing'
+`
+
+type FailAttachmentProcessingParams struct {
+	ID              int64  `json:"id"`
+	ProcessingError string `json:"processing_error"`
+}
+
+func (q *Queries) FailAttachmentProcessing(ctx context.Context, arg FailAttachmentProcessingParams) error {
+	_, err := q.db.Exec(ctx, failAttachmentProcessing, arg.ID, arg.ProcessingError)
+	return err
+}
+
+const fetchUserByID = `-- name: FetchUserByID :one
+SELECT id, username, password, created_at, role FROM users WHERE id = $1
+`
+
+func (q *Queries) FetchUserByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, fetchUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const fetchUserByUsername = `-- name: FetchUserByUsername :one
+SELECT id, username, password, created_at, role FROM users WHERE username = $1
+`
+
+func (q *Queries) FetchUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, fetchUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const finishAttachmentProcessing = `-- name: FinishAttachmentProcessing :one
+UPDATE attachments SET status = 'ready', content_type = $2, size_bytes = $3, width = $4, height = $5
+WHERE id = $1 AND status = 'processing'
+RETURNING id, storage_key, user_id, filename, content_type, size_bytes, target_type, target_id, created_at, attached_at, status, width, height, processing_error, processing_attempts, processing_claimed_at
+`
+
+type FinishAttachmentProcessingParams struct {
+	ID          int64       `json:"id"`
+	ContentType string      `json:"content_type"`
+	SizeBytes   int64       `json:"size_bytes"`
+	Width       pgtype.Int4 `json:"width"`
+	Height      pgtype.Int4 `json:"height"`
+}
+
+func (q *Queries) FinishAttachmentProcessing(ctx context.Context, arg FinishAttachmentProcessingParams) (Attachment, error) {
+	row := q.db.QueryRow(ctx, finishAttachmentProcessing,
+		arg.ID,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+	)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.StorageKey,
+		&i.UserID,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CreatedAt,
+		&i.AttachedAt,
+		&i.Status,
+		&i.Width,
+		&i.Height,
+		&i.ProcessingError,
+		&i.ProcessingAttempts,
+		&i.ProcessingClaimedAt,
+	)
+	return i, err
+}
+
+const getActiveSanction = `-- name: GetActiveSanction :one
+SELECT id, user_id, kind, reason, issued_by, expires_at, revoked_at, revoked_by, created_at FROM user_sanctions
+WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())
+ORDER BY kind = 'ban' DESC, expires_at DESC NULLS FIRST
+LIMIT 1
+`
+
+func (q *Queries) GetActiveSanction(ctx context.Context, userID int64) (UserSanction, error) {
+	row := q.db.QueryRow(ctx, getActiveSanction, userID)
+	var i UserSanction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.Reason,
+		&i.IssuedBy,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.RevokedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAttachment = `-- name: GetAttachment :one
+SELECT id, storage_key, user_id, filename, content_type, size_bytes, target_type, target_id, created_at, attached_at, status, width, height, processing_error, processing_attempts, processing_claimed_at FROM attachments WHERE id = $1
+`
+
+func (q *Queries) GetAttachment(ctx context.Context, id int64) (Attachment, error) {
+	row := q.db.QueryRow(ctx, getAttachment, id)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.StorageKey,
+		&i.UserID,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CreatedAt,
+		&i.AttachedAt,
+		&i.Status,
+		&i.Width,
+		&i.Height,
+		&i.ProcessingError,
+		&i.ProcessingAttempts,
+		&i.ProcessingClaimedAt,
+	)
+	return i, err
+}
+
+const getAttachmentVariant = `-- name: GetAttachmentVariant :one
+SELECT attachment_id, name, storage_key, content_type, size_bytes, width, height FROM attachment_variants WHERE attachment_id = $1 AND name = $2
+`
+
+type GetAttachmentVariantParams struct {
+	AttachmentID int64  `json:"attachment_id"`
+	Name         string `json:"name"`
+}
+
+func (q *Queries) GetAttachmentVariant(ctx context.Context, arg GetAttachmentVariantParams) (AttachmentVariant, error) {
+	row := q.db.QueryRow(ctx, getAttachmentVariant, arg.AttachmentID, arg.Name)
+	var i AttachmentVariant
+	err := row.Scan(
+		&i.AttachmentID,
+		&i.Name,
+		&i.StorageKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+	)
+	return i, err
+}
+
+const getBookmarkCollection = `-- name: GetBookmarkCollection :one
+SELECT id, user_id, name, created_at FROM bookmark_collections WHERE id = $1 AND user_id = $2
+`
+
+type GetBookmarkCollectionParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetBookmarkCollection(ctx context.Context, arg GetBookmarkCollectionParams) (BookmarkCollection, error) {
+	row := q.db.QueryRow(ctx, getBookmarkCollection, arg.ID, arg.UserID)
+	var i BookmarkCollection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getComment = `-- name: GetComment :one
+SELECT id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at FROM comments WHERE id = $1
+`
+
+func (q *Queries) GetComment(ctx context.Context, id int64) (Comment, error) {
+	row := q.db.QueryRow(ctx, getComment, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const getCommentForUpdate = `-- name: GetCommentForUpdate :one
+SELECT id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at FROM comments WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+`
+
+func (q *Queries) GetCommentForUpdate(ctx context.Context, id int64) (Comment, error) {
+	row := q.db.QueryRow(ctx, getCommentForUpdate, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const getImportMapping = `-- name: GetImportMapping :one
+SELECT new_id FROM import_mappings WHERE source = $1 AND kind = $2 AND old_id = $3
+`
+
+type GetImportMappingParams struct {
+	Source string `json:"source"`
+	Kind   string `json:"kind"`
+	OldID  int64  `json:"old_id"`
+}
+
+func (q *Queries) GetImportMapping(ctx context.Context, arg GetImportMappingParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getImportMapping, arg.Source, arg.Kind, arg.OldID)
+	var newID int64
+	err := row.Scan(&newID)
+	return newID, err
+}
+
+const getLatestPublishedRevision = `-- name: GetLatestPublishedRevision :one
+SELECT id, target_type, target_id, revision, title, content, edited_by, created_at, status FROM revisions WHERE target_type = $1 AND target_id = $2 AND status = 'published' ORDER BY revision DESC LIMIT 1
+`
+
+type GetLatestPublishedRevisionParams struct {
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+}
+
+func (q *Queries) GetLatestPublishedRevision(ctx context.Context, arg GetLatestPublishedRevisionParams) (Revision, error) {
+	row := q.db.QueryRow(ctx, getLatestPublishedRevision, arg.TargetType, arg.TargetID)
+	var i Revision
+	err := row.Scan(
+		&i.ID,
+		&i.TargetType,
+		&i.TargetID,
+		&i.Revision,
+		&i.Title,
+		&i.Content,
+		&i.EditedBy,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getPoll = `-- name: GetPoll :one
+SELECT id, post_id, question, multiple_choice, hide_results, closes_at, created_at FROM polls WHERE id = $1
+`
+
+func (q *Queries) GetPoll(ctx context.Context, id int64) (Poll, error) {
+	row := q.db.QueryRow(ctx, getPoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.Question,
+		&i.MultipleChoice,
+		&i.HideResults,
+		&i.ClosesAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPollByPostID = `-- name: GetPollByPostID :one
+SELECT id, post_id, question, multiple_choice, hide_results, closes_at, created_at FROM polls WHERE post_id = $1
+`
+
+func (q *Queries) GetPollByPostID(ctx context.Context, postID int64) (Poll, error) {
+	row := q.db.QueryRow(ctx, getPollByPostID, postID)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.Question,
+		&i.MultipleChoice,
+		&i.HideResults,
+		&i.ClosesAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at FROM posts WHERE id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id int64) (Post, error) {
+	row := q.db.QueryRow(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const getPostCommentsVersion = `-- name: GetPostCommentsVersion :one
+SELECT
+    COUNT(*) FILTER (WHERE c.deleted_at IS NULL AND c.status = 'published')::bigint AS comment_count,
+    (
+        SELECT COUNT(*) FROM bookmarks b JOIN comments bc ON bc.id = b.target_id
+        WHERE b.user_id = $1 AND b.target_type = 'comment' AND bc.post_id = $2
+    )::bigint AS saved_count,
+    GREATEST(
+        MAX(c.created_at), MAX(c.updated_at), MAX(c.deleted_at),
+        (
+            SELECT MAX(b.created_at) FROM bookmarks b JOIN comments bc ON bc.id = b.target_id
+            WHERE b.user_id = $1 AND b.target_type = 'comment' AND bc.post_id = $2
+        )
+    )::timestamp AS last_modified
+FROM comments c
+WHERE c.post_id = $2
+`
+
+type GetPostCommentsVersionParams struct {
+	UserID int64 `json:"user_id"`
+	PostID int64 `json:"post_id"`
+}
+
+type GetPostCommentsVersionRow struct {
+	CommentCount int64            `json:"comment_count"`
+	SavedCount   int64            `json:"saved_count"`
+	LastModified pgtype.Timestamp `json:"last_modified"`
+}
+
+func (q *Queries) GetPostCommentsVersion(ctx context.Context, arg GetPostCommentsVersionParams) (GetPostCommentsVersionRow, error) {
+	row := q.db.QueryRow(ctx, getPostCommentsVersion, arg.UserID, arg.PostID)
+	var i GetPostCommentsVersionRow
+	err := row.Scan(&i.CommentCount, &i.SavedCount, &i.LastModified)
+	return i, err
+}
+
+const getPostDetail = `-- name: GetPostDetail :one
+SELECT
+    p.id, p.title, p.content, p.user_id, p.username, p.topic_id, p.created_at, p.content_html, p.updated_at, p.edit_count, p.deleted_at, p.deleted_by, p.status, p.flag_reason, p.content_hash, p.pinned, p.locked, p.archived_at, p.comment_count, p.last_activity_at, p.version, p.published_at,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = $1 AND b.target_type = 'post' AND b.target_id = p.id) AS saved,
+    ARRAY(SELECT tg.name FROM post_tags pt JOIN tags tg ON tg.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY tg.name)::text[] AS tags,
+    EXISTS(SELECT 1 FROM polls pl WHERE pl.post_id = p.id) AS has_poll,
+    t.name AS topic_name,
+    t.description AS topic_description,
+    t.post_count AS topic_post_count,
+    u.role AS author_role,
+    u.created_at AS author_created_at,
+    (SELECT COUNT(*) FROM posts ap WHERE ap.user_id = u.id AND ap.deleted_at IS NULL AND ap.status = 'published')::bigint AS author_post_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.followee_id = u.id)::bigint AS author_follower_count,
+    EXISTS(SELECT 1 FROM user_follows f WHERE f.follower_id = $1 AND f.followee_id = u.id) AS author_followed_by_viewer
+FROM posts p
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+JOIN users u ON u.id = p.user_id
+WHERE p.id = $2 AND p.deleted_at IS NULL AND p.status = 'published'
+`
+
+type GetPostDetailParams struct {
+	UserID int64 `json:"user_id"`
+	ID     int64 `json:"id"`
+}
+
+type GetPostDetailRow struct {
+	ID                     int64            `json:"id"`
+	Title                  string           `json:"title"`
+	Content                string           `json:"content"`
+	UserID                 int64            `json:"user_id"`
+	Username               string           `json:"username"`
+	TopicID                int64            `json:"topic_id"`
+	CreatedAt              pgtype.Timestamp `json:"created_at"`
+	ContentHtml            string           `json:"content_html"`
+	UpdatedAt              pgtype.Timestamp `json:"updated_at"`
+	EditCount              int32            `json:"edit_count"`
+	DeletedAt              pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy              pgtype.Int8      `json:"deleted_by"`
+	Status                 string           `json:"status"`
+	FlagReason             string           `json:"flag_reason"`
+	ContentHash            string           `json:"content_hash"`
+	Pinned                 bool             `json:"pinned"`
+	Locked                 bool             `json:"locked"`
+	ArchivedAt             pgtype.Timestamp `json:"archived_at"`
+	CommentCount           int32            `json:"comment_count"`
+	LastActivityAt         pgtype.Timestamp `json:"last_activity_at"`
+	Version                int32            `json:"version"`
+	PublishedAt            pgtype.Timestamp `json:"published_at"`
+	Saved                  bool             `json:"saved"`
+	Tags                   []string         `json:"tags"`
+	HasPoll                bool             `json:"has_poll"`
+	TopicName              string           `json:"topic_name"`
+	TopicDescription       string           `json:"topic_description"`
+	TopicPostCount         int32            `json:"topic_post_count"`
+	AuthorRole             string           `json:"author_role"`
+	AuthorCreatedAt        pgtype.Timestamp `json:"author_created_at"`
+	AuthorPostCount        int64            `json:"author_post_count"`
+	AuthorFollowerCount    int64            `json:"author_follower_count"`
+	AuthorFollowedByViewer bool             `json:"author_followed_by_viewer"`
+}
+
+// everything the post page shows apart from the comments, hidden posts and posts in deleted topics are left out
+func (q *Queries) GetPostDetail(ctx context.Context, arg GetPostDetailParams) (GetPostDetailRow, error) {
+	row := q.db.QueryRow(ctx, getPostDetail, arg.UserID, arg.ID)
+	var i GetPostDetailRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+		&i.Saved,
+		&i.Tags,
+		&i.HasPoll,
+		&i.TopicName,
+		&i.TopicDescription,
+		&i.TopicPostCount,
+		&i.AuthorRole,
+		&i.AuthorCreatedAt,
+		&i.AuthorPostCount,
+		&i.AuthorFollowerCount,
+		&i.AuthorFollowedByViewer,
+	)
+	return i, err
+}
+
+const getPostForUpdate = `-- name: GetPostForUpdate :one
+SELECT id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+`
+
+func (q *Queries) GetPostForUpdate(ctx context.Context, id int64) (Post, error) {
+	row := q.db.QueryRow(ctx, getPostForUpdate, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const getPostVersion = `-- name: GetPostVersion :one
+SELECT
+    p.version,
+    p.comment_count::bigint AS comment_count,
+    (p.pinned::int + 2 * p.locked::int + 4 * (p.archived_at IS NOT NULL)::int)::bigint AS state,
+    t.post_count::bigint AS topic_post_count,
+    (CASE u.role WHEN 'admin' THEN 2 WHEN 'moderator' THEN 1 ELSE 0 END)::bigint AS author_role,
+    (SELECT COUNT(*) FROM posts ap WHERE ap.user_id = u.id AND ap.deleted_at IS NULL AND ap.status = 'published')::bigint AS author_post_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.followee_id = u.id)::bigint AS author_follower_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.follower_id = $1 AND f.followee_id = u.id)::bigint AS author_followed_by_viewer,
+    (
+        SELECT COUNT(*) FROM bookmarks b
+        WHERE b.user_id = $1
+          AND ((b.target_type = 'post' AND b.target_id = p.id)
+            OR (b.target_type = 'comment' AND b.target_id IN (SELECT c.id FROM comments c WHERE c.post_id = p.id)))
+    )::bigint AS saved_count,
+    GREATEST(
+        p.created_at, p.updated_at, p.last_activity_at, p.archived_at, t.updated_at,
+        (SELECT MAX(GREATEST(c.created_at, c.updated_at, c.deleted_at)) FROM comments c WHERE c.post_id = p.id),
+        (
+            SELECT MAX(b.created_at) FROM bookmarks b
+            WHERE b.user_id = $1
+              AND ((b.target_type = 'post' AND b.target_id = p.id)
+                OR (b.target_type = 'comment' AND b.target_id IN (SELECT c.id FROM comments c WHERE c.post_id = p.id)))
+        )
+    )::timestamp AS last_modified
+FROM posts p
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+JOIN users u ON u.id = p.user_id
+WHERE p.id = $2 AND p.deleted_at IS NULL AND p.status = 'published'
+`
+
+type GetPostVersionParams struct {
+	UserID int64 `json:"user_id"`
+	ID     int64 `json:"id"`
+}
+
+type GetPostVersionRow struct {
+	Version                int32            `json:"version"`
+	CommentCount           int64            `json:"comment_count"`
+	State                  int64            `json:"state"`
+	TopicPostCount         int64            `json:"topic_post_count"`
+	AuthorRole             int64            `json:"author_role"`
+	AuthorPostCount        int64            `json:"author_post_count"`
+	AuthorFollowerCount    int64            `json:"author_follower_count"`
+	AuthorFollowedByViewer int64            `json:"author_followed_by_viewer"`
+	SavedCount             int64            `json:"saved_count"`
+	LastModified           pgtype.Timestamp `json:"last_modified"`
+}
+
+// what the post page shows changes whenever one of these does, a hidden post has no row just like in GetPostDetail
+func (q *Queries) GetPostVersion(ctx context.Context, arg GetPostVersionParams) (GetPostVersionRow, error) {
+	row := q.db.QueryRow(ctx, getPostVersion, arg.UserID, arg.ID)
+	var i GetPostVersionRow
+	err := row.Scan(
+		&i.Version,
+		&i.CommentCount,
+		&i.State,
+		&i.TopicPostCount,
+		&i.AuthorRole,
+		&i.AuthorPostCount,
+		&i.AuthorFollowerCount,
+		&i.AuthorFollowedByViewer,
+		&i.SavedCount,
+		&i.LastModified,
+	)
+	return i, err
+}
+
+const getRevision = `-- name: GetRevision :one
+SELECT id, target_type, target_id, revision, title, content, edited_by, created_at, status FROM revisions WHERE target_type = $1 AND target_id = $2 AND revision = $3
+`
+
+type GetRevisionParams struct {
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	Revision   int32  `json:"revision"`
+}
+
+func (q *Queries) GetRevision(ctx context.Context, arg GetRevisionParams) (Revision, error) {
+	row := q.db.QueryRow(ctx, getRevision, arg.TargetType, arg.TargetID, arg.Revision)
+	var i Revision
+	err := row.Scan(
+		&i.ID,
+		&i.TargetType,
+		&i.TargetID,
+		&i.Revision,
+		&i.Title,
+		&i.Content,
+		&i.EditedBy,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getSanctionForUpdate = `-- name: GetSanctionForUpdate :one
+SELECT id, user_id, kind, reason, issued_by, expires_at, revoked_at, revoked_by, created_at FROM user_sanctions WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetSanctionForUpdate(ctx context.Context, id int64) (UserSanction, error) {
+	row := q.db.QueryRow(ctx, getSanctionForUpdate, id)
+	var i UserSanction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.Reason,
+		&i.IssuedBy,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.RevokedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTopic = `-- name: GetTopic :one
+SELECT id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version FROM topics WHERE id = $1
+`
+
+func (q *Queries) GetTopic(ctx context.Context, id int64) (Topic, error) {
+	row := q.db.QueryRow(ctx, getTopic, id)
+	var i Topic
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.UserID,
+		&i.Username,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PostCount,
+		&i.LastPostAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getTopicForUpdate = `-- name: GetTopicForUpdate :one
+SELECT id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version FROM topics WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+`
+
+func (q *Queries) GetTopicForUpdate(ctx context.Context, id int64) (Topic, error) {
+	row := q.db.QueryRow(ctx, getTopicForUpdate, id)
+	var i Topic
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.UserID,
+		&i.Username,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PostCount,
+		&i.LastPostAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getTopicPostsVersion = `-- name: GetTopicPostsVersion :one
+SELECT
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published')::bigint AS post_count,
+    COALESCE(SUM(p.comment_count) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published'), 0)::bigint AS comment_count,
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published' AND p.pinned)::bigint AS pinned_count,
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published' AND p.locked)::bigint AS locked_count,
+    COUNT(*) FILTER (WHERE p.deleted_at IS NULL AND p.status = 'published' AND p.archived_at IS NOT NULL)::bigint AS archived_count,
+    (
+        SELECT COUNT(*) FROM bookmarks b JOIN posts bp ON bp.id = b.target_id
+        WHERE b.user_id = $1 AND b.target_type = 'post' AND bp.topic_id = $2
+    )::bigint AS saved_count,
+    GREATEST(
+        MAX(p.created_at), MAX(p.updated_at), MAX(p.deleted_at), MAX(p.last_activity_at), MAX(p.archived_at),
+        (
+            SELECT MAX(b.created_at) FROM bookmarks b JOIN posts bp ON bp.id = b.target_id
+            WHERE b.user_id = $1 AND b.target_type = 'post' AND bp.topic_id = $2
+        )
+    )::timestamp AS last_modified
+FROM posts p
+WHERE p.topic_id = $2
+`
+
+type GetTopicPostsVersionParams struct {
+	UserID  int64 `json:"user_id"`
+	TopicID int64 `json:"topic_id"`
+}
+
+type GetTopicPostsVersionRow struct {
+	PostCount     int64            `json:"post_count"`
+	CommentCount  int64            `json:"comment_count"`
+	PinnedCount   int64            `json:"pinned_count"`
+	LockedCount   int64            `json:"locked_count"`
+	ArchivedCount int64            `json:"archived_count"`
+	SavedCount    int64            `json:"saved_count"`
+	LastModified  pgtype.Timestamp `json:"last_modified"`
+}
+
+func (q *Queries) GetTopicPostsVersion(ctx context.Context, arg GetTopicPostsVersionParams) (GetTopicPostsVersionRow, error) {
+	row := q.db.QueryRow(ctx, getTopicPostsVersion, arg.UserID, arg.TopicID)
+	var i GetTopicPostsVersionRow
+	err := row.Scan(
+		&i.PostCount,
+		&i.CommentCount,
+		&i.PinnedCount,
+		&i.LockedCount,
+		&i.ArchivedCount,
+		&i.SavedCount,
+		&i.LastModified,
+	)
+	return i, err
+}
+
+const getTopicsVersion = `-- name: GetTopicsVersion :one
+SELECT
+    COUNT(*) FILTER (WHERE t.deleted_at IS NULL)::bigint AS topic_count,
+    COALESCE(SUM(t.post_count) FILTER (WHERE t.deleted_at IS NULL), 0)::bigint AS post_count,
+    (SELECT COUNT(*) FROM topic_subscriptions s WHERE s.user_id = $1)::bigint AS subscription_count,
+    GREATEST(
+        MAX(t.created_at), MAX(t.updated_at), MAX(t.deleted_at), MAX(t.last_post_at),
+        (SELECT MAX(s.created_at) FROM topic_subscriptions s WHERE s.user_id = $1),
+        (SELECT MAX(v.last_visited_at) FROM topic_visits v WHERE v.user_id = $1)
+    )::timestamp AS last_modified
+FROM topics t
+`
+
+type GetTopicsVersionRow struct {
+	TopicCount        int64            `json:"topic_count"`
+	PostCount         int64            `json:"post_count"`
+	SubscriptionCount int64            `json:"subscription_count"`
+	LastModified      pgtype.Timestamp `json:"last_modified"`
+}
+
+// the listing changes whenever one of these does, timestamps of deleted topics count so deleting one moves them
+func (q *Queries) GetTopicsVersion(ctx context.Context, userID int64) (GetTopicsVersionRow, error) {
+	row := q.db.QueryRow(ctx, getTopicsVersion, userID)
+	var i GetTopicsVersionRow
+	err := row.Scan(
+		&i.TopicCount,
+		&i.PostCount,
+		&i.SubscriptionCount,
+		&i.LastModified,
+	)
+	return i, err
+}
+
+const getUserProfile = `-- name: GetUserProfile :one
+SELECT
+    u.id,
+    u.username,
+    u.role,
+    u.created_at,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.followee_id = u.id)::bigint AS follower_count,
+    (SELECT COUNT(*) FROM user_follows f WHERE f.follower_id = u.id)::bigint AS following_count,
+    EXISTS(SELECT 1 FROM user_follows f WHERE f.follower_id = $1 AND f.followee_id = u.id) AS followed_by_viewer
+FROM users u
+WHERE u.username = $2
+`
+
+type GetUserProfileParams struct {
+	ViewerID int64  `json:"viewer_id"`
+	Username string `json:"username"`
+}
+
+type GetUserProfileRow struct {
+	ID               int64            `json:"id"`
+	Username         string           `json:"username"`
+	Role             string           `json:"role"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	FollowerCount    int64            `json:"follower_count"`
+	FollowingCount   int64            `json:"following_count"`
+	FollowedByViewer bool             `json:"followed_by_viewer"`
+}
+
+func (q *Queries) GetUserProfile(ctx context.Context, arg GetUserProfileParams) (GetUserProfileRow, error) {
+	row := q.db.QueryRow(ctx, getUserProfile, arg.ViewerID, arg.Username)
+	var i GetUserProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Role,
+		&i.CreatedAt,
+		&i.FollowerCount,
+		&i.FollowingCount,
+		&i.FollowedByViewer,
+	)
+	return i, err
+}
+
+const importBookmark = `-- name: ImportBookmark :execrows
+INSERT INTO bookmarks (user_id, target_type, target_id, collection_id, created_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING
+`
+
+type ImportBookmarkParams struct {
+	UserID       int64            `json:"user_id"`
+	TargetType   string           `json:"target_type"`
+	TargetID     int64            `json:"target_id"`
+	CollectionID pgtype.Int8      `json:"collection_id"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+func (q *Queries) ImportBookmark(ctx context.Context, arg ImportBookmarkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, importBookmark,
+		arg.UserID,
+		arg.TargetType,
+		arg.TargetID,
+		arg.CollectionID,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const importBookmarkCollection = `-- name: ImportBookmarkCollection :one
+INSERT INTO bookmark_collections (user_id, name, created_at) VALUES ($1, $2, $3)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, user_id, name, created_at
+`
+
+type ImportBookmarkCollectionParams struct {
+	UserID    int64            `json:"user_id"`
+	Name      string           `json:"name"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// an existing collection with the same name is reused as is
+func (q *Queries) ImportBookmarkCollection(ctx context.Context, arg ImportBookmarkCollectionParams) (BookmarkCollection, error) {
+	row := q.db.QueryRow(ctx, importBookmarkCollection, arg.UserID, arg.Name, arg.CreatedAt)
+	var i BookmarkCollection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const importComment = `-- name: ImportComment :one
+INSERT INTO comments (content, content_html, user_id, username, post_id, created_at, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, CASE WHEN $11 = 'published' THEN $6 END)
+RETURNING id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at
+`
+
+type ImportCommentParams struct {
+	Content     string           `json:"content"`
+	ContentHtml string           `json:"content_html"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	PostID      int64            `json:"post_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	EditCount   int32            `json:"edit_count"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	Status      string           `json:"status"`
+	FlagReason  string           `json:"flag_reason"`
+	ContentHash string           `json:"content_hash"`
+}
+
+func (q *Queries) ImportComment(ctx context.Context, arg ImportCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, importComment,
+		arg.Content,
+		arg.ContentHtml,
+		arg.UserID,
+		arg.Username,
+		arg.PostID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.EditCount,
+		arg.DeletedAt,
+		arg.DeletedBy,
+		arg.Status,
+		arg.FlagReason,
+		arg.ContentHash,
+	)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const importPoll = `-- name: ImportPoll :one
+INSERT INTO polls (post_id, question, multiple_choice, hide_results, closes_at, created_at) VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (post_id) DO NOTHING
+RETURNING id, post_id, question, multiple_choice, hide_results, closes_at, created_at
+`
+
+type ImportPollParams struct {
+	PostID         int64            `json:"post_id"`
+	Question       string           `json:"question"`
+	MultipleChoice bool             `json:"multiple_choice"`
+	HideResults    bool             `json:"hide_results"`
+	ClosesAt       pgtype.Timestamp `json:"closes_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+// returns no rows when the post already has a poll, which means it was imported before
+func (q *Queries) ImportPoll(ctx context.Context, arg ImportPollParams) (Poll, error) {
+	row := q.db.QueryRow(ctx, importPoll,
+		arg.PostID,
+		arg.Question,
+		arg.MultipleChoice,
+		arg.HideResults,
+		arg.ClosesAt,
+		arg.CreatedAt,
+	)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.Question,
+		&i.MultipleChoice,
+		&i.HideResults,
+		&i.ClosesAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const importPollBallot = `-- name: ImportPollBallot :exec
+INSERT INTO poll_ballots (poll_id, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4)
+`
+
+type ImportPollBallotParams struct {
+	PollID    int64            `json:"poll_id"`
+	UserID    int64            `json:"user_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) ImportPollBallot(ctx context.Context, arg ImportPollBallotParams) error {
+	_, err := q.db.Exec(ctx, importPollBallot,
+		arg.PollID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const importPost = `-- name: ImportPost :one
+INSERT INTO posts (title, content, content_html, user_id, username, topic_id, created_at, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, last_activity_at, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $7, CASE WHEN $12 = 'published' THEN $7 END)
+RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
+`
+
+type ImportPostParams struct {
+	Title       string           `json:"title"`
+	Content     string           `json:"content"`
+	ContentHtml string           `json:"content_html"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	TopicID     int64            `json:"topic_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	EditCount   int32            `json:"edit_count"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	Status      string           `json:"status"`
+	FlagReason  string           `json:"flag_reason"`
+	ContentHash string           `json:"content_hash"`
+	Pinned      bool             `json:"pinned"`
+	Locked      bool             `json:"locked"`
+	ArchivedAt  pgtype.Timestamp `json:"archived_at"`
+}
+
+// activity starts at the original created_at, the comments imported after it move it forward
+// published rows count as published since their creation
+func (q *Queries) ImportPost(ctx context.Context, arg ImportPostParams) (Post, error) {
+	row := q.db.QueryRow(ctx, importPost,
+		arg.Title,
+		arg.Content,
+		arg.ContentHtml,
+		arg.UserID,
+		arg.Username,
+		arg.TopicID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.EditCount,
+		arg.DeletedAt,
+		arg.DeletedBy,
+		arg.Status,
+		arg.FlagReason,
+		arg.ContentHash,
+		arg.Pinned,
+		arg.Locked,
+		arg.ArchivedAt,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const importRevision = `-- name: ImportRevision :execrows
+INSERT INTO revisions (target_type, target_id, revision, title, content, edited_by, created_at, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (target_type, target_id, revision) DO NOTHING
+`
+
+type ImportRevisionParams struct {
+	TargetType string           `json:"target_type"`
+	TargetID   int64            `json:"target_id"`
+	Revision   int32            `json:"revision"`
+	Title      pgtype.Text      `json:"title"`
+	Content    string           `json:"content"`
+	EditedBy   pgtype.Int8      `json:"edited_by"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	Status     string           `json:"status"`
+}
+
+func (q *Queries) ImportRevision(ctx context.Context, arg ImportRevisionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, importRevision,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Revision,
+		arg.Title,
+		arg.Content,
+		arg.EditedBy,
+		arg.CreatedAt,
+		arg.Status,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const importTag = `-- name: ImportTag :one
+INSERT INTO tags (name, topic_id, created_at) VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, name, topic_id, created_at
+`
+
+type ImportTagParams struct {
+	Name      string           `json:"name"`
+	TopicID   pgtype.Int8      `json:"topic_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// an existing tag keeps its own restriction
+func (q *Queries) ImportTag(ctx context.Context, arg ImportTagParams) (Tag, error) {
+	row := q.db.QueryRow(ctx, importTag, arg.Name, arg.TopicID, arg.CreatedAt)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TopicID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const importTopic = `-- name: ImportTopic :one
+INSERT INTO topics (name, description, user_id, username, created_at, deleted_at, deleted_by) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version
+`
+
+type ImportTopicParams struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+}
+
+// an existing topic with the same name is reused as is
+func (q *Queries) ImportTopic(ctx context.Context, arg ImportTopicParams) (Topic, error) {
+	row := q.db.QueryRow(ctx, importTopic,
+		arg.Name,
+		arg.Description,
+		arg.UserID,
+		arg.Username,
+		arg.CreatedAt,
+		arg.DeletedAt,
+		arg.DeletedBy,
+	)
+	var i Topic
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.UserID,
+		&i.Username,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PostCount,
+		&i.LastPostAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const importTopicSubscription = `-- name: ImportTopicSubscription :execrows
+INSERT INTO topic_subscriptions (user_id, topic_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING
+`
+
+type ImportTopicSubscriptionParams struct {
+	UserID    int64            `json:"user_id"`
+	TopicID   int64            `json:"topic_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+func (q *Queries) ImportTopicSubscription(ctx context.Context, arg ImportTopicSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, importTopicSubscription, arg.UserID, arg.TopicID, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const importUser = `-- name: ImportUser :one
+INSERT INTO users (username, password, role, created_at) VALUES ($1, $2, $3, $4)
+RETURNING id, username, password, created_at, role
+`
+
+type ImportUserParams struct {
+	Username  string           `json:"username"`
+	Password  string           `json:"password"`
+	Role      string           `json:"role"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+// the importer picks a free username first, so a clash means the name was taken in the meantime and fails the batch
+func (q *Queries) ImportUser(ctx context.Context, arg ImportUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, importUser,
+		arg.Username,
+		arg.Password,
+		arg.Role,
+		arg.CreatedAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const importUserFollow = `-- name: ImportUserFollow :execrows
+INSERT INTO user_follows (follower_id, followee_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING
+`
+
+type ImportUserFollowParams struct {
+	FollowerID int64            `json:"follower_id"`
+	FolloweeID int64            `json:"followee_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+func (q *Queries) ImportUserFollow(ctx context.Context, arg ImportUserFollowParams) (int64, error) {
+	result, err := q.db.Exec(ctx, importUserFollow, arg.FollowerID, arg.FolloweeID, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const isMutedInTopic = `-- name: IsMutedInTopic :one
+SELECT EXISTS (
+    SELECT 1 FROM topic_mutes
+    WHERE topic_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > now())
+) AS muted
+`
+
+type IsMutedInTopicParams struct {
+	TopicID int64 `json:"topic_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+func (q *Queries) IsMutedInTopic(ctx context.Context, arg IsMutedInTopicParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isMutedInTopic, arg.TopicID, arg.UserID)
+	var muted bool
+	err := row.Scan(&muted)
+	return muted, err
+}
+
+const listAttachmentVariants = `-- name: ListAttachmentVariants :many
+SELECT attachment_id, name, storage_key, content_type, size_bytes, width, height FROM attachment_variants WHERE attachment_id = $1 ORDER BY width
+`
+
+func (q *Queries) ListAttachmentVariants(ctx context.Context, attachmentID int64) ([]AttachmentVariant, error) {
+	rows, err := q.db.Query(ctx, listAttachmentVariants, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AttachmentVariant
+	for rows.Next() {
+		var i AttachmentVariant
+		if err := rows.Scan(
+			&i.AttachmentID,
+			&i.Name,
+			&i.StorageKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Width,
+			&i.Height,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAttachments = `-- name: ListAttachments :many
+SELECT id, storage_key, user_id, filename, content_type, size_bytes, target_type, target_id, created_at, attached_at, status, width, height, processing_error, processing_attempts, processing_claimed_at FROM attachments WHERE target_type = $1 AND target_id = $2 ORDER BY id
+`
+
+type ListAttachmentsParams struct {
+	TargetType pgtype.Text `json:"target_type"`
+	TargetID   pgtype.Int8 `json:"target_id"`
+}
+
+func (q *Queries) ListAttachments(ctx context.Context, arg ListAttachmentsParams) ([]Attachment, error) {
+	rows, err := q.db.Query(ctx, listAttachments, arg.TargetType, arg.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.StorageKey,
+			&i.UserID,
+			&i.Filename,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.TargetType,
+			&i.TargetID,
+			&i.CreatedAt,
+			&i.AttachedAt,
+			&i.Status,
+			&i.Width,
+			&i.Height,
+			&i.ProcessingError,
+			&i.ProcessingAttempts,
+			&i.ProcessingClaimedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLog = `-- name: ListAuditLog :many
+SELECT id, actor_id, action, target_type, target_id, details, created_at, request_id, before_state, after_state FROM audit_log
+WHERE ($1::BIGINT IS NULL OR actor_id = $1)
+  AND ($2::TEXT IS NULL OR target_type = $2)
+  AND ($3::BIGINT IS NULL OR target_id = $3)
+  AND ($4::TIMESTAMP IS NULL OR created_at >= $4)
+  AND ($5::TIMESTAMP IS NULL OR created_at < $5)
+ORDER BY created_at DESC, id DESC
+LIMIT $6 OFFSET $7
+`
+
+type ListAuditLogParams struct {
+	ActorID     pgtype.Int8      `json:"actor_id"`
+	TargetType  pgtype.Text      `json:"target_type"`
+	TargetID    pgtype.Int8      `json:"target_id"`
+	CreatedFrom pgtype.Timestamp `json:"created_from"`
+	CreatedTo   pgtype.Timestamp `json:"created_to"`
+	RowLimit    int32            `json:"row_limit"`
+	RowOffset   int32            `json:"row_offset"`
+}
+
+func (q *Queries) ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLog,
+		arg.ActorID,
+		arg.TargetType,
+		arg.TargetID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Details,
+			&i.CreatedAt,
+			&i.RequestID,
+			&i.BeforeState,
+			&i.AfterState,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBallotChoices = `-- name: ListBallotChoices :many
+SELECT option_id FROM poll_votes WHERE poll_id = $1 AND user_id = $2 ORDER BY option_id
+`
+
+type ListBallotChoicesParams struct {
+	PollID int64 `json:"poll_id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) ListBallotChoices(ctx context.Context, arg ListBallotChoicesParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listBallotChoices, arg.PollID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var optionID int64
+		if err := rows.Scan(&optionID); err != nil {
+			return nil, err
+		}
+		items = append(items, optionID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBannedWords = `-- name: ListBannedWords :many
+SELECT id, word, action, created_by, created_at FROM banned_words ORDER BY word
+`
+
+func (q *Queries) ListBannedWords(ctx context.Context) ([]BannedWord, error) {
+	rows, err := q.db.Query(ctx, listBannedWords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BannedWord
+	for rows.Next() {
+		var i BannedWord
+		if err := rows.Scan(
+			&i.ID,
+			&i.Word,
+			&i.Action,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookmarkCollections = `-- name: ListBookmarkCollections :many
+SELECT id, user_id, name, created_at FROM bookmark_collections WHERE user_id = $1 ORDER BY name
+`
+
+func (q *Queries) ListBookmarkCollections(ctx context.Context, userID int64) ([]BookmarkCollection, error) {
+	rows, err := q.db.Query(ctx, listBookmarkCollections, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookmarkCollection
+	for rows.Next() {
+		var i BookmarkCollection
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookmarks = `-- name: ListBookmarks :many
+SELECT
+    b.id, b.user_id, b.target_type, b.target_id, b.collection_id, b.created_at,
+    COALESCE(p.title, cp.title, '')::text AS title,
+    COALESCE(p.content_html, c.content_html, '')::text AS content_html,
+    COALESCE(p.username, c.username, '')::text AS author,
+    COALESCE(p.topic_id, cp.topic_id, 0)::bigint AS topic_id,
+    COALESCE(p.id, c.post_id, 0)::bigint AS post_id
+FROM bookmarks b
+LEFT JOIN posts p ON b.target_type = 'post' AND p.id = b.target_id AND p.deleted_at IS NULL AND p.status = 'published'
+LEFT JOIN comments c ON b.target_type = 'comment' AND c.id = b.target_id AND c.deleted_at IS NULL AND c.status = 'published'
+LEFT JOIN posts cp ON cp.id = c.post_id AND cp.deleted_at IS NULL AND cp.status = 'published'
+LEFT JOIN topics t ON t.id = COALESCE(p.topic_id, cp.topic_id) AND t.deleted_at IS NULL
+WHERE b.user_id = $1
+  AND ($2::BIGINT IS NULL OR b.collection_id = $2)
+  AND (p.id IS NOT NULL OR cp.id IS NOT NULL)
+  AND t.id IS NOT NULL
+ORDER BY b.created_at DESC, b.id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListBookmarksParams struct {
+	UserID       int64       `json:"user_id"`
+	CollectionID pgtype.Int8 `json:"collection_id"`
+	RowLimit     int32       `json:"row_limit"`
+	RowOffset    int32       `json:"row_offset"`
+}
+
+type ListBookmarksRow struct {
+	ID           int64            `json:"id"`
+	UserID       int64            `json:"user_id"`
+	TargetType   string           `json:"target_type"`
+	TargetID     int64            `json:"target_id"`
+	CollectionID pgtype.Int8      `json:"collection_id"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	Title        string           `json:"title"`
+	ContentHtml  string           `json:"content_html"`
+	Author       string           `json:"author"`
+	TopicID      int64            `json:"topic_id"`
+	PostID       int64            `json:"post_id"`
+}
+
+func (q *Queries) ListBookmarks(ctx context.Context, arg ListBookmarksParams) ([]ListBookmarksRow, error) {
+	rows, err := q.db.Query(ctx, listBookmarks,
+		arg.UserID,
+		arg.CollectionID,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookmarksRow
+	for rows.Next() {
+		var i ListBookmarksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.CollectionID,
+			&i.CreatedAt,
+			&i.Title,
+			&i.ContentHtml,
+			&i.Author,
+			&i.TopicID,
+			&i.PostID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listComments = `-- name: ListComments :many
+SELECT
+    c.id, c.content, c.user_id, c.username, c.post_id, c.created_at, c.content_html, c.updated_at, c.edit_count, c.deleted_at, c.deleted_by, c.status, c.flag_reason, c.content_hash, c.version, c.published_at,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = $1 AND b.target_type = 'comment' AND b.target_id = c.id) AS saved
+FROM comments c
+JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE c.post_id = $2 AND c.deleted_at IS NULL AND c.status = 'published'
+`
+
+type ListCommentsParams struct {
+	UserID int64 `json:"user_id"`
+	PostID int64 `json:"post_id"`
+}
+
+type ListCommentsRow struct {
+	ID          int64            `json:"id"`
+	Content     string           `json:"content"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	PostID      int64            `json:"post_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	ContentHtml string           `json:"content_html"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	EditCount   int32            `json:"edit_count"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	Status      string           `json:"status"`
+	FlagReason  string           `json:"flag_reason"`
+	ContentHash string           `json:"content_hash"`
+	Version     int32            `json:"version"`
+	PublishedAt pgtype.Timestamp `json:"published_at"`
+	Saved       bool             `json:"saved"`
+}
+
+// deleting a post or its topic hides the comments along with it
+func (q *Queries) ListComments(ctx context.Context, arg ListCommentsParams) ([]ListCommentsRow, error) {
+	rows, err := q.db.Query(ctx, listComments, arg.UserID, arg.PostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCommentsRow
+	for rows.Next() {
+		var i ListCommentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Version,
+			&i.PublishedAt,
+			&i.Saved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsForReindex = `-- name: ListCommentsForReindex :many
+SELECT id, content FROM comments
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListCommentsForReindexParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+type ListCommentsForReindexRow struct {
+	ID      int64  `json:"id"`
+	Content string `json:"content"`
+}
+
+func (q *Queries) ListCommentsForReindex(ctx context.Context, arg ListCommentsForReindexParams) ([]ListCommentsForReindexRow, error) {
+	rows, err := q.db.Query(ctx, listCommentsForReindex, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCommentsForReindexRow
+	for rows.Next() {
+		var i ListCommentsForReindexRow
+		if err := rows.Scan(&i.ID, &i.Content); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentsPage = `-- name: ListCommentsPage :many
+SELECT
+    c.id, c.content, c.user_id, c.username, c.post_id, c.created_at, c.content_html, c.updated_at, c.edit_count, c.deleted_at, c.deleted_by, c.status, c.flag_reason, c.content_hash, c.version, c.published_at,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = $1 AND b.target_type = 'comment' AND b.target_id = c.id) AS saved
+FROM comments c
+JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE c.post_id = $2 AND c.deleted_at IS NULL AND c.status = 'published'
+ORDER BY c.created_at, c.id
+LIMIT $3
+`
+
+type ListCommentsPageParams struct {
+	UserID   int64 `json:"user_id"`
+	PostID   int64 `json:"post_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+type ListCommentsPageRow struct {
+	ID          int64            `json:"id"`
+	Content     string           `json:"content"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	PostID      int64            `json:"post_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	ContentHtml string           `json:"content_html"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	EditCount   int32            `json:"edit_count"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	Status      string           `json:"status"`
+	FlagReason  string           `json:"flag_reason"`
+	ContentHash string           `json:"content_hash"`
+	Version     int32            `json:"version"`
+	PublishedAt pgtype.Timestamp `json:"published_at"`
+	Saved       bool             `json:"saved"`
+}
+
+// oldest first, the way a thread is read
+func (q *Queries) ListCommentsPage(ctx context.Context, arg ListCommentsPageParams) ([]ListCommentsPageRow, error) {
+	rows, err := q.db.Query(ctx, listCommentsPage, arg.UserID, arg.PostID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCommentsPageRow
+	for rows.Next() {
+		var i ListCommentsPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Version,
+			&i.PublishedAt,
+			&i.Saved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeed = `-- name: ListFeed :many
+SELECT p.id, p.title, p.content, p.user_id, p.username, p.topic_id, p.created_at, p.content_html, p.updated_at, p.edit_count, p.deleted_at, p.deleted_by, p.status, p.flag_reason, p.content_hash, p.pinned, p.locked, p.archived_at, p.comment_count, p.last_activity_at, p.version, p.published_at FROM posts p
+JOIN topic_subscriptions s ON s.topic_id = p.topic_id AND s.user_id = $1
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND p.status = 'published'
+  AND ($2::TIMESTAMP IS NULL OR (p.created_at, p.id) < ($2, $3::BIGINT))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT $4
+`
+
+type ListFeedParams struct {
+	UserID          int64            `json:"user_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.Int8      `json:"cursor_id"`
+	RowLimit        int32            `json:"row_limit"`
+}
+
+func (q *Queries) ListFeed(ctx context.Context, arg ListFeedParams) ([]Post, error) {
+	rows, err := q.db.Query(ctx, listFeed,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.TopicID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Pinned,
+			&i.Locked,
+			&i.ArchivedAt,
+			&i.CommentCount,
+			&i.LastActivityAt,
+			&i.Version,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFollowedComments = `-- name: ListFollowedComments :many
+SELECT c.id, c.content, c.user_id, c.username, c.post_id, c.created_at, c.content_html, c.updated_at, c.edit_count, c.deleted_at, c.deleted_by, c.status, c.flag_reason, c.content_hash, c.version, c.published_at, p.topic_id, p.title AS post_title FROM comments c
+JOIN user_follows f ON f.followee_id = c.user_id AND f.follower_id = $1
+JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL AND p.status = 'published'
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE c.deleted_at IS NULL AND c.status = 'published'
+  AND ($2::TIMESTAMP IS NULL OR (c.created_at, c.id) < ($2, $3::BIGINT))
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT $4
+`
+
+type ListFollowedCommentsParams struct {
+	UserID          int64            `json:"user_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.Int8      `json:"cursor_id"`
+	RowLimit        int32            `json:"row_limit"`
+}
+
+type ListFollowedCommentsRow struct {
+	ID          int64            `json:"id"`
+	Content     string           `json:"content"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	PostID      int64            `json:"post_id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	ContentHtml string           `json:"content_html"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	EditCount   int32            `json:"edit_count"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	Status      string           `json:"status"`
+	FlagReason  string           `json:"flag_reason"`
+	ContentHash string           `json:"content_hash"`
+	Version     int32            `json:"version"`
+	PublishedAt pgtype.Timestamp `json:"published_at"`
+	TopicID     int64            `json:"topic_id"`
+	PostTitle   string           `json:"post_title"`
+}
+
+func (q *Queries) ListFollowedComments(ctx context.Context, arg ListFollowedCommentsParams) ([]ListFollowedCommentsRow, error) {
+	rows, err := q.db.Query(ctx, listFollowedComments,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFollowedCommentsRow
+	for rows.Next() {
+		var i ListFollowedCommentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Version,
+			&i.PublishedAt,
+			&i.TopicID,
+			&i.PostTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFollowedPosts = `-- name: ListFollowedPosts :many
+SELECT p.id, p.title, p.content, p.user_id, p.username, p.topic_id, p.created_at, p.content_html, p.updated_at, p.edit_count, p.deleted_at, p.deleted_by, p.status, p.flag_reason, p.content_hash, p.pinned, p.locked, p.archived_at, p.comment_count, p.last_activity_at, p.version, p.published_at FROM posts p
+JOIN user_follows f ON f.followee_id = p.user_id AND f.follower_id = $1
+JOIN topics t ON t.id = p.topic_id AND t.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND p.status = 'published'
+  AND ($2::TIMESTAMP IS NULL OR (p.created_at, p.id) < ($2, $3::BIGINT))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT $4
+`
+
+type ListFollowedPostsParams struct {
+	UserID          int64            `json:"user_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.Int8      `json:"cursor_id"`
+	RowLimit        int32            `json:"row_limit"`
+}
+
+func (q *Queries) ListFollowedPosts(ctx context.Context, arg ListFollowedPostsParams) ([]Post, error) {
+	rows, err := q.db.Query(ctx, listFollowedPosts,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.TopicID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Pinned,
+			&i.Locked,
+			&i.ArchivedAt,
+			&i.CommentCount,
+			&i.LastActivityAt,
+			&i.Version,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFollowers = `-- name: ListFollowers :many
+SELECT u.id, u.username, f.created_at AS followed_at
+FROM user_follows f
+JOIN users u ON u.id = f.follower_id
+WHERE f.followee_id = $1
+ORDER BY f.created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListFollowersParams struct {
+	FolloweeID int64 `json:"followee_id"`
+	Limit      int32 `json:"limit"`
+	Offset     int32 `json:"offset"`
+}
+
+type ListFollowersRow struct {
+	ID         int64            `json:"id"`
+	Username   string           `json:"username"`
+	FollowedAt pgtype.Timestamp `json:"followed_at"`
+}
+
+func (q *Queries) ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error) {
+	rows, err := q.db.Query(ctx, listFollowers, arg.FolloweeID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFollowersRow
+	for rows.Next() {
+		var i ListFollowersRow
+		if err := rows.Scan(&i.ID, &i.Username, &i.FollowedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFollowing = `-- name: ListFollowing :many
+SELECT u.id, u.username, f.created_at AS followed_at
+FROM user_follows f
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = $1
+ORDER BY f.created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListFollowingParams struct {
+	FollowerID int64 `json:"follower_id"`
+	Limit      int32 `json:"limit"`
+	Offset     int32 `json:"offset"`
+}
+
+type ListFollowingRow struct {
+	ID         int64            `json:"id"`
+	Username   string           `json:"username"`
+	FollowedAt pgtype.Timestamp `json:"followed_at"`
+}
+
+func (q *Queries) ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error) {
+	rows, err := q.db.Query(ctx, listFollowing, arg.FollowerID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFollowingRow
+	for rows.Next() {
+		var i ListFollowingRow
+		if err := rows.Scan(&i.ID, &i.Username, &i.FollowedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotifications = `-- name: ListNotifications :many
+SELECT id, user_id, kind, actor_id, target_type, target_id, read_at, created_at FROM notifications
+WHERE user_id = $1 AND (NOT $2::BOOLEAN OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListNotificationsParams struct {
+	UserID     int64 `json:"user_id"`
+	UnreadOnly bool  `json:"unread_only"`
+	RowLimit   int32 `json:"row_limit"`
+	RowOffset  int32 `json:"row_offset"`
+}
+
+func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error) {
+	rows, err := q.db.Query(ctx, listNotifications,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Notification
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Kind,
+			&i.ActorID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.ReadAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenReportsForTarget = `-- name: ListOpenReportsForTarget :many
+SELECT id, target_type, target_id, reason, note, reporter_id, status, resolution, resolved_by, resolved_at, created_at FROM reports WHERE target_type = $1 AND target_id = $2 AND status = 'open' ORDER BY created_at
+`
+
+type ListOpenReportsForTargetParams struct {
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+}
+
+func (q *Queries) ListOpenReportsForTarget(ctx context.Context, arg ListOpenReportsForTargetParams) ([]Report, error) {
+	rows, err := q.db.Query(ctx, listOpenReportsForTarget, arg.TargetType, arg.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Report
+	for rows.Next() {
+		var i Report
+		if err := rows.Scan(
+			&i.ID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Reason,
+			&i.Note,
+			&i.ReporterID,
+			&i.Status,
+			&i.Resolution,
+			&i.ResolvedBy,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrphanedAttachments = `-- name: ListOrphanedAttachments :many
+SELECT a.id, a.storage_key, a.user_id, a.filename, a.content_type, a.size_bytes, a.target_type, a.target_id, a.created_at, a.attached_at, a.status, a.width, a.height, a.processing_error, a.processing_attempts, a.processing_claimed_at FROM attachments a
+WHERE (a.target_id IS NULL AND a.created_at < $1::TIMESTAMP)
+   OR (a.target_type = 'post' AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = a.target_id))
+   OR (a.target_type = 'comment' AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.id = a.target_id))
+ORDER BY a.id
+LIMIT $2
+`
+
+type ListOrphanedAttachmentsParams struct {
+	Cutoff   pgtype.Timestamp `json:"cutoff"`
+	RowLimit int32            `json:"row_limit"`
+}
+
+// uploads never attached to anything, or whose post or comment has been purged
+func (q *Queries) ListOrphanedAttachments(ctx context.Context, arg ListOrphanedAttachmentsParams) ([]Attachment, error) {
+	rows, err := q.db.Query(ctx, listOrphanedAttachments, arg.Cutoff, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attachment
+	for rows.Next() {
+		var i Attachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.StorageKey,
+			&i.UserID,
+			&i.Filename,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.TargetType,
+			&i.TargetID,
+			&i.CreatedAt,
+			&i.AttachedAt,
+			&i.Status,
+			&i.Width,
+			&i.Height,
+			&i.ProcessingError,
+			&i.ProcessingAttempts,
+			&i.ProcessingClaimedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingComments = `-- name: ListPendingComments :many
+SELECT id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at FROM comments WHERE status = 'pending' AND deleted_at IS NULL ORDER BY created_at LIMIT $1 OFFSET $2
+`
+
+type ListPendingCommentsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListPendingComments(ctx context.Context, arg ListPendingCommentsParams) ([]Comment, error) {
+	rows, err := q.db.Query(ctx, listPendingComments, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Version,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingPosts = `-- name: ListPendingPosts :many
+SELECT id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at FROM posts WHERE status = 'pending' AND deleted_at IS NULL ORDER BY created_at LIMIT $1 OFFSET $2
+`
+
+type ListPendingPostsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListPendingPosts(ctx context.Context, arg ListPendingPostsParams) ([]Post, error) {
+	rows, err := q.db.Query(ctx, listPendingPosts, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.TopicID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Pinned,
+			&i.Locked,
+			&i.ArchivedAt,
+			&i.CommentCount,
+			&i.LastActivityAt,
+			&i.Version,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPollOptions = `-- name: ListPollOptions :many
+SELECT id, poll_id, position, label FROM poll_options WHERE poll_id = $1 ORDER BY position
+`
+
+func (q *Queries) ListPollOptions(ctx context.Context, pollID int64) ([]PollOption, error) {
+	rows, err := q.db.Query(ctx, listPollOptions, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollOption
+	for rows.Next() {
+		var i PollOption
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Label,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPollResults = `-- name: ListPollResults :many
+SELECT o.id, o.poll_id, o.position, o.label, COUNT(v.user_id)::bigint AS votes
+FROM poll_options o
+LEFT JOIN poll_votes v ON v.option_id = o.id
+WHERE o.poll_id = $1
+GROUP BY o.id
+ORDER BY o.position
+`
+
+type ListPollResultsRow struct {
+	ID       int64  `json:"id"`
+	PollID   int64  `json:"poll_id"`
+	Position int32  `json:"position"`
+	Label    string `json:"label"`
+	Votes    int64  `json:"votes"`
+}
+
+func (q *Queries) ListPollResults(ctx context.Context, pollID int64) ([]ListPollResultsRow, error) {
+	rows, err := q.db.Query(ctx, listPollResults, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPollResultsRow
+	for rows.Next() {
+		var i ListPollResultsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Label,
+			&i.Votes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPostTags = `-- name: ListPostTags :many
+SELECT t.name FROM post_tags pt
+JOIN tags t ON t.id = pt.tag_id
+WHERE pt.post_id = $1
+ORDER BY t.name
+`
+
+func (q *Queries) ListPostTags(ctx context.Context, postID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listPostTags, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPosts = `-- name: ListPosts :many
+SELECT
+    p.id, p.title, p.content, p.user_id, p.username, p.topic_id, p.created_at, p.content_html, p.updated_at, p.edit_count, p.deleted_at, p.deleted_by, p.status, p.flag_reason, p.content_hash, p.pinned, p.locked, p.archived_at, p.comment_count, p.last_activity_at, p.version, p.published_at,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = $1 AND b.target_type = 'post' AND b.target_id = p.id) AS saved,
+    ARRAY(SELECT t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name)::text[] AS tags,
+    EXISTS(SELECT 1 FROM polls pl WHERE pl.post_id = p.id) AS has_poll
+FROM posts p
+JOIN topics tp ON tp.id = p.topic_id AND tp.deleted_at IS NULL
+WHERE p.topic_id = $2 AND p.deleted_at IS NULL AND p.status = 'published'
+ORDER BY p.pinned DESC, p.created_at DESC, p.id DESC
+`
+
+type ListPostsParams struct {
+	UserID  int64 `json:"user_id"`
+	TopicID int64 `json:"topic_id"`
+}
+
+type ListPostsRow struct {
+	ID             int64            `json:"id"`
+	Title          string           `json:"title"`
+	Content        string           `json:"content"`
+	UserID         int64            `json:"user_id"`
+	Username       string           `json:"username"`
+	TopicID        int64            `json:"topic_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	ContentHtml    string           `json:"content_html"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	EditCount      int32            `json:"edit_count"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy      pgtype.Int8      `json:"deleted_by"`
+	Status         string           `json:"status"`
+	FlagReason     string           `json:"flag_reason"`
+	ContentHash    string           `json:"content_hash"`
+	Pinned         bool             `json:"pinned"`
+	Locked         bool             `json:"locked"`
+	ArchivedAt     pgtype.Timestamp `json:"archived_at"`
+	CommentCount   int32            `json:"comment_count"`
+	LastActivityAt pgtype.Timestamp `json:"last_activity_at"`
+	Version        int32            `json:"version"`
+	PublishedAt    pgtype.Timestamp `json:"published_at"`
+	Saved          bool             `json:"saved"`
+	Tags           []string         `json:"tags"`
+	HasPoll        bool             `json:"has_poll"`
+}
+
+func (q *Queries) ListPosts(ctx context.Context, arg ListPostsParams) ([]ListPostsRow, error) {
+	rows, err := q.db.Query(ctx, listPosts, arg.UserID, arg.TopicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPostsRow
+	for rows.Next() {
+		var i ListPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.TopicID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Pinned,
+			&i.Locked,
+			&i.ArchivedAt,
+			&i.CommentCount,
+			&i.LastActivityAt,
+			&i.Version,
+			&i.PublishedAt,
+			&i.Saved,
+			&i.Tags,
+			&i.HasPoll,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPostsByTag = `-- name: ListPostsByTag :many
+SELECT
+    p.id, p.title, p.content, p.user_id, p.username, p.topic_id, p.created_at, p.content_html, p.updated_at, p.edit_count, p.deleted_at, p.deleted_by, p.status, p.flag_reason, p.content_hash, p.pinned, p.locked, p.archived_at, p.comment_count, p.last_activity_at, p.version, p.published_at,
+    EXISTS(SELECT 1 FROM bookmarks b WHERE b.user_id = $1 AND b.target_type = 'post' AND b.target_id = p.id) AS saved,
+    ARRAY(SELECT t2.name FROM post_tags pt2 JOIN tags t2 ON t2.id = pt2.tag_id WHERE pt2.post_id = p.id ORDER BY t2.name)::text[] AS tags
+FROM posts p
+JOIN post_tags pt ON pt.post_id = p.id
+JOIN tags t ON t.id = pt.tag_id AND t.name = $2
+JOIN topics tp ON tp.id = p.topic_id AND tp.deleted_at IS NULL
+WHERE p.deleted_at IS NULL AND p.status = 'published'
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListPostsByTagParams struct {
+	UserID    int64  `json:"user_id"`
+	Tag       string `json:"tag"`
+	RowLimit  int32  `json:"row_limit"`
+	RowOffset int32  `json:"row_offset"`
+}
+
+type ListPostsByTagRow struct {
+	ID             int64            `json:"id"`
+	Title          string           `json:"title"`
+	Content        string           `json:"content"`
+	UserID         int64            `json:"user_id"`
+	Username       string           `json:"username"`
+	TopicID        int64            `json:"topic_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	ContentHtml    string           `json:"content_html"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	EditCount      int32            `json:"edit_count"`
+	DeletedAt      pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy      pgtype.Int8      `json:"deleted_by"`
+	Status         string           `json:"status"`
+	FlagReason     string           `json:"flag_reason"`
+	ContentHash    string           `json:"content_hash"`
+	Pinned         bool             `json:"pinned"`
+	Locked         bool             `json:"locked"`
+	ArchivedAt     pgtype.Timestamp `json:"archived_at"`
+	CommentCount   int32            `json:"comment_count"`
+	LastActivityAt pgtype.Timestamp `json:"last_activity_at"`
+	Version        int32            `json:"version"`
+	PublishedAt    pgtype.Timestamp `json:"published_at"`
+	Saved          bool             `json:"saved"`
+	Tags           []string         `json:"tags"`
+}
+
+func (q *Queries) ListPostsByTag(ctx context.Context, arg ListPostsByTagParams) ([]ListPostsByTagRow, error) {
+	rows, err := q.db.Query(ctx, listPostsByTag,
+		arg.UserID,
+		arg.Tag,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPostsByTagRow
+	for rows.Next() {
+		var i ListPostsByTagRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.UserID,
+			&i.Username,
+			&i.TopicID,
+			&i.CreatedAt,
+			&i.ContentHtml,
+			&i.UpdatedAt,
+			&i.EditCount,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.FlagReason,
+			&i.ContentHash,
+			&i.Pinned,
+			&i.Locked,
+			&i.ArchivedAt,
+			&i.CommentCount,
+			&i.LastActivityAt,
+			&i.Version,
+			&i.PublishedAt,
+			&i.Saved,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPostsForReindex = `-- name: ListPostsForReindex :many
+SELECT id, content FROM posts
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListPostsForReindexParams struct {
+	AfterID  int64 `json:"after_id"`
+	RowLimit int32 `json:"row_limit"`
+}
+
+type ListPostsForReindexRow struct {
+	ID      int64  `json:"id"`
+	Content string `json:"content"`
+}
+
+func (q *Queries) ListPostsForReindex(ctx context.Context, arg ListPostsForReindexParams) ([]ListPostsForReindexRow, error) {
+	rows, err := q.db.Query(ctx, listPostsForReindex, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPostsForReindexRow
+	for rows.Next() {
+		var i ListPostsForReindexRow
+		if err := rows.Scan(&i.ID, &i.Content); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReportQueue = `-- name: ListReportQueue :many
+SELECT
+    target_type,
+    target_id,
+    COUNT(*) AS report_count,
+    array_agg(DISTINCT reason)::text[] AS reasons,
+    MIN(created_at)::timestamp AS first_reported_at,
+    MAX(created_at)::timestamp AS last_reported_at
+FROM reports
+WHERE status = 'open'
+GROUP BY target_type, target_id
+ORDER BY report_count DESC, first_reported_at
+LIMIT $1 OFFSET $2
+`
+
+type ListReportQueueParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListReportQueueRow struct {
+	TargetType      string           `json:"target_type"`
+	TargetID        int64            `json:"target_id"`
+	ReportCount     int64            `json:"report_count"`
+	Reasons         []string         `json:"reasons"`
+	FirstReportedAt pgtype.Timestamp `json:"first_reported_at"`
+	LastReportedAt  pgtype.Timestamp `json:"last_reported_at"`
+}
+
+func (q *Queries) ListReportQueue(ctx context.Context, arg ListReportQueueParams) ([]ListReportQueueRow, error) {
+	rows, err := q.db.Query(ctx, listReportQueue, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReportQueueRow
+	for rows.Next() {
+		var i ListReportQueueRow
+		if err := rows.Scan(
+			&i.TargetType,
+			&i.TargetID,
+			&i.ReportCount,
+			&i.Reasons,
+			&i.FirstReportedAt,
+			&i.LastReportedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRevisions = `-- name: ListRevisions :many
+SELECT id, target_type, target_id, revision, title, content, edited_by, created_at, status FROM revisions WHERE target_type = $1 AND target_id = $2 ORDER BY revision DESC
+`
+
+type ListRevisionsParams struct {
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+}
+
+func (q *Queries) ListRevisions(ctx context.Context, arg ListRevisionsParams) ([]Revision, error) {
+	rows, err := q.db.Query(ctx, listRevisions, arg.TargetType, arg.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Revision
+	for rows.Next() {
+		var i Revision
+		if err := rows.Scan(
+			&i.ID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Revision,
+			&i.Title,
+			&i.Content,
+			&i.EditedBy,
+			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSanctionsForUser = `-- name: ListSanctionsForUser :many
+SELECT id, user_id, kind, reason, issued_by, expires_at, revoked_at, revoked_by, created_at FROM user_sanctions WHERE user_id = $1 ORDER BY created_at DESC
+`
+
+func (q *Queries) ListSanctionsForUser(ctx context.Context, userID int64) ([]UserSanction, error) {
+	rows, err := q.db.Query(ctx, listSanctionsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserSanction
+	for rows.Next() {
+		var i UserSanction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Kind,
+			&i.Reason,
+			&i.IssuedBy,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+			&i.RevokedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavedCommentIDs = `-- name: ListSavedCommentIDs :many
+SELECT b.target_id FROM bookmarks b
+JOIN comments c ON c.id = b.target_id
+WHERE b.user_id = $1 AND b.target_type = 'comment' AND c.post_id = $2
+`
+
+type ListSavedCommentIDsParams struct {
+	UserID int64 `json:"user_id"`
+	PostID int64 `json:"post_id"`
+}
+
+// which comments of a post the user saved, laid over a comment listing shared by everyone
+func (q *Queries) ListSavedCommentIDs(ctx context.Context, arg ListSavedCommentIDsParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listSavedCommentIDs, arg.UserID, arg.PostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var targetID int64
+		if err := rows.Scan(&targetID); err != nil {
+			return nil, err
+		}
+		items = append(items, targetID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavedPostIDs = `-- name: ListSavedPostIDs :many
+SELECT b.target_id FROM bookmarks b
+JOIN posts p ON p.id = b.target_id
+WHERE b.user_id = $1 AND b.target_type = 'post' AND p.topic_id = $2
+`
+
+type ListSavedPostIDsParams struct {
+	UserID  int64 `json:"user_id"`
+	TopicID int64 `json:"topic_id"`
+}
+
+// which posts of a topic the user saved, laid over a post listing shared by everyone
+func (q *Queries) ListSavedPostIDs(ctx context.Context, arg ListSavedPostIDsParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listSavedPostIDs, arg.UserID, arg.TopicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var targetID int64
+		if err := rows.Scan(&targetID); err != nil {
+			return nil, err
+		}
+		items = append(items, targetID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStalledAttachments = `-- name: ListStalledAttachments :many
+SELECT id FROM attachments
+WHERE status = 'processing' AND created_at < $1::TIMESTAMP
+  AND (processing_claimed_at IS NULL OR processing_claimed_at < $1::TIMESTAMP)
+ORDER BY id
+LIMIT $2
+`
+
+type ListStalledAttachmentsParams struct {
+	Cutoff   pgtype.Timestamp `json:"cutoff"`
+	RowLimit int32            `json:"row_limit"`
+}
+
+// uploads still waiting for processing, e.g. because the server restarted before the queue was drained
+func (q *Queries) ListStalledAttachments(ctx context.Context, arg ListStalledAttachmentsParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listStalledAttachments, arg.Cutoff, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopicMutes = `-- name: ListTopicMutes :many
+SELECT topic_id, user_id, reason, muted_by, expires_at, created_at FROM topic_mutes WHERE topic_id = $1 AND (expires_at IS NULL OR expires_at > now()) ORDER BY created_at DESC
+`
+
+func (q *Queries) ListTopicMutes(ctx context.Context, topicID int64) ([]TopicMute, error) {
+	rows, err := q.db.Query(ctx, listTopicMutes, topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TopicMute
+	for rows.Next() {
+		var i TopicMute
+		if err := rows.Scan(
+			&i.TopicID,
+			&i.UserID,
+			&i.Reason,
+			&i.MutedBy,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopicStates = `-- name: ListTopicStates :many
+SELECT
+    t.id AS topic_id,
+    EXISTS(SELECT 1 FROM topic_subscriptions s WHERE s.topic_id = t.id AND s.user_id = $1) AS subscribed,
+    (
+        SELECT COUNT(*) FROM posts p
+        WHERE p.topic_id = t.id AND p.deleted_at IS NULL AND p.status = 'published'
+          AND p.user_id <> $1
+          AND p.created_at > COALESCE(v.last_visited_at, '-infinity'::timestamp)
+    )::bigint AS unread_count
+FROM topics t
+LEFT JOIN topic_visits v ON v.topic_id = t.id AND v.user_id = $1
+WHERE t.deleted_at IS NULL
+`
+
+type ListTopicStatesRow struct {
+	TopicID     int64 `json:"topic_id"`
+	Subscribed  bool  `json:"subscribed"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// the part of ListTopicsForUser that differs between users, laid over a listing shared by everyone
+func (q *Queries) ListTopicStates(ctx context.Context, userID int64) ([]ListTopicStatesRow, error) {
+	rows, err := q.db.Query(ctx, listTopicStates, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTopicStatesRow
+	for rows.Next() {
+		var i ListTopicStatesRow
+		if err := rows.Scan(&i.TopicID, &i.Subscribed, &i.UnreadCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopicSubscriptions = `-- name: ListTopicSubscriptions :many
+SELECT t.id, t.name, t.description, t.user_id, t.username, t.created_at, t.deleted_at, t.deleted_by, t.post_count, t.last_post_at, t.updated_at, t.version FROM topics t
+JOIN topic_subscriptions s ON s.topic_id = t.id
+WHERE s.user_id = $1 AND t.deleted_at IS NULL
+ORDER BY t.name
+`
+
+func (q *Queries) ListTopicSubscriptions(ctx context.Context, userID int64) ([]Topic, error) {
+	rows, err := q.db.Query(ctx, listTopicSubscriptions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Topic
+	for rows.Next() {
+		var i Topic
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.UserID,
+			&i.Username,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PostCount,
+			&i.LastPostAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopics = `-- name: ListTopics :many
+SELECT id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version FROM topics WHERE deleted_at IS NULL
+`
+
+func (q *Queries) ListTopics(ctx context.Context) ([]Topic, error) {
+	rows, err := q.db.Query(ctx, listTopics)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Topic
+	for rows.Next() {
+		var i Topic
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.UserID,
+			&i.Username,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PostCount,
+			&i.LastPostAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopicsForUser = `-- name: ListTopicsForUser :many
+SELECT
+    t.id, t.name, t.description, t.user_id, t.username, t.created_at, t.deleted_at, t.deleted_by, t.post_count, t.last_post_at, t.updated_at, t.version,
+    EXISTS(SELECT 1 FROM topic_subscriptions s WHERE s.topic_id = t.id AND s.user_id = $1) AS subscribed,
+    (
+        SELECT COUNT(*) FROM posts p
+        WHERE p.topic_id = t.id AND p.deleted_at IS NULL AND p.status = 'published'
+          AND p.user_id <> $1
+          AND p.created_at > COALESCE(v.last_visited_at, '-infinity'::timestamp)
+    )::bigint AS unread_count
+FROM topics t
+LEFT JOIN topic_visits v ON v.topic_id = t.id AND v.user_id = $1
+WHERE t.deleted_at IS NULL
+`
+
+type ListTopicsForUserRow struct {
+	ID          int64            `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	DeletedAt   pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy   pgtype.Int8      `json:"deleted_by"`
+	PostCount   int32            `json:"post_count"`
+	LastPostAt  pgtype.Timestamp `json:"last_post_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	Version     int32            `json:"version"`
+	Subscribed  bool             `json:"subscribed"`
+	UnreadCount int64            `json:"unread_count"`
+}
+
+func (q *Queries) ListTopicsForUser(ctx context.Context, userID int64) ([]ListTopicsForUserRow, error) {
+	rows, err := q.db.Query(ctx, listTopicsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTopicsForUserRow
+	for rows.Next() {
+		var i ListTopicsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.UserID,
+			&i.Username,
+			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.PostCount,
+			&i.LastPostAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Subscribed,
+			&i.UnreadCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markNotificationsRead = `-- name: MarkNotificationsRead :execrows
+UPDATE notifications SET read_at = now()
+WHERE user_id = $1 AND read_at IS NULL
+  AND (cardinality($2::BIGINT[]) = 0 OR id = ANY($2::BIGINT[]))
+`
+
+type MarkNotificationsReadParams struct {
+	UserID int64   `json:"user_id"`
+	Ids    []int64 `json:"ids"`
+}
+
+func (q *Queries) MarkNotificationsRead(ctx context.Context, arg MarkNotificationsReadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markNotificationsRead, arg.UserID, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const notifyFollowers = `-- name: NotifyFollowers :execrows
+INSERT INTO notifications (user_id, kind, actor_id, target_type, target_id)
+SELECT f.follower_id, $1::TEXT, f.followee_id, $2::TEXT, $3::BIGINT
+FROM user_follows f
+WHERE f.followee_id = $4
+`
+
+type NotifyFollowersParams struct {
+	Kind       string `json:"kind"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	ActorID    int64  `json:"actor_id"`
+}
+
+func (q *Queries) NotifyFollowers(ctx context.Context, arg NotifyFollowersParams) (int64, error) {
+	result, err := q.db.Exec(ctx, notifyFollowers,
+		arg.Kind,
+		arg.TargetType,
+		arg.TargetID,
+		arg.ActorID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedComments = `-- name: PurgeDeletedComments :execrows
+DELETE FROM comments WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedComments(ctx context.Context, cutoff pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedComments, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedPosts = `-- name: PurgeDeletedPosts :execrows
+DELETE FROM posts WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedPosts(ctx context.Context, cutoff pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedPosts, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedTopics = `-- name: PurgeDeletedTopics :execrows
+DELETE FROM topics WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedTopics(ctx context.Context, cutoff pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedTopics, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeOrphanedRevisions = `-- name: PurgeOrphanedRevisions :execrows
+DELETE FROM revisions r
+WHERE (r.target_type = 'post' AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = r.target_id))
+   OR (r.target_type = 'comment' AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.id = r.target_id))
+`
+
+func (q *Queries) PurgeOrphanedRevisions(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeOrphanedRevisions)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordTopicVisit = `-- name: RecordTopicVisit :exec
+INSERT INTO topic_visits (user_id, topic_id)
+SELECT $1::BIGINT, id FROM topics WHERE id = $2
+ON CONFLICT (user_id, topic_id) DO UPDATE SET last_visited_at = now()
+`
+
+type RecordTopicVisitParams struct {
+	UserID  int64 `json:"user_id"`
+	TopicID int64 `json:"topic_id"`
+}
+
+func (q *Queries) RecordTopicVisit(ctx context.Context, arg RecordTopicVisitParams) error {
+	_, err := q.db.Exec(ctx, recordTopicVisit, arg.UserID, arg.TopicID)
+	return err
+}
+
+const releaseAttachmentProcessing = `-- name: ReleaseAttachmentProcessing :exec
+UPDATE attachments SET processing_claimed_at = NULL WHERE id = $1 AND status = 'processing'
+`
+
+// lets the next resume pick the upload up again after a failed attempt
+func (q *Queries) ReleaseAttachmentProcessing(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, releaseAttachmentProcessing, id)
+	return err
+}
+
+const renameBookmarkCollection = `-- name: RenameBookmarkCollection :one
+UPDATE bookmark_collections SET name = $3 WHERE id = $1 AND user_id = $2 RETURNING id, user_id, name, created_at
+`
+
+type RenameBookmarkCollectionParams struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) RenameBookmarkCollection(ctx context.Context, arg RenameBookmarkCollectionParams) (BookmarkCollection, error) {
+	row := q.db.QueryRow(ctx, renameBookmarkCollection, arg.ID, arg.UserID, arg.Name)
+	var i BookmarkCollection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const repairPostCounters = `-- name: RepairPostCounters :execrows
+UPDATE posts p SET comment_count = s.comment_count, last_activity_at = s.last_activity_at
+FROM (
+    SELECT p2.id, COUNT(c.id)::INT AS comment_count, GREATEST(p2.created_at, MAX(c.created_at)) AS last_activity_at
+    FROM posts p2
+    LEFT JOIN comments c ON c.post_id = p2.id AND c.deleted_at IS NULL AND c.status = 'published'
+    GROUP BY p2.id
+) s
+WHERE s.id = p.id AND (p.comment_count, p.last_activity_at) IS DISTINCT FROM (s.comment_count, s.last_activity_at)
+`
+
+// recounts every post and only writes the ones that drifted, the count is how many were wrong
+func (q *Queries) RepairPostCounters(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, repairPostCounters)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const repairTopicCounters = `-- name: RepairTopicCounters :execrows
+UPDATE topics t SET post_count = s.post_count, last_post_at = s.last_post_at
+FROM (
+    SELECT t2.id, COUNT(p.id)::INT AS post_count, MAX(p.created_at) AS last_post_at
+    FROM topics t2
+    LEFT JOIN posts p ON p.topic_id = t2.id AND p.deleted_at IS NULL AND p.status = 'published'
+    GROUP BY t2.id
+) s
+WHERE s.id = t.id AND (t.post_count, t.last_post_at) IS DISTINCT FROM (s.post_count, s.last_post_at)
+`
+
+func (q *Queries) RepairTopicCounters(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, repairTopicCounters)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resolveReports = `-- name: ResolveReports :many
+UPDATE reports SET status = 'resolved', resolution = $3, resolved_by = $4, resolved_at = now()
+WHERE target_type = $1 AND target_id = $2 AND status = 'open'
+RETURNING id, target_type, target_id, reason, note, reporter_id, status, resolution, resolved_by, resolved_at, created_at
+`
+
+type ResolveReportsParams struct {
+	TargetType string      `json:"target_type"`
+	TargetID   int64       `json:"target_id"`
+	Resolution pgtype.Text `json:"resolution"`
+	ResolvedBy pgtype.Int8 `json:"resolved_by"`
+}
+
+func (q *Queries) ResolveReports(ctx context.Context, arg ResolveReportsParams) ([]Report, error) {
+	rows, err := q.db.Query(ctx, resolveReports,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Resolution,
+		arg.ResolvedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Report
+	for rows.Next() {
+		var i Report
+		if err := rows.Scan(
+			&i.ID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Reason,
+			&i.Note,
+			&i.ReporterID,
+			&i.Status,
+			&i.Resolution,
+			&i.ResolvedBy,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreComment = `-- name: RestoreComment :one
+UPDATE comments SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 RETURNING id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at
+`
+
+func (q *Queries) RestoreComment(ctx context.Context, id int64) (Comment, error) {
+	row := q.db.QueryRow(ctx, restoreComment, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const restorePost = `-- name: RestorePost :one
+UPDATE posts SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
+`
+
+func (q *Queries) RestorePost(ctx context.Context, id int64) (Post, error) {
+	row := q.db.QueryRow(ctx, restorePost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const restoreTopic = `-- name: RestoreTopic :one
+UPDATE topics SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 RETURNING id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version
+`
+
+func (q *Queries) RestoreTopic(ctx context.Context, id int64) (Topic, error) {
+	row := q.db.QueryRow(ctx, restoreTopic, id)
 	var i Topic
 	err := row.Scan(
 		&i.ID,
@@ -98,37 +4948,58 @@ func (q *Queries) CreateTopic(ctx context.Context, arg CreateTopicParams) (Topic
 		&i.UserID,
 		&i.Username,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PostCount,
+		&i.LastPostAt,
+		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO users (username, password) VALUES ($1, $2) RETURNING id, username, password, created_at
+const restrictTag = `-- name: RestrictTag :one
+INSERT INTO tags (name, topic_id) VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET topic_id = EXCLUDED.topic_id
+RETURNING id, name, topic_id, created_at
 `
 
-type CreateUserParams struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+type RestrictTagParams struct {
+	Name    string      `json:"name"`
+	TopicID pgtype.Int8 `json:"topic_id"`
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser, arg.Username, arg.Password)
-	var i User
+func (q *Queries) RestrictTag(ctx context.Context, arg RestrictTagParams) (Tag, error) {
+	row := q.db.QueryRow(ctx, restrictTag, arg.Name, arg.TopicID)
+	var i Tag
 	err := row.Scan(
 		&i.ID,
-		&i.Username,
-		&i.Password,
+		&i.Name,
+		&i.TopicID,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const deleteComment = `-- name: DeleteComment :one
-DELETE FROM comments WHERE id = $1 RETURNING id, content, user_id, username, post_id, created_at
+const revertComment = `-- name: RevertComment :one
+UPDATE comments SET content = $2, content_html = $3, content_hash = $4, status = 'published', flag_reason = NULL, updated_at = now(), edit_count = edit_count + 1, version = version + 1
+WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at
 `
 
-func (q *Queries) DeleteComment(ctx context.Context, id int64) (Comment, error) {
-	row := q.db.QueryRow(ctx, deleteComment, id)
+type RevertCommentParams struct {
+	ID          int64  `json:"id"`
+	Content     string `json:"content"`
+	ContentHtml string `json:"content_html"`
+	ContentHash string `json:"content_hash"`
+}
+
+func (q *Queries) RevertComment(ctx context.Context, arg RevertCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, revertComment,
+		arg.ID,
+		arg.Content,
+		arg.ContentHtml,
+		arg.ContentHash,
+	)
 	var i Comment
 	err := row.Scan(
 		&i.ID,
@@ -137,16 +5008,42 @@ func (q *Queries) DeleteComment(ctx context.Context, id int64) (Comment, error)
 		&i.Username,
 		&i.PostID,
 		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
-const deletePost = `-- name: DeletePost :one
-DELETE FROM posts WHERE id = $1 RETURNING id, title, content, user_id, username, topic_id, created_at
+const revertPost = `-- name: RevertPost :one
+UPDATE posts SET title = $2, content = $3, content_html = $4, content_hash = $5, status = 'published', flag_reason = NULL, updated_at = now(), edit_count = edit_count + 1, version = version + 1
+WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
 `
 
-func (q *Queries) DeletePost(ctx context.Context, id int64) (Post, error) {
-	row := q.db.QueryRow(ctx, deletePost, id)
+type RevertPostParams struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	ContentHtml string `json:"content_html"`
+	ContentHash string `json:"content_hash"`
+}
+
+// puts back the text of a held post's latest published revision, counting as an edit so the rejected text is kept as a revision
+func (q *Queries) RevertPost(ctx context.Context, arg RevertPostParams) (Post, error) {
+	row := q.db.QueryRow(ctx, revertPost,
+		arg.ID,
+		arg.Title,
+		arg.Content,
+		arg.ContentHtml,
+		arg.ContentHash,
+	)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -156,64 +5053,92 @@ func (q *Queries) DeletePost(ctx context.Context, id int64) (Post, error) {
 		&i.Username,
 		&i.TopicID,
 		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
-const deleteTopic = `-- name: DeleteTopic :one
-DELETE FROM topics WHERE id = $1 RETURNING id, name, description, user_id, username, created_at
+const revokeSanction = `-- name: RevokeSanction :one
+UPDATE user_sanctions SET revoked_at = now(), revoked_by = $2 WHERE id = $1 AND revoked_at IS NULL RETURNING id, user_id, kind, reason, issued_by, expires_at, revoked_at, revoked_by, created_at
 `
 
-func (q *Queries) DeleteTopic(ctx context.Context, id int64) (Topic, error) {
-	row := q.db.QueryRow(ctx, deleteTopic, id)
-	var i Topic
+type RevokeSanctionParams struct {
+	ID        int64       `json:"id"`
+	RevokedBy pgtype.Int8 `json:"revoked_by"`
+}
+
+func (q *Queries) RevokeSanction(ctx context.Context, arg RevokeSanctionParams) (UserSanction, error) {
+	row := q.db.QueryRow(ctx, revokeSanction, arg.ID, arg.RevokedBy)
+	var i UserSanction
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Description,
 		&i.UserID,
-		&i.Username,
+		&i.Kind,
+		&i.Reason,
+		&i.IssuedBy,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.RevokedBy,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const fetchUserByUsername = `-- name: FetchUserByUsername :one
-SELECT id, username, password, created_at FROM users WHERE username = $1
+const searchTags = `-- name: SearchTags :many
+SELECT t.id, t.name, t.topic_id, t.created_at, COUNT(pt.post_id)::bigint AS post_count
+FROM tags t
+LEFT JOIN post_tags pt ON pt.tag_id = t.id
+WHERE t.name LIKE $1::text || '%'
+  AND (t.topic_id IS NULL OR t.topic_id = $2::BIGINT)
+GROUP BY t.id
+ORDER BY post_count DESC, t.name
+LIMIT $3
 `
 
-func (q *Queries) FetchUserByUsername(ctx context.Context, username string) (User, error) {
-	row := q.db.QueryRow(ctx, fetchUserByUsername, username)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Username,
-		&i.Password,
-		&i.CreatedAt,
-	)
-	return i, err
+type SearchTagsParams struct {
+	Prefix   string      `json:"prefix"`
+	TopicID  pgtype.Int8 `json:"topic_id"`
+	RowLimit int32       `json:"row_limit"`
 }
 
-const listComments = `-- name: ListComments :many
-SELECT id, content, user_id, username, post_id, created_at FROM comments WHERE post_id = $1
-`
+type SearchTagsRow struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	TopicID   pgtype.Int8      `json:"topic_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	PostCount int64            `json:"post_count"`
+}
 
-func (q *Queries) ListComments(ctx context.Context, postID int64) ([]Comment, error) {
-	rows, err := q.db.Query(ctx, listComments, postID)
+// tag names never contain LIKE wildcards so the prefix can be matched as is
+func (q *Queries) SearchTags(ctx context.Context, arg SearchTagsParams) ([]SearchTagsRow, error) {
+	rows, err := q.db.Query(ctx, searchTags, arg.Prefix, arg.TopicID, arg.RowLimit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Comment
+	var items []SearchTagsRow
 	for rows.Next() {
-		var i Comment
+		var i SearchTagsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Content,
-			&i.UserID,
-			&i.Username,
-			&i.PostID,
+			&i.Name,
+			&i.TopicID,
 			&i.CreatedAt,
+			&i.PostCount,
 		); err != nil {
 			return nil, err
 		}
@@ -225,80 +5150,133 @@ func (q *Queries) ListComments(ctx context.Context, postID int64) ([]Comment, er
 	return items, nil
 }
 
-const listPosts = `-- name: ListPosts :many
-SELECT id, title, content, user_id, username, topic_id, created_at FROM posts WHERE topic_id = $1
+const setCommentIndex = `-- name: SetCommentIndex :exec
+UPDATE comments SET content_html = $2, content_hash = $3 WHERE id = $1
 `
 
-func (q *Queries) ListPosts(ctx context.Context, topicID int64) ([]Post, error) {
-	rows, err := q.db.Query(ctx, listPosts, topicID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Post
-	for rows.Next() {
-		var i Post
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.Content,
-			&i.UserID,
-			&i.Username,
-			&i.TopicID,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type SetCommentIndexParams struct {
+	ID          int64  `json:"id"`
+	ContentHtml string `json:"content_html"`
+	ContentHash string `json:"content_hash"`
 }
 
-const listTopics = `-- name: ListTopics :many
-SELECT id, name, description, user_id, username, created_at FROM topics
+func (q *Queries) SetCommentIndex(ctx context.Context, arg SetCommentIndexParams) error {
+	_, err := q.db.Exec(ctx, setCommentIndex, arg.ID, arg.ContentHtml, arg.ContentHash)
+	return err
+}
+
+const setPostIndex = `-- name: SetPostIndex :exec
+UPDATE posts SET content_html = $2, content_hash = $3 WHERE id = $1
 `
 
-func (q *Queries) ListTopics(ctx context.Context) ([]Topic, error) {
-	rows, err := q.db.Query(ctx, listTopics)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Topic
-	for rows.Next() {
-		var i Topic
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.Description,
-			&i.UserID,
-			&i.Username,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type SetPostIndexParams struct {
+	ID          int64  `json:"id"`
+	ContentHtml string `json:"content_html"`
+	ContentHash string `json:"content_hash"`
+}
+
+// leaves updated_at alone, rebuilding derived columns is not an edit
+func (q *Queries) SetPostIndex(ctx context.Context, arg SetPostIndexParams) error {
+	_, err := q.db.Exec(ctx, setPostIndex, arg.ID, arg.ContentHtml, arg.ContentHash)
+	return err
+}
+
+const setPostState = `-- name: SetPostState :one
+UPDATE posts SET
+    pinned = $1,
+    locked = $2,
+    archived_at = CASE WHEN $3::BOOLEAN THEN COALESCE(archived_at, now()) ELSE NULL END
+WHERE id = $4 AND deleted_at IS NULL
+RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
+`
+
+type SetPostStateParams struct {
+	Pinned   bool  `json:"pinned"`
+	Locked   bool  `json:"locked"`
+	Archived bool  `json:"archived"`
+	ID       int64 `json:"id"`
+}
+
+// archiving keeps the original archived_at if the post was already archived
+func (q *Queries) SetPostState(ctx context.Context, arg SetPostStateParams) (Post, error) {
+	row := q.db.QueryRow(ctx, setPostState,
+		arg.Pinned,
+		arg.Locked,
+		arg.Archived,
+		arg.ID,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.UserID,
+		&i.Username,
+		&i.TopicID,
+		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
+	)
+	return i, err
+}
+
+const touchPollBallot = `-- name: TouchPollBallot :one
+UPDATE poll_ballots SET updated_at = now() WHERE poll_id = $1 AND user_id = $2 RETURNING poll_id, user_id, created_at, updated_at
+`
+
+type TouchPollBallotParams struct {
+	PollID int64 `json:"poll_id"`
+	UserID int64 `json:"user_id"`
+}
+
+// locks the ballot so two changes from the same user can't interleave
+func (q *Queries) TouchPollBallot(ctx context.Context, arg TouchPollBallotParams) (PollBallot, error) {
+	row := q.db.QueryRow(ctx, touchPollBallot, arg.PollID, arg.UserID)
+	var i PollBallot
+	err := row.Scan(
+		&i.PollID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
 const updateComment = `-- name: UpdateComment :one
-UPDATE comments SET content = $2 WHERE id = $1 RETURNING id, content, user_id, username, post_id, created_at
+UPDATE comments SET content = $2, content_html = $3, status = $4, flag_reason = $5, content_hash = $6, updated_at = now(), edit_count = edit_count + 1, version = version + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING id, content, user_id, username, post_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, version, published_at
 `
 
 type UpdateCommentParams struct {
-	ID      int64  `json:"id"`
-	Content string `json:"content"`
+	ID          int64  `json:"id"`
+	Content     string `json:"content"`
+	ContentHtml string `json:"content_html"`
+	Status      string `json:"status"`
+	FlagReason  string `json:"flag_reason"`
+	ContentHash string `json:"content_hash"`
 }
 
 func (q *Queries) UpdateComment(ctx context.Context, arg UpdateCommentParams) (Comment, error) {
-	row := q.db.QueryRow(ctx, updateComment, arg.ID, arg.Content)
+	row := q.db.QueryRow(ctx, updateComment,
+		arg.ID,
+		arg.Content,
+		arg.ContentHtml,
+		arg.Status,
+		arg.FlagReason,
+		arg.ContentHash,
+	)
 	var i Comment
 	err := row.Scan(
 		&i.ID,
@@ -307,22 +5285,44 @@ func (q *Queries) UpdateComment(ctx context.Context, arg UpdateCommentParams) (C
 		&i.Username,
 		&i.PostID,
 		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Version,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
 const updatePost = `-- name: UpdatePost :one
-UPDATE posts SET title = $2, content = $3 WHERE id = $1 RETURNING id, title, content, user_id, username, topic_id, created_at
+UPDATE posts SET title = $2, content = $3, content_html = $4, status = $5, flag_reason = $6, content_hash = $7, updated_at = now(), edit_count = edit_count + 1, version = version + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING id, title, content, user_id, username, topic_id, created_at, content_html, updated_at, edit_count, deleted_at, deleted_by, status, flag_reason, content_hash, pinned, locked, archived_at, comment_count, last_activity_at, version, published_at
 `
 
 type UpdatePostParams struct {
-	ID      int64  `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	ContentHtml string `json:"content_html"`
+	Status      string `json:"status"`
+	FlagReason  string `json:"flag_reason"`
+	ContentHash string `json:"content_hash"`
 }
 
 func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, error) {
-	row := q.db.QueryRow(ctx, updatePost, arg.ID, arg.Title, arg.Content)
+	row := q.db.QueryRow(ctx, updatePost,
+		arg.ID,
+		arg.Title,
+		arg.Content,
+		arg.ContentHtml,
+		arg.Status,
+		arg.FlagReason,
+		arg.ContentHash,
+	)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -332,12 +5332,27 @@ func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, e
 		&i.Username,
 		&i.TopicID,
 		&i.CreatedAt,
+		&i.ContentHtml,
+		&i.UpdatedAt,
+		&i.EditCount,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.FlagReason,
+		&i.ContentHash,
+		&i.Pinned,
+		&i.Locked,
+		&i.ArchivedAt,
+		&i.CommentCount,
+		&i.LastActivityAt,
+		&i.Version,
+		&i.PublishedAt,
 	)
 	return i, err
 }
 
 const updateTopic = `-- name: UpdateTopic :one
-UPDATE topics SET name = $2, description = $3 WHERE id = $1 RETURNING id, name, description, user_id, username, created_at
+UPDATE topics SET name = $2, description = $3, updated_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL RETURNING id, name, description, user_id, username, created_at, deleted_at, deleted_by, post_count, last_post_at, updated_at, version
 `
 
 type UpdateTopicParams struct {
@@ -356,6 +5371,212 @@ func (q *Queries) UpdateTopic(ctx context.Context, arg UpdateTopicParams) (Topic
 		&i.UserID,
 		&i.Username,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.PostCount,
+		&i.LastPostAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users SET password = $2 WHERE id = $1 RETURNING id, username, password, created_at, role
+`
+
+type UpdateUserPasswordParams struct {
+	ID       int64  `json:"id"`
+	Password string `json:"password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.ID, arg.Password)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users SET role = $2 WHERE id = $1 RETURNING id, username, password, created_at, role
+`
+
+type UpdateUserRoleParams struct {
+	ID   int64  `json:"id"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const upsertAttachmentVariant = `-- name: UpsertAttachmentVariant :one
+INSERT INTO attachment_variants (attachment_id, name, storage_key, content_type, size_bytes, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (attachment_id, name) DO UPDATE SET
+    storage_key = EXCLUDED.storage_key,
+    content_type = EXCLUDED.content_type,
+    size_bytes = EXCLUDED.size_bytes,
+    width = EXCLUDED.width,
+    height = EXCLUDED.height
+RETURNING attachment_id, name, storage_key, content_type, size_bytes, width, height
+`
+
+type UpsertAttachmentVariantParams struct {
+	AttachmentID int64  `json:"attachment_id"`
+	Name         string `json:"name"`
+	StorageKey   string `json:"storage_key"`
+	ContentType  string `json:"content_type"`
+	SizeBytes    int64  `json:"size_bytes"`
+	Width        int32  `json:"width"`
+	Height       int32  `json:"height"`
+}
+
+func (q *Queries) UpsertAttachmentVariant(ctx context.Context, arg UpsertAttachmentVariantParams) (AttachmentVariant, error) {
+	row := q.db.QueryRow(ctx, upsertAttachmentVariant,
+		arg.AttachmentID,
+		arg.Name,
+		arg.StorageKey,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+	)
+	var i AttachmentVariant
+	err := row.Scan(
+		&i.AttachmentID,
+		&i.Name,
+		&i.StorageKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+	)
+	return i, err
+}
+
+const upsertBannedWord = `-- name: UpsertBannedWord :one
+INSERT INTO banned_words (word, action, created_by) VALUES ($1, $2, $3)
+ON CONFLICT (word) DO UPDATE SET action = EXCLUDED.action
+RETURNING id, word, action, created_by, created_at
+`
+
+type UpsertBannedWordParams struct {
+	Word      string      `json:"word"`
+	Action    string      `json:"action"`
+	CreatedBy pgtype.Int8 `json:"created_by"`
+}
+
+func (q *Queries) UpsertBannedWord(ctx context.Context, arg UpsertBannedWordParams) (BannedWord, error) {
+	row := q.db.QueryRow(ctx, upsertBannedWord, arg.Word, arg.Action, arg.CreatedBy)
+	var i BannedWord
+	err := row.Scan(
+		&i.ID,
+		&i.Word,
+		&i.Action,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertBookmark = `-- name: UpsertBookmark :one
+INSERT INTO bookmarks (user_id, target_type, target_id, collection_id) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, target_type, target_id) DO UPDATE SET collection_id = EXCLUDED.collection_id
+RETURNING id, user_id, target_type, target_id, collection_id, created_at
+`
+
+type UpsertBookmarkParams struct {
+	UserID       int64       `json:"user_id"`
+	TargetType   string      `json:"target_type"`
+	TargetID     int64       `json:"target_id"`
+	CollectionID pgtype.Int8 `json:"collection_id"`
+}
+
+func (q *Queries) UpsertBookmark(ctx context.Context, arg UpsertBookmarkParams) (Bookmark, error) {
+	row := q.db.QueryRow(ctx, upsertBookmark,
+		arg.UserID,
+		arg.TargetType,
+		arg.TargetID,
+		arg.CollectionID,
+	)
+	var i Bookmark
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TargetType,
+		&i.TargetID,
+		&i.CollectionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertTag = `-- name: UpsertTag :one
+INSERT INTO tags (name) VALUES ($1)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, name, topic_id, created_at
+`
+
+// the no-op update makes RETURNING hand back tags that already exist
+func (q *Queries) UpsertTag(ctx context.Context, name string) (Tag, error) {
+	row := q.db.QueryRow(ctx, upsertTag, name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TopicID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertTopicMute = `-- name: UpsertTopicMute :one
+INSERT INTO topic_mutes (topic_id, user_id, reason, muted_by, expires_at) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (topic_id, user_id) DO UPDATE
+SET reason = EXCLUDED.reason, muted_by = EXCLUDED.muted_by, expires_at = EXCLUDED.expires_at, created_at = now()
+RETURNING topic_id, user_id, reason, muted_by, expires_at, created_at
+`
+
+type UpsertTopicMuteParams struct {
+	TopicID   int64            `json:"topic_id"`
+	UserID    int64            `json:"user_id"`
+	Reason    string           `json:"reason"`
+	MutedBy   pgtype.Int8      `json:"muted_by"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+}
+
+func (q *Queries) UpsertTopicMute(ctx context.Context, arg UpsertTopicMuteParams) (TopicMute, error) {
+	row := q.db.QueryRow(ctx, upsertTopicMute,
+		arg.TopicID,
+		arg.UserID,
+		arg.Reason,
+		arg.MutedBy,
+		arg.ExpiresAt,
+	)
+	var i TopicMute
+	err := row.Scan(
+		&i.TopicID,
+		&i.UserID,
+		&i.Reason,
+		&i.MutedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
 	)
 	return i, err
 }
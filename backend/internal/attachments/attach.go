@@ -0,0 +1,41 @@
+package attachments
+
+import (
+	"context"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Attach links the user's pending uploads to a post or comment that was just created
+// Pass the transaction's queries so the content and its attachments are saved together
+func Attach(ctx context.Context, qtx *repo.Queries, userID int64, targetType string, targetID int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	attached, err := qtx.AttachUploads(ctx, repo.AttachUploadsParams{
+		TargetType: pgtype.Text{String: targetType, Valid: true},
+		TargetID:   pgtype.Int8{Int64: targetID, Valid: true},
+		Ids:        unique,
+		UserID:     userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// anything missing belongs to someone else, is already in use or was never uploaded
+	if len(attached) != len(unique) {
+		return ErrUnavailable
+	}
+	return nil
+}
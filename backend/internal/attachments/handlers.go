@@ -0,0 +1,191 @@
+package attachments
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	var tooBig *http.MaxBytesError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows), errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "attachment not found", http.StatusNotFound)
+	case errors.Is(err, ErrTooLarge), errors.As(err, &tooBig):
+		http.Error(w, ErrTooLarge.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, ErrUnsupportedType):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, ErrNoFile), errors.Is(err, ErrInvalidTarget):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotReady), errors.Is(err, ErrFailed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function that handles the Upload API
+// expects a multipart form with the file in a part named "file"
+func (h *handler) Upload(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// stream the parts instead of ParseMultipartForm so the file is never spooled to a temp file
+	reader, err := r.MultipartReader()
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "expected a multipart/form-data body", http.StatusBadRequest)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			writeError(w, ErrNoFile)
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.service.Upload(r.Context(), userID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		// images are still being processed, clients poll the status until they are ready
+		status := http.StatusCreated
+		if attachment.Status == StatusProcessing {
+			status = http.StatusAccepted
+		}
+
+		json.Write(w, status, attachment)
+		return
+	}
+}
+
+// Function that handles the Download API
+func (h *handler) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid attachment id", http.StatusBadRequest)
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// ?size=thumb and the like serve a scaled down copy of an image
+	download, body, err := h.service.Open(r.Context(), id, userID, r.URL.Query().Get("size"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer body.Close()
+
+	// images can be shown in the page, anything else is downloaded
+	disposition := "attachment"
+	if strings.HasPrefix(download.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", download.ContentType)
+	header.Set("Content-Length", strconv.FormatInt(download.Size, 10))
+	if cd := mime.FormatMediaType(disposition, map[string]string{"filename": download.Filename}); cd != "" {
+		header.Set("Content-Disposition", cd)
+	} else {
+		header.Set("Content-Disposition", disposition)
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	header.Set("Cache-Control", "private, max-age=86400") // an upload never changes once stored
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, body); err != nil {
+		log.Println(err)
+	}
+}
+
+// Function that handles the Details API
+func (h *handler) Details(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid attachment id", http.StatusBadRequest)
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	details, err := h.service.Details(r.Context(), id, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, details)
+}
+
+// Function that handles the List API
+func (h *handler) List(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TargetType string `json:"target_type"`
+		TargetID   int64  `json:"target_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	attachments, err := h.service.List(r.Context(), data.TargetType, data.TargetID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, attachments)
+}
@@ -0,0 +1,198 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/imageproc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// maxAttempts is how often an image is tried before it is marked as failed
+	maxAttempts = 3
+
+	// claimTimeout is how long a claim holds, after that the worker is taken to have died
+	claimTimeout = 10 * time.Minute
+)
+
+// Processor strips metadata from uploaded images and makes their scaled down variants
+// Work goes through a bounded queue to a fixed number of workers, so uploads return straight away
+// and a burst of them cannot use up every CPU
+type Processor struct {
+	repo    *repo.Queries
+	store   storage.BlobStore
+	limits  imageproc.Limits
+	workers int
+	queue   chan int64
+}
+
+func NewProcessor(repo *repo.Queries, store storage.BlobStore, limits imageproc.Limits, workers int, queueSize int) *Processor {
+	return &Processor{
+		repo:    repo,
+		store:   store,
+		limits:  limits,
+		workers: max(1, workers),
+		queue:   make(chan int64, max(1, queueSize)),
+	}
+}
+
+// Start launches the workers, they stop once ctx is cancelled
+func (p *Processor) Start(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-p.queue:
+					if err := p.process(ctx, id); err != nil {
+						slog.Error("image processing failed", "attachment", id, "error", err)
+					}
+				}
+			}
+		}()
+	}
+}
+
+// Enqueue schedules an attachment for processing without blocking
+// When the queue is full the attachment stays in processing and ResumeStalled picks it up later
+func (p *Processor) Enqueue(id int64) bool {
+	if p == nil {
+		return false
+	}
+
+	select {
+	case p.queue <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// ResumeStalled queues attachments that have been waiting in processing since before cutoff
+func (p *Processor) ResumeStalled(ctx context.Context, cutoff time.Time) error {
+	ids, err := p.repo.ListStalledAttachments(ctx, repo.ListStalledAttachmentsParams{
+		Cutoff:   pgtype.Timestamp{Time: cutoff, Valid: true},
+		RowLimit: int32(cap(p.queue)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if !p.Enqueue(id) {
+			break
+		}
+	}
+	return nil
+}
+
+// process claims the attachment and cleans it, an attempt that fails for a reason other than the image
+// itself is released for ResumeStalled to retry until maxAttempts is reached
+func (p *Processor) process(ctx context.Context, id int64) error {
+	// a stalled attachment may have been queued twice, or by another server, only one of them gets it
+	attachment, err := p.repo.ClaimAttachmentProcessing(ctx, repo.ClaimAttachmentProcessingParams{
+		ID:     id,
+		Cutoff: pgtype.Timestamp{Time: time.Now().UTC().Add(-claimTimeout), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// attempts that took the worker down with them never reached the checks below
+	if attachment.ProcessingAttempts > maxAttempts {
+		return p.fail(ctx, attachment, fmt.Errorf("gave up after %d attempts", maxAttempts))
+	}
+
+	err = p.clean(ctx, attachment)
+	if errors.Is(err, imageproc.ErrUnsupported) || errors.Is(err, imageproc.ErrTooManyPixels) {
+		return p.fail(ctx, attachment, err)
+	}
+	if err != nil && attachment.ProcessingAttempts >= maxAttempts {
+		return p.fail(ctx, attachment, fmt.Errorf("gave up after %d attempts: %w", attachment.ProcessingAttempts, err))
+	}
+	if err != nil {
+		if releaseErr := p.repo.ReleaseAttachmentProcessing(ctx, attachment.ID); releaseErr != nil {
+			slog.Error("failed to release an image after a failed attempt", "attachment", attachment.ID, "error", releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// fail marks the attachment as failed for good
+// The raw upload still has its metadata, so it must not stay around to be served
+func (p *Processor) fail(ctx context.Context, attachment repo.Attachment, cause error) error {
+	if err := p.store.Delete(ctx, attachment.StorageKey); err != nil {
+		return err
+	}
+	return p.repo.FailAttachmentProcessing(ctx, repo.FailAttachmentProcessingParams{
+		ID:              attachment.ID,
+		ProcessingError: cause.Error(),
+	})
+}
+
+// clean replaces the upload with a copy stripped of its metadata and stores the scaled down variants
+func (p *Processor) clean(ctx context.Context, attachment repo.Attachment) error {
+	body, err := p.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+
+	full, variants, err := imageproc.Process(data, p.limits)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range variants {
+		key := attachment.StorageKey + "_" + v.Name
+		if err := p.store.Put(ctx, key, bytes.NewReader(v.Data), int64(len(v.Data)), v.ContentType); err != nil {
+			return err
+		}
+
+		_, err = p.repo.UpsertAttachmentVariant(ctx, repo.UpsertAttachmentVariantParams{
+			AttachmentID: attachment.ID,
+			Name:         v.Name,
+			StorageKey:   key,
+			ContentType:  v.ContentType,
+			SizeBytes:    int64(len(v.Data)),
+			Width:        int32(v.Width),
+			Height:       int32(v.Height),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// replace the upload with the cleaned copy
+	err = p.store.Put(ctx, attachment.StorageKey, bytes.NewReader(full.Data), int64(len(full.Data)), full.ContentType)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.FinishAttachmentProcessing(ctx, repo.FinishAttachmentProcessingParams{
+		ID:          attachment.ID,
+		ContentType: full.ContentType,
+		SizeBytes:   int64(len(full.Data)),
+		Width:       pgtype.Int4{Int32: int32(full.Width), Valid: true},
+		Height:      pgtype.Int4{Int32: int32(full.Height), Valid: true},
+	})
+	return err
+}
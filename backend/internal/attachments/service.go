@@ -0,0 +1,344 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// orphans are removed in batches so one run never holds too many rows in memory
+const orphanBatch = 100
+
+// sniffLen is how much of an upload http.DetectContentType looks at
+const sniffLen = 512
+
+func NewService(repo *repo.Queries, pool db.Pool, store storage.BlobStore, maxBytes int64, images *Processor) Service {
+	return &svc{repo: repo, db: pool, store: store, maxBytes: maxBytes, images: images}
+}
+
+// Upload stores a file and records it as an unattached upload of the user
+func (s *svc) Upload(ctx context.Context, userID int64, filename string, r io.Reader) (repo.Attachment, error) {
+	// only the head is held in memory to sniff the type, the rest streams straight into the store
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return repo.Attachment{}, err
+	}
+	if n == 0 {
+		return repo.Attachment{}, ErrNoFile
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !allowedTypes[mediaType] {
+		return repo.Attachment{}, ErrUnsupportedType
+	}
+
+	// images can't be handed out until their metadata has been stripped
+	status := StatusReady
+	if imageTypes[mediaType] {
+		status = StatusProcessing
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return repo.Attachment{}, err
+	}
+
+	body := &limitedReader{r: io.MultiReader(bytes.NewReader(head), r), remaining: s.maxBytes}
+	if err := s.store.Put(ctx, key, body, -1, contentType); err != nil {
+		// the store may have kept part of the file
+		if delErr := s.store.Delete(ctx, key); delErr != nil {
+			slog.Error("failed to remove blob after a failed upload", "key", key, "error", delErr)
+		}
+		if body.over {
+			return repo.Attachment{}, ErrTooLarge
+		}
+		return repo.Attachment{}, err
+	}
+
+	attachment, err := s.repo.CreateAttachment(ctx, repo.CreateAttachmentParams{
+		StorageKey:  key,
+		UserID:      userID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		SizeBytes:   body.read,
+		Status:      status,
+	})
+	if err != nil {
+		// nothing points at the blob, so don't leave it for the collector
+		if delErr := s.store.Delete(ctx, key); delErr != nil {
+			slog.Error("failed to remove blob after a failed upload", "key", key, "error", delErr)
+		}
+		return repo.Attachment{}, err
+	}
+
+	if status == StatusProcessing && !s.images.Enqueue(attachment.ID) {
+		slog.Warn("image processing queue is full, the upload will be picked up later", "attachment", attachment.ID)
+	}
+
+	return attachment, nil
+}
+
+// Details returns an attachment and its variants, which is how clients follow image processing
+func (s *svc) Details(ctx context.Context, id int64, userID int64) (Details, error) {
+	attachment, err := s.visible(ctx, id, userID)
+	if err != nil {
+		return Details{}, err
+	}
+
+	variants, err := s.repo.ListAttachmentVariants(ctx, attachment.ID)
+	if err != nil {
+		return Details{}, err
+	}
+	if variants == nil {
+		variants = []repo.AttachmentVariant{}
+	}
+
+	return Details{Attachment: attachment, Variants: variants}, nil
+}
+
+// Open returns the content of an attachment, or of one of its variants, the caller has to close the reader
+func (s *svc) Open(ctx context.Context, id int64, userID int64, variant string) (Download, io.ReadCloser, error) {
+	attachment, err := s.visible(ctx, id, userID)
+	if err != nil {
+		return Download{}, nil, err
+	}
+
+	switch attachment.Status {
+	case StatusProcessing:
+		return Download{}, nil, ErrNotReady
+	case StatusFailed:
+		return Download{}, nil, ErrFailed
+	}
+
+	download := Download{Filename: attachment.Filename, ContentType: attachment.ContentType, Size: attachment.SizeBytes}
+	key := attachment.StorageKey
+
+	if variant != "" {
+		v, err := s.repo.GetAttachmentVariant(ctx, repo.GetAttachmentVariantParams{AttachmentID: attachment.ID, Name: variant})
+		if err != nil {
+			return Download{}, nil, err
+		}
+		download.ContentType, download.Size, key = v.ContentType, v.SizeBytes, v.StorageKey
+	}
+	// processing may have converted the image, so make the name match what is served
+	download.Filename = withExtension(download.Filename, download.ContentType)
+
+	body, err := s.store.Get(ctx, key)
+	if err != nil {
+		return Download{}, nil, err
+	}
+
+	return download, body, nil
+}
+
+// visible loads an attachment the user is allowed to see
+// Unattached uploads are only visible to the uploader, attached ones to anyone who can see what they are attached to
+func (s *svc) visible(ctx context.Context, id int64, userID int64) (repo.Attachment, error) {
+	attachment, err := s.repo.GetAttachment(ctx, id)
+	if err != nil {
+		return repo.Attachment{}, err
+	}
+
+	if attachment.UserID != userID {
+		if !attachment.TargetID.Valid {
+			return repo.Attachment{}, pgx.ErrNoRows
+		}
+		if err := s.checkTarget(ctx, attachment.TargetType.String, attachment.TargetID.Int64); err != nil {
+			return repo.Attachment{}, err
+		}
+	}
+
+	return attachment, nil
+}
+
+func (s *svc) List(ctx context.Context, targetType string, targetID int64) ([]repo.Attachment, error) {
+	if err := s.checkTarget(ctx, targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.ListAttachments(ctx, repo.ListAttachmentsParams{
+		TargetType: pgtype.Text{String: targetType, Valid: true},
+		TargetID:   pgtype.Int8{Int64: targetID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if attachments == nil {
+		attachments = []repo.Attachment{}
+	}
+	return attachments, nil
+}
+
+// CollectOrphans removes uploads that were never attached before cutoff, and those whose post or comment was purged
+func (s *svc) CollectOrphans(ctx context.Context, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		orphans, err := s.repo.ListOrphanedAttachments(ctx, repo.ListOrphanedAttachmentsParams{
+			Cutoff:   pgtype.Timestamp{Time: cutoff, Valid: true},
+			RowLimit: orphanBatch,
+		})
+		if err != nil {
+			return total, err
+		}
+
+		for _, a := range orphans {
+			variants, err := s.repo.ListAttachmentVariants(ctx, a.ID)
+			if err != nil {
+				return total, err
+			}
+
+			// drop the row first, a blob without a row is harmless while a row without a blob breaks downloads
+			n, err := s.repo.DeleteOrphanedAttachment(ctx, a.ID)
+			if err != nil {
+				return total, err
+			}
+			if n == 0 {
+				continue
+			}
+
+			keys := []string{a.StorageKey}
+			for _, v := range variants {
+				keys = append(keys, v.StorageKey)
+			}
+			for _, key := range keys {
+				if err := s.store.Delete(ctx, key); err != nil {
+					slog.Error("failed to remove orphaned blob", "key", key, "error", err)
+				}
+			}
+			total++
+		}
+
+		if len(orphans) < orphanBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		slog.Info("removed orphaned uploads", "uploads", total, "cutoff", cutoff)
+	}
+
+	return total, nil
+}
+
+// checkTarget makes sure a post or comment is live and published, along with the post and topic it is under, returning ErrNoRows otherwise
+func (s *svc) checkTarget(ctx context.Context, targetType string, targetID int64) error {
+	switch targetType {
+	case TargetPost:
+		return s.checkPost(ctx, targetID)
+	case TargetComment:
+		comment, err := s.repo.GetComment(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if comment.DeletedAt.Valid || comment.Status != contentfilter.StatusPublished {
+			return pgx.ErrNoRows
+		}
+		return s.checkPost(ctx, comment.PostID)
+	default:
+		return ErrInvalidTarget
+	}
+}
+
+// checkPost makes sure a post is live and published and its topic is not deleted
+func (s *svc) checkPost(ctx context.Context, id int64) error {
+	post, err := s.repo.GetPost(ctx, id)
+	if err != nil {
+		return err
+	}
+	if post.DeletedAt.Valid || post.Status != contentfilter.StatusPublished {
+		return pgx.ErrNoRows
+	}
+
+	topic, err := s.repo.GetTopic(ctx, post.TopicID)
+	if err != nil {
+		return err
+	}
+	if topic.DeletedAt.Valid {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// withExtension swaps the extension of filename for the one matching contentType
+func withExtension(filename string, contentType string) string {
+	ext, ok := extensions[contentType]
+	current := strings.ToLower(filepath.Ext(filename))
+	if !ok || current == ext || (ext == ".jpg" && current == ".jpeg") {
+		return filename
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ext
+}
+
+// newKey picks a random storage key, grouped by day to keep directories small
+func newKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return time.Now().UTC().Format("2006/01/02") + "/" + hex.EncodeToString(b), nil
+}
+
+// cleanFilename keeps the base name the client sent, minus anything that could break a header
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if len(name) > 255 {
+		name = strings.ToValidUTF8(name[:255], "")
+	}
+	return name
+}
+
+// limitedReader fails the read that takes it past remaining bytes, so a store never keeps an oversized file
+// read counts what went through, which is the size of the file once the store has reached EOF
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+	over      bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	// ask for one byte past the limit to tell a file that is exactly at the limit from one that is over
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		l.over = true
+		return 0, ErrTooLarge
+	}
+	l.remaining -= int64(n)
+	l.read += int64(n)
+	return n, err
+}
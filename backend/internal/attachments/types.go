@@ -0,0 +1,100 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/storage"
+)
+
+// values stored in attachments.target_type
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+)
+
+// values stored in attachments.status
+const (
+	StatusProcessing = "processing"
+	StatusReady      = "ready"
+	StatusFailed     = "failed"
+)
+
+// imageTypes are the uploads that go through the image processor before they can be downloaded
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// extensions used to name processed images
+var extensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+}
+
+// allowedTypes are the media types uploads may have, decided by sniffing the content rather than trusting the client
+var allowedTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+var (
+	ErrNoFile          = errors.New("no file was uploaded")
+	ErrTooLarge        = errors.New("the file is too large")
+	ErrUnsupportedType = errors.New("this type of file is not allowed")
+	ErrInvalidTarget   = errors.New("target_type must be either post or comment")
+	ErrUnavailable     = errors.New("attachments must be your own uploads that are not attached to anything yet")
+	ErrNotReady        = errors.New("this image is still being processed")
+	ErrFailed          = errors.New("this image could not be processed")
+)
+
+// Details is an attachment along with the scaled down copies made of it, clients poll it until the status is ready
+type Details struct {
+	repo.Attachment
+	Variants []repo.AttachmentVariant `json:"variants"`
+}
+
+// Download describes the content handed out for an attachment or one of its variants
+type Download struct {
+	Filename    string
+	ContentType string
+	Size        int64
+}
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+
+	// where the uploaded bytes go
+	store storage.BlobStore
+
+	// largest upload accepted, in bytes
+	maxBytes int64
+
+	// processes uploaded images in the background
+	images *Processor
+}
+
+type Service interface {
+	Upload(ctx context.Context, userID int64, filename string, r io.Reader) (repo.Attachment, error)
+	Details(ctx context.Context, id int64, userID int64) (Details, error)
+	Open(ctx context.Context, id int64, userID int64, variant string) (Download, io.ReadCloser, error)
+	List(ctx context.Context, targetType string, targetID int64) ([]repo.Attachment, error)
+	CollectOrphans(ctx context.Context, cutoff time.Time) (int64, error)
+}
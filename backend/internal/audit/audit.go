@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Entry describes a single privileged or destructive action
+type Entry struct {
+	Action     string
+	TargetType string
+	TargetID   int64
+	Details    any // marshalled into the details JSONB column
+	Before     any // the target before the change, nil when it was just created
+	After      any // the target after the change
+}
+
+// Record appends an entry to the audit log
+// The actor and request ID come from ctx, so entries written outside a request have neither
+// Pass the transaction's queries so the entry is only kept if the action itself commits
+func Record(ctx context.Context, qtx *repo.Queries, e Entry) error {
+	details, err := marshal(e.Details)
+	if err != nil {
+		return err
+	}
+	if details == nil {
+		details = []byte("{}")
+	}
+
+	before, err := marshal(e.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := marshal(e.After)
+	if err != nil {
+		return err
+	}
+
+	actorID, ok := ctx.Value(appctx.UserIDKey).(int64)
+
+	return qtx.CreateAuditLogEntry(ctx, repo.CreateAuditLogEntryParams{
+		ActorID:     pgtype.Int8{Int64: actorID, Valid: ok},
+		RequestID:   middleware.GetReqID(ctx),
+		Action:      e.Action,
+		TargetType:  e.TargetType,
+		TargetID:    e.TargetID,
+		Details:     details,
+		BeforeState: before,
+		AfterState:  after,
+	})
+}
+
+// marshal leaves nil values as NULL instead of the JSON null literal
+func marshal(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
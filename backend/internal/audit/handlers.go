@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// Function that handles the ListEntries API
+func (h *handler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Filter
+		Limit  int32 `json:"limit"`
+		Offset int32 `json:"offset"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	entries, err := h.service.ListEntries(r.Context(), data.Filter, data.Limit, data.Offset)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.Write(w, http.StatusOK, entries)
+}
+
+// Function that handles the ExportEntries API
+// Streams every matching entry as CSV, newest first
+func (h *handler) ExportEntries(w http.ResponseWriter, r *http.Request) {
+	var filter Filter
+	if err := json.Read(r, &filter); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "created_at", "actor_id", "request_id", "action", "target_type", "target_id", "details", "before", "after"})
+
+	err := h.service.ExportEntries(r.Context(), filter, func(e LogEntry) error {
+		actorID := ""
+		if e.ActorID.Valid {
+			actorID = strconv.FormatInt(e.ActorID.Int64, 10)
+		}
+
+		return writer.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.Time.Format(time.RFC3339),
+			actorID,
+			e.RequestID,
+			cell(e.Action),
+			cell(e.TargetType),
+			strconv.FormatInt(e.TargetID, 10),
+			cell(string(e.Details)),
+			cell(string(e.Before)),
+			cell(string(e.After)),
+		})
+	})
+	if err != nil {
+		// the status line has most likely been sent already, so all that is left is to cut the file short
+		log.Println(err)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Println(err)
+	}
+}
+
+// cell stops spreadsheet applications from treating free text such as moderator notes as a formula
+func cell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// number of rows fetched per query while exporting
+const exportPageSize = 500
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+func (s *svc) ListEntries(ctx context.Context, filter Filter, limit int32, offset int32) ([]LogEntry, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	rows, err := s.repo.ListAuditLog(ctx, listParams(filter, limit, offset))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LogEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = toLogEntry(row)
+	}
+	return entries, nil
+}
+
+func (s *svc) ExportEntries(ctx context.Context, filter Filter, fn func(LogEntry) error) error {
+	// pin the end of the range so entries written during the export cannot shift the pages
+	if filter.To == nil {
+		now := time.Now().UTC()
+		filter.To = &now
+	}
+
+	for offset := int32(0); ; offset += exportPageSize {
+		rows, err := s.repo.ListAuditLog(ctx, listParams(filter, exportPageSize, offset))
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := fn(toLogEntry(row)); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+func listParams(filter Filter, limit int32, offset int32) repo.ListAuditLogParams {
+	params := repo.ListAuditLogParams{
+		ActorID:    pgtype.Int8{Int64: filter.ActorID, Valid: filter.ActorID != 0},
+		TargetType: pgtype.Text{String: filter.TargetType, Valid: filter.TargetType != ""},
+		TargetID:   pgtype.Int8{Int64: filter.TargetID, Valid: filter.TargetID != 0},
+		RowLimit:   limit,
+		RowOffset:  offset,
+	}
+
+	// created_at is stored without a time zone, in UTC
+	if filter.From != nil {
+		params.CreatedFrom = pgtype.Timestamp{Time: filter.From.UTC(), Valid: true}
+	}
+	if filter.To != nil {
+		params.CreatedTo = pgtype.Timestamp{Time: filter.To.UTC(), Valid: true}
+	}
+
+	return params
+}
+
+func toLogEntry(row repo.AuditLog) LogEntry {
+	return LogEntry{
+		ID:         row.ID,
+		ActorID:    row.ActorID,
+		RequestID:  row.RequestID,
+		Action:     row.Action,
+		TargetType: row.TargetType,
+		TargetID:   row.TargetID,
+		Details:    row.Details,
+		Before:     row.BeforeState,
+		After:      row.AfterState,
+		CreatedAt:  row.CreatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Filter narrows down the audit log, zero values match everything
+// From is inclusive and To is exclusive
+type Filter struct {
+	ActorID    int64      `json:"actor_id"`
+	TargetType string     `json:"target_type"`
+	TargetID   int64      `json:"target_id"`
+	From       *time.Time `json:"from"`
+	To         *time.Time `json:"to"`
+}
+
+// LogEntry is an audit_log row with the JSONB columns kept as raw JSON instead of base64 encoded bytes
+type LogEntry struct {
+	ID         int64            `json:"id"`
+	ActorID    pgtype.Int8      `json:"actor_id"`
+	RequestID  string           `json:"request_id"`
+	Action     string           `json:"action"`
+	TargetType string           `json:"target_type"`
+	TargetID   int64            `json:"target_id"`
+	Details    json.RawMessage  `json:"details"`
+	Before     json.RawMessage  `json:"before"`
+	After      json.RawMessage  `json:"after"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+type Service interface {
+	ListEntries(ctx context.Context, filter Filter, limit int32, offset int32) ([]LogEntry, error)
+	ExportEntries(ctx context.Context, filter Filter, fn func(LogEntry) error) error
+}
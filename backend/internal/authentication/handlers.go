@@ -25,7 +25,7 @@ func (h *handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var createUserParams repo.CreateUserParams
 	if err := json.Read(r, &createUserParams); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
@@ -71,7 +71,7 @@ func (h *handler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.Read(r, &param); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
@@ -85,6 +85,12 @@ func (h *handler) LoginUser(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		var bannedErr *BannedError
+		if errors.As(err, &bannedErr) {
+			http.Error(w, bannedErr.Error(), http.StatusForbidden)
+			return
+		}
+
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
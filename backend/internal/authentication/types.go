@@ -5,6 +5,7 @@ import (
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/sanctions"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -24,7 +25,17 @@ type UserClaims struct {
 	jwt.RegisteredClaims
 }
 
+// BannedError is returned by LoginUser when the account has an active ban
+type BannedError struct {
+	Sanction repo.UserSanction
+}
+
+func (e *BannedError) Error() string {
+	return sanctions.Message(e.Sanction)
+}
+
 type Service interface {
 	CreateUser(ctx context.Context, params repo.CreateUserParams) (repo.User, error)
 	LoginUser(ctx context.Context, username string, password string) (repo.User, error)
+	ResetPassword(ctx context.Context, username string, password string) (repo.User, error)
 }
@@ -0,0 +1,186 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	tarHeaderName    = "header.json"
+	tarRecordsPrefix = "records/"
+)
+
+// recordWriter writes the header of an export and then its records
+type recordWriter interface {
+	WriteHeader(h Header) error
+	Write(rec Record) error
+	Close() error
+}
+
+func newRecordWriter(w io.Writer, archive string) (recordWriter, error) {
+	switch archive {
+	case "", ArchiveJSONL:
+		buf := bufio.NewWriter(w)
+		return &jsonlWriter{buf: buf, enc: json.NewEncoder(buf)}, nil
+	case ArchiveTar:
+		return &tarWriter{tw: tar.NewWriter(w)}, nil
+	default:
+		return nil, ErrUnknownArchive
+	}
+}
+
+type jsonlWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *jsonlWriter) WriteHeader(h Header) error { return w.enc.Encode(h) }
+func (w *jsonlWriter) Write(rec Record) error     { return w.enc.Encode(rec) }
+func (w *jsonlWriter) Close() error               { return w.buf.Flush() }
+
+// tarWriter needs the size of every file up front, so records are held back until a file's worth is ready
+type tarWriter struct {
+	tw      *tar.Writer
+	pending bytes.Buffer
+	records int
+	files   int
+	modTime time.Time
+}
+
+func (w *tarWriter) WriteHeader(h Header) error {
+	w.modTime = h.ExportedAt
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return w.writeFile(tarHeaderName, data)
+}
+
+func (w *tarWriter) Write(rec Record) error {
+	if err := json.NewEncoder(&w.pending).Encode(rec); err != nil {
+		return err
+	}
+	w.records++
+	if w.records == exportBatchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.tw.Close()
+}
+
+func (w *tarWriter) flush() error {
+	if w.records == 0 {
+		return nil
+	}
+	w.files++
+	if err := w.writeFile(fmt.Sprintf("%s%06d.jsonl", tarRecordsPrefix, w.files), w.pending.Bytes()); err != nil {
+		return err
+	}
+	w.pending.Reset()
+	w.records = 0
+	return nil
+}
+
+func (w *tarWriter) writeFile(name string, data []byte) error {
+	err := w.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: w.modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.tw.Write(data)
+	return err
+}
+
+// recordReader returns the records of an export one at a time, and io.EOF once they run out
+type recordReader interface {
+	Next() (Record, error)
+}
+
+// openArchive reads the header of an export, telling JSON Lines and tar archives apart by their first byte
+func openArchive(r io.Reader) (Header, recordReader, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(1)
+	if err != nil {
+		return Header{}, nil, ErrNotAnExport
+	}
+
+	var header Header
+	var rr recordReader
+	if first[0] == '{' {
+		dec := json.NewDecoder(br)
+		if err := dec.Decode(&header); err != nil {
+			return Header{}, nil, ErrNotAnExport
+		}
+		rr = &jsonlReader{dec: dec}
+	} else {
+		tr := tar.NewReader(br)
+		h, err := tr.Next()
+		if err != nil || h.Name != tarHeaderName {
+			return Header{}, nil, ErrNotAnExport
+		}
+		if err := json.NewDecoder(tr).Decode(&header); err != nil {
+			return Header{}, nil, ErrNotAnExport
+		}
+		rr = &tarReader{tr: tr}
+	}
+
+	if header.Format != Format {
+		return Header{}, nil, ErrNotAnExport
+	}
+	if header.Version < MinVersion || header.Version > Version {
+		return Header{}, nil, fmt.Errorf("%w: got %d, this server reads %d to %d", ErrUnsupportedVersion, header.Version, MinVersion, Version)
+	}
+
+	return header, rr, nil
+}
+
+type jsonlReader struct {
+	dec *json.Decoder
+}
+
+func (r *jsonlReader) Next() (Record, error) {
+	if !r.dec.More() {
+		return Record{}, io.EOF
+	}
+	var rec Record
+	err := r.dec.Decode(&rec)
+	return rec, err
+}
+
+// tarReader goes through the record files in the order they were written, other files are ignored
+type tarReader struct {
+	tr  *tar.Reader
+	dec *json.Decoder // the record file being read, nil between files
+}
+
+func (r *tarReader) Next() (Record, error) {
+	for r.dec == nil || !r.dec.More() {
+		h, err := r.tr.Next()
+		if err != nil {
+			return Record{}, err
+		}
+		if strings.HasPrefix(h.Name, tarRecordsPrefix) {
+			r.dec = json.NewDecoder(r.tr)
+		}
+	}
+
+	var rec Record
+	err := r.dec.Decode(&rec)
+	return rec, err
+}
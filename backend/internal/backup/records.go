@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ref points at another record by its kind and exported ID
+type ref struct {
+	kind string
+	id   int64
+}
+
+// entry is a decoded record
+// id is only set for the kinds other records refer to, those are the ones remembered in import_mappings
+type entry struct {
+	kind  string
+	id    int64
+	refs  []ref
+	value any
+}
+
+// decode parses the data of a record and collects the records it refers to
+func decode(rec Record) (entry, error) {
+	e := entry{kind: rec.Kind}
+
+	switch rec.Kind {
+	case KindUser:
+		var u User
+		if err := json.Unmarshal(rec.Data, &u); err != nil {
+			return e, err
+		}
+		e.id, e.value = u.ID, u
+
+	case KindTopic:
+		var t repo.Topic
+		if err := json.Unmarshal(rec.Data, &t); err != nil {
+			return e, err
+		}
+		e.id, e.value = t.ID, t
+		e.refs = withOptional([]ref{{KindUser, t.UserID}}, KindUser, t.DeletedBy)
+
+	case KindTag:
+		var t repo.Tag
+		if err := json.Unmarshal(rec.Data, &t); err != nil {
+			return e, err
+		}
+		e.value = t
+		e.refs = withOptional(nil, KindTopic, t.TopicID)
+
+	case KindPost:
+		var p repo.ExportPostsRow
+		if err := json.Unmarshal(rec.Data, &p); err != nil {
+			return e, err
+		}
+		e.id, e.value = p.ID, p
+		e.refs = withOptional([]ref{{KindUser, p.UserID}, {KindTopic, p.TopicID}}, KindUser, p.DeletedBy)
+
+	case KindPoll:
+		var p Poll
+		if err := json.Unmarshal(rec.Data, &p); err != nil {
+			return e, err
+		}
+		e.value = p
+		e.refs = []ref{{KindPost, p.PostID}}
+
+		// options live in the same record, so votes are checked against them here
+		options := make(map[int64]bool, len(p.Options))
+		for _, o := range p.Options {
+			options[o.ID] = true
+		}
+		for _, b := range p.Ballots {
+			for _, optionID := range b.OptionIds {
+				if !options[optionID] {
+					return e, fmt.Errorf("poll option %d %w", optionID, ErrMissingReference)
+				}
+			}
+			e.refs = append(e.refs, ref{KindUser, b.UserID})
+		}
+
+	case KindComment:
+		var c repo.Comment
+		if err := json.Unmarshal(rec.Data, &c); err != nil {
+			return e, err
+		}
+		e.id, e.value = c.ID, c
+		e.refs = withOptional([]ref{{KindUser, c.UserID}, {KindPost, c.PostID}}, KindUser, c.DeletedBy)
+
+	case KindRevision:
+		var r repo.Revision
+		if err := json.Unmarshal(rec.Data, &r); err != nil {
+			return e, err
+		}
+		if err := checkTargetType(r.TargetType); err != nil {
+			return e, err
+		}
+		e.value = r
+		e.refs = withOptional([]ref{{r.TargetType, r.TargetID}}, KindUser, r.EditedBy)
+
+	case KindFollow:
+		var f repo.UserFollow
+		if err := json.Unmarshal(rec.Data, &f); err != nil {
+			return e, err
+		}
+		e.value = f
+		e.refs = []ref{{KindUser, f.FollowerID}, {KindUser, f.FolloweeID}}
+
+	case KindSubscription:
+		var s repo.TopicSubscription
+		if err := json.Unmarshal(rec.Data, &s); err != nil {
+			return e, err
+		}
+		e.value = s
+		e.refs = []ref{{KindUser, s.UserID}, {KindTopic, s.TopicID}}
+
+	case KindCollection:
+		var c repo.BookmarkCollection
+		if err := json.Unmarshal(rec.Data, &c); err != nil {
+			return e, err
+		}
+		e.id, e.value = c.ID, c
+		e.refs = []ref{{KindUser, c.UserID}}
+
+	case KindBookmark:
+		var b repo.Bookmark
+		if err := json.Unmarshal(rec.Data, &b); err != nil {
+			return e, err
+		}
+		if err := checkTargetType(b.TargetType); err != nil {
+			return e, err
+		}
+		e.value = b
+		e.refs = withOptional([]ref{{KindUser, b.UserID}, {b.TargetType, b.TargetID}}, KindCollection, b.CollectionID)
+
+	default:
+		return e, fmt.Errorf("unknown record kind %q", rec.Kind)
+	}
+
+	return e, nil
+}
+
+// withOptional adds a reference held in a nullable column when it is set
+func withOptional(refs []ref, kind string, id pgtype.Int8) []ref {
+	if id.Valid {
+		refs = append(refs, ref{kind, id.Int64})
+	}
+	return refs
+}
+
+// checkTargetType makes sure revisions and bookmarks point at something that can be exported
+func checkTargetType(targetType string) error {
+	if targetType != KindPost && targetType != KindComment {
+		return fmt.Errorf("unknown target type %q", targetType)
+	}
+	return nil
+}
@@ -0,0 +1,663 @@
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/markdown"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// unusablePassword is stored for imported accounts when the export carries no password hashes
+// bcrypt never matches it, so they have to be given a password with reset-password before logging in
+const unusablePassword = "!"
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+// Export writes every user, topic, post and comment along with their tags, polls, revisions, follows,
+// subscriptions and bookmarks, one kind after the other in the order of Kinds
+// Rows are read in batches so the whole database never has to fit in memory
+// Attachments, notifications and moderation history are not part of an export
+func (s *svc) Export(ctx context.Context, w io.Writer, opts ExportOptions) (Counts, error) {
+	counts := Counts{}
+
+	rw, err := newRecordWriter(w, opts.Archive)
+	if err != nil {
+		return counts, err
+	}
+
+	// every query reads the same snapshot, so rows written during the export can't refer to rows it already passed
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return counts, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return counts, err
+	}
+
+	err = rw.WriteHeader(Header{
+		Format:            Format,
+		Version:           Version,
+		ID:                hex.EncodeToString(id),
+		ExportedAt:        time.Now().UTC(),
+		IncludesPasswords: opts.IncludePasswords,
+	})
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindUser], err = exportByID(rw, KindUser, func(afterID int64) ([]User, error) {
+		rows, err := qtx.ExportUsers(ctx, repo.ExportUsersParams{
+			IncludePasswords: opts.IncludePasswords,
+			AfterID:          afterID,
+			RowLimit:         exportBatchSize,
+		})
+		users := make([]User, len(rows))
+		for i, row := range rows {
+			users[i] = User{ID: row.ID, Username: row.Username, Role: row.Role, CreatedAt: row.CreatedAt, Password: row.Password}
+		}
+		return users, err
+	}, func(u User) int64 { return u.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindTopic], err = exportByID(rw, KindTopic, func(afterID int64) ([]repo.Topic, error) {
+		return qtx.ExportTopics(ctx, repo.ExportTopicsParams{AfterID: afterID, RowLimit: exportBatchSize})
+	}, func(t repo.Topic) int64 { return t.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindTag], err = exportByID(rw, KindTag, func(afterID int64) ([]repo.Tag, error) {
+		return qtx.ExportTags(ctx, repo.ExportTagsParams{AfterID: afterID, RowLimit: exportBatchSize})
+	}, func(t repo.Tag) int64 { return t.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindPost], err = exportByID(rw, KindPost, func(afterID int64) ([]repo.ExportPostsRow, error) {
+		return qtx.ExportPosts(ctx, repo.ExportPostsParams{AfterID: afterID, RowLimit: exportBatchSize})
+	}, func(p repo.ExportPostsRow) int64 { return p.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindPoll], err = exportByID(rw, KindPoll, func(afterID int64) ([]Poll, error) {
+		rows, err := qtx.ExportPolls(ctx, repo.ExportPollsParams{AfterID: afterID, RowLimit: exportBatchSize})
+		if err != nil {
+			return nil, err
+		}
+		polls := make([]Poll, len(rows))
+		for i, row := range rows {
+			polls[i].Poll = row
+			if polls[i].Options, err = qtx.ListPollOptions(ctx, row.ID); err != nil {
+				return nil, err
+			}
+			if polls[i].Ballots, err = qtx.ExportPollBallots(ctx, row.ID); err != nil {
+				return nil, err
+			}
+		}
+		return polls, nil
+	}, func(p Poll) int64 { return p.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindComment], err = exportByID(rw, KindComment, func(afterID int64) ([]repo.Comment, error) {
+		return qtx.ExportComments(ctx, repo.ExportCommentsParams{AfterID: afterID, RowLimit: exportBatchSize})
+	}, func(c repo.Comment) int64 { return c.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindRevision], err = exportByID(rw, KindRevision, func(afterID int64) ([]repo.Revision, error) {
+		return qtx.ExportRevisions(ctx, repo.ExportRevisionsParams{AfterID: afterID, RowLimit: exportBatchSize})
+	}, func(r repo.Revision) int64 { return r.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	// follows and subscriptions have no ID of their own, so they are paged by their primary key
+	var lastFollow repo.UserFollow
+	for {
+		rows, err := qtx.ExportUserFollows(ctx, repo.ExportUserFollowsParams{
+			AfterFollowerID: lastFollow.FollowerID,
+			AfterFolloweeID: lastFollow.FolloweeID,
+			RowLimit:        exportBatchSize,
+		})
+		if err != nil {
+			return counts, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		if err := writeRecords(rw, KindFollow, rows); err != nil {
+			return counts, err
+		}
+		counts[KindFollow] += int64(len(rows))
+		lastFollow = rows[len(rows)-1]
+	}
+
+	var lastSubscription repo.TopicSubscription
+	for {
+		rows, err := qtx.ExportTopicSubscriptions(ctx, repo.ExportTopicSubscriptionsParams{
+			AfterUserID:  lastSubscription.UserID,
+			AfterTopicID: lastSubscription.TopicID,
+			RowLimit:     exportBatchSize,
+		})
+		if err != nil {
+			return counts, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		if err := writeRecords(rw, KindSubscription, rows); err != nil {
+			return counts, err
+		}
+		counts[KindSubscription] += int64(len(rows))
+		lastSubscription = rows[len(rows)-1]
+	}
+
+	counts[KindCollection], err = exportByID(rw, KindCollection, func(afterID int64) ([]repo.BookmarkCollection, error) {
+		return qtx.ExportBookmarkCollections(ctx, repo.ExportBookmarkCollectionsParams{AfterID: afterID, RowLimit: exportBatchSize})
+	}, func(c repo.BookmarkCollection) int64 { return c.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	counts[KindBookmark], err = exportByID(rw, KindBookmark, func(afterID int64) ([]repo.Bookmark, error) {
+		return qtx.ExportBookmarks(ctx, repo.ExportBookmarksParams{AfterID: afterID, RowLimit: exportBatchSize})
+	}, func(b repo.Bookmark) int64 { return b.ID })
+	if err != nil {
+		return counts, err
+	}
+
+	return counts, rw.Close()
+}
+
+// exportByID pages through a table by ID and writes every row as a record of the given kind
+func exportByID[T any](rw recordWriter, kind string, fetch func(afterID int64) ([]T, error), id func(T) int64) (int64, error) {
+	var afterID, total int64
+	for {
+		rows, err := fetch(afterID)
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		if err := writeRecords(rw, kind, rows); err != nil {
+			return total, err
+		}
+		total += int64(len(rows))
+		afterID = id(rows[len(rows)-1])
+	}
+}
+
+func writeRecords[T any](rw recordWriter, kind string, rows []T) error {
+	for _, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if err := rw.Write(Record{Kind: kind, Data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate reads a whole export without writing anything, checking that every record parses
+// and that everything it refers to appears earlier in the same export
+func (s *svc) Validate(ctx context.Context, r io.Reader) (Counts, error) {
+	counts := Counts{}
+
+	_, rr, err := openArchive(r)
+	if err != nil {
+		return counts, err
+	}
+
+	// exported IDs seen so far, by kind
+	seen := map[string]map[int64]bool{}
+	for n := 1; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return counts, err
+		}
+
+		rec, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			return counts, nil
+		}
+		if err != nil {
+			return counts, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		e, err := decode(rec)
+		if err != nil {
+			return counts, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		for _, ref := range e.refs {
+			if !seen[ref.kind][ref.id] {
+				return counts, fmt.Errorf("record %d (%s): %s %d %w", n, e.kind, ref.kind, ref.id, ErrMissingReference)
+			}
+		}
+
+		if e.id != 0 {
+			if seen[e.kind] == nil {
+				seen[e.kind] = map[int64]bool{}
+			}
+			if seen[e.kind][e.id] {
+				return counts, fmt.Errorf("record %d: %s %d appears more than once", n, e.kind, e.id)
+			}
+			seen[e.kind][e.id] = true
+		}
+
+		counts[e.kind]++
+	}
+}
+
+// Import recreates the records of an export under new IDs, keeping their authors and timestamps
+// Records are imported in batches of their own transaction, and each row the export refers to is remembered in
+// import_mappings, so an import that failed halfway can be run again and carries on where it stopped
+// Topics, tags and bookmark collections that already exist under the same name are reused as they are
+// A user whose username is taken is imported as imported-<name> and listed in the report, or with opts.MergeUsers
+// put on the local account, which then takes over the exported content
+func (s *svc) Import(ctx context.Context, r io.Reader, opts ImportOptions) (Report, error) {
+	report := Report{Imported: Counts{}, Skipped: Counts{}}
+
+	header, rr, err := openArchive(r)
+	if err != nil {
+		return report, err
+	}
+
+	// version 1 exports had no ID, the time they were taken tells them apart just as well
+	source := header.ID
+	if source == "" {
+		source = header.ExportedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	imp := importer{source: source, passwords: header.IncludesPasswords, mergeUsers: opts.MergeUsers}
+	for n, done := 1, false; !done; {
+		batch := Report{Imported: Counts{}, Skipped: Counts{}}
+
+		tx, err := s.db.Begin(ctx)
+		if err != nil {
+			return report, err
+		}
+		imp.qtx = s.repo.WithTx(tx)
+
+		for i := 0; i < importBatchSize; i, n = i+1, n+1 {
+			rec, err := rr.Next()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+			if err != nil {
+				tx.Rollback(ctx)
+				return report, fmt.Errorf("record %d: %w", n, err)
+			}
+
+			e, err := decode(rec)
+			if err != nil {
+				tx.Rollback(ctx)
+				return report, fmt.Errorf("record %d: %w", n, err)
+			}
+
+			merged := len(imp.merged)
+			imported, err := imp.importEntry(ctx, e)
+			if err != nil {
+				tx.Rollback(ctx)
+				return report, fmt.Errorf("record %d (%s): %w", n, e.kind, err)
+			}
+
+			switch {
+			case len(imp.merged) > merged:
+				// reported by name below
+			case imported:
+				batch.Imported[e.kind]++
+			default:
+				batch.Skipped[e.kind]++
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return report, err
+		}
+
+		report.MergedUsers = append(report.MergedUsers, imp.merged...)
+		report.RenamedUsers = append(report.RenamedUsers, imp.renamed...)
+		imp.merged, imp.renamed = nil, nil
+
+		for kind, c := range batch.Imported {
+			report.Imported[kind] += c
+		}
+		for kind, c := range batch.Skipped {
+			report.Skipped[kind] += c
+		}
+	}
+
+	return report, nil
+}
+
+// importer writes the records of one export through the queries of the current batch
+type importer struct {
+	qtx        *repo.Queries
+	source     string
+	passwords  bool
+	mergeUsers bool
+	merged     []string      // usernames of the current batch that were reused from the local accounts
+	renamed    []RenamedUser // users of the current batch imported under a new name
+}
+
+// importEntry inserts a single record and reports false when it was already imported
+func (imp *importer) importEntry(ctx context.Context, e entry) (bool, error) {
+	if e.id != 0 {
+		_, err := imp.qtx.GetImportMapping(ctx, repo.GetImportMappingParams{Source: imp.source, Kind: e.kind, OldID: e.id})
+		if err == nil {
+			return false, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return false, err
+		}
+	}
+
+	// new IDs of what the record refers to, in the order of e.refs
+	ids := make([]int64, len(e.refs))
+	for i, ref := range e.refs {
+		id, err := imp.lookup(ctx, ref)
+		if err != nil {
+			return false, err
+		}
+		ids[i] = id
+	}
+
+	newID, imported, err := imp.insert(ctx, e, ids)
+	if err != nil || !imported {
+		return imported, err
+	}
+
+	if e.id != 0 {
+		err := imp.qtx.CreateImportMapping(ctx, repo.CreateImportMappingParams{Source: imp.source, Kind: e.kind, OldID: e.id, NewID: newID})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// freeUsername returns the first of imported-<name>, imported-<name>-2 and so on that no account has
+func (imp *importer) freeUsername(ctx context.Context, name string) (string, error) {
+	for n := 1; ; n++ {
+		candidate := "imported-" + name
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", candidate, n)
+		}
+		_, err := imp.qtx.FetchUserByUsername(ctx, candidate)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// lookup returns the ID a referenced record was imported as
+func (imp *importer) lookup(ctx context.Context, r ref) (int64, error) {
+	id, err := imp.qtx.GetImportMapping(ctx, repo.GetImportMappingParams{Source: imp.source, Kind: r.kind, OldID: r.id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%s %d %w", r.kind, r.id, ErrMissingReference)
+	}
+	return id, err
+}
+
+// insert writes the row of a record, ids holds the new IDs of its references in the order decode listed them
+func (imp *importer) insert(ctx context.Context, e entry, ids []int64) (int64, bool, error) {
+	switch v := e.value.(type) {
+	case User:
+		password := unusablePassword
+		if imp.passwords && v.Password != "" {
+			password = v.Password
+		}
+		username := v.Username
+		existing, err := imp.qtx.FetchUserByUsername(ctx, username)
+		switch {
+		case err == nil && imp.mergeUsers:
+			// the export's content ends up under the local account, which whoever runs the import has to hear about
+			imp.merged = append(imp.merged, username)
+			return existing.ID, true, nil
+		case err == nil:
+			// someone else's account never takes over the exported content unless asked to
+			if username, err = imp.freeUsername(ctx, username); err != nil {
+				return 0, false, err
+			}
+			imp.renamed = append(imp.renamed, RenamedUser{From: v.Username, To: username})
+		case !errors.Is(err, pgx.ErrNoRows):
+			return 0, false, err
+		}
+
+		user, err := imp.qtx.ImportUser(ctx, repo.ImportUserParams{
+			Username:  username,
+			Password:  password,
+			Role:      v.Role,
+			CreatedAt: v.CreatedAt,
+		})
+		return user.ID, err == nil, err
+
+	case repo.Topic:
+		topic, err := imp.qtx.ImportTopic(ctx, repo.ImportTopicParams{
+			Name:        v.Name,
+			Description: v.Description,
+			UserID:      ids[0],
+			Username:    v.Username,
+			CreatedAt:   v.CreatedAt,
+			DeletedAt:   v.DeletedAt,
+			DeletedBy:   optionalID(v.DeletedBy, ids, 1),
+		})
+		return topic.ID, err == nil, err
+
+	case repo.Tag:
+		tag, err := imp.qtx.ImportTag(ctx, repo.ImportTagParams{
+			Name:      v.Name,
+			TopicID:   optionalID(v.TopicID, ids, 0),
+			CreatedAt: v.CreatedAt,
+		})
+		return tag.ID, err == nil, err
+
+	case repo.ExportPostsRow:
+		// the HTML is rendered again rather than trusted from the file, which anyone could have edited
+		contentHtml, err := markdown.Render(v.Content)
+		if err != nil {
+			return 0, false, err
+		}
+		post, err := imp.qtx.ImportPost(ctx, repo.ImportPostParams{
+			Title:       v.Title,
+			Content:     v.Content,
+			ContentHtml: contentHtml,
+			UserID:      ids[0],
+			Username:    v.Username,
+			TopicID:     ids[1],
+			CreatedAt:   v.CreatedAt,
+			UpdatedAt:   v.UpdatedAt,
+			EditCount:   v.EditCount,
+			DeletedAt:   v.DeletedAt,
+			DeletedBy:   optionalID(v.DeletedBy, ids, 2),
+			Status:      v.Status,
+			FlagReason:  v.FlagReason,
+			ContentHash: v.ContentHash,
+			Pinned:      v.Pinned,
+			Locked:      v.Locked,
+			ArchivedAt:  v.ArchivedAt,
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		for _, name := range v.Tags {
+			tag, err := imp.qtx.UpsertTag(ctx, name)
+			if err != nil {
+				return 0, false, err
+			}
+			if err := imp.qtx.AddPostTag(ctx, repo.AddPostTagParams{PostID: post.ID, TagID: tag.ID}); err != nil {
+				return 0, false, err
+			}
+		}
+		return post.ID, true, nil
+
+	case Poll:
+		return imp.insertPoll(ctx, v, ids)
+
+	case repo.Comment:
+		contentHtml, err := markdown.Render(v.Content)
+		if err != nil {
+			return 0, false, err
+		}
+		comment, err := imp.qtx.ImportComment(ctx, repo.ImportCommentParams{
+			Content:     v.Content,
+			ContentHtml: contentHtml,
+			UserID:      ids[0],
+			Username:    v.Username,
+			PostID:      ids[1],
+			CreatedAt:   v.CreatedAt,
+			UpdatedAt:   v.UpdatedAt,
+			EditCount:   v.EditCount,
+			DeletedAt:   v.DeletedAt,
+			DeletedBy:   optionalID(v.DeletedBy, ids, 2),
+			Status:      v.Status,
+			FlagReason:  v.FlagReason,
+			ContentHash: v.ContentHash,
+		})
+		return comment.ID, err == nil, err
+
+	case repo.Revision:
+		// archives written before revisions had a status hold published text
+		status := v.Status
+		if status == "" {
+			status = contentfilter.StatusPublished
+		}
+		n, err := imp.qtx.ImportRevision(ctx, repo.ImportRevisionParams{
+			TargetType: v.TargetType,
+			TargetID:   ids[0],
+			Revision:   v.Revision,
+			Title:      v.Title,
+			Content:    v.Content,
+			EditedBy:   optionalID(v.EditedBy, ids, 1),
+			CreatedAt:  v.CreatedAt,
+			Status:     status,
+		})
+		return 0, n > 0, err
+
+	case repo.UserFollow:
+		n, err := imp.qtx.ImportUserFollow(ctx, repo.ImportUserFollowParams{
+			FollowerID: ids[0],
+			FolloweeID: ids[1],
+			CreatedAt:  v.CreatedAt,
+		})
+		return 0, n > 0, err
+
+	case repo.TopicSubscription:
+		n, err := imp.qtx.ImportTopicSubscription(ctx, repo.ImportTopicSubscriptionParams{
+			UserID:    ids[0],
+			TopicID:   ids[1],
+			CreatedAt: v.CreatedAt,
+		})
+		return 0, n > 0, err
+
+	case repo.BookmarkCollection:
+		collection, err := imp.qtx.ImportBookmarkCollection(ctx, repo.ImportBookmarkCollectionParams{
+			UserID:    ids[0],
+			Name:      v.Name,
+			CreatedAt: v.CreatedAt,
+		})
+		return collection.ID, err == nil, err
+
+	case repo.Bookmark:
+		n, err := imp.qtx.ImportBookmark(ctx, repo.ImportBookmarkParams{
+			UserID:       ids[0],
+			TargetType:   v.TargetType,
+			TargetID:     ids[1],
+			CollectionID: optionalID(v.CollectionID, ids, 2),
+			CreatedAt:    v.CreatedAt,
+		})
+		return 0, n > 0, err
+	}
+
+	return 0, false, fmt.Errorf("unknown record kind %q", e.kind)
+}
+
+// insertPoll creates the poll with its options and ballots, ids holds the new post ID followed by one user ID per ballot
+// A post only ever has one poll, so finding one already there means it was imported before
+func (imp *importer) insertPoll(ctx context.Context, p Poll, ids []int64) (int64, bool, error) {
+	poll, err := imp.qtx.ImportPoll(ctx, repo.ImportPollParams{
+		PostID:         ids[0],
+		Question:       p.Question,
+		MultipleChoice: p.MultipleChoice,
+		HideResults:    p.HideResults,
+		ClosesAt:       p.ClosesAt,
+		CreatedAt:      p.CreatedAt,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	options := make(map[int64]int64, len(p.Options))
+	for _, o := range p.Options {
+		option, err := imp.qtx.CreatePollOption(ctx, repo.CreatePollOptionParams{PollID: poll.ID, Position: o.Position, Label: o.Label})
+		if err != nil {
+			return 0, false, err
+		}
+		options[o.ID] = option.ID
+	}
+
+	for i, b := range p.Ballots {
+		userID := ids[1+i]
+		err := imp.qtx.ImportPollBallot(ctx, repo.ImportPollBallotParams{
+			PollID:    poll.ID,
+			UserID:    userID,
+			CreatedAt: b.CreatedAt,
+			UpdatedAt: b.UpdatedAt,
+		})
+		if err != nil {
+			return 0, false, err
+		}
+
+		for _, optionID := range b.OptionIds {
+			err := imp.qtx.CreatePollVote(ctx, repo.CreatePollVoteParams{PollID: poll.ID, UserID: userID, OptionID: options[optionID]})
+			if err != nil {
+				return 0, false, err
+			}
+		}
+	}
+
+	return poll.ID, true, nil
+}
+
+// optionalID maps a nullable reference to its new ID, which decode listed at position i of ids when it was set
+func optionalID(old pgtype.Int8, ids []int64, i int) pgtype.Int8 {
+	if !old.Valid {
+		return old
+	}
+	return pgtype.Int8{Int64: ids[i], Valid: true}
+}
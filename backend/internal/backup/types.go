@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Format and Version identify an export, they are written in its header
+// Version 1 only held users, topics, posts and comments, it can still be imported
+const (
+	Format     = "gossip-with-go"
+	Version    = 2
+	MinVersion = 1
+)
+
+// kinds of records
+const (
+	KindUser         = "user"
+	KindTopic        = "topic"
+	KindTag          = "tag"
+	KindPost         = "post"
+	KindPoll         = "poll"
+	KindComment      = "comment"
+	KindRevision     = "revision"
+	KindFollow       = "follow"
+	KindSubscription = "subscription"
+	KindCollection   = "collection"
+	KindBookmark     = "bookmark"
+)
+
+// Kinds lists every kind in the order an export writes them, so a record only ever refers to records before it
+var Kinds = []string{
+	KindUser, KindTopic, KindTag, KindPost, KindPoll, KindComment,
+	KindRevision, KindFollow, KindSubscription, KindCollection, KindBookmark,
+}
+
+// archive layouts an export can be written in
+const (
+	// ArchiveJSONL is the header on the first line followed by one record per line
+	ArchiveJSONL = "jsonl"
+	// ArchiveTar holds header.json followed by the records split over records/000001.jsonl, records/000002.jsonl...
+	ArchiveTar = "tar"
+)
+
+const (
+	// rows read per query while exporting, and records per file of a tar archive
+	exportBatchSize = 500
+	// records imported per transaction
+	importBatchSize = 500
+)
+
+var (
+	ErrNotAnExport        = errors.New("input is not a gossip-with-go export")
+	ErrUnsupportedVersion = errors.New("export version is not supported")
+	ErrUnknownArchive     = errors.New("archive must be jsonl or tar")
+	ErrMissingReference   = errors.New("refers to a record that is not in the export")
+)
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+// ExportOptions changes what an export holds and how it is laid out
+type ExportOptions struct {
+	// IncludePasswords adds the bcrypt hashes, so accounts can log in straight after an import
+	IncludePasswords bool
+	// Archive is ArchiveJSONL or ArchiveTar, empty means ArchiveJSONL
+	Archive string
+}
+
+// ImportOptions changes how an export is imported
+type ImportOptions struct {
+	// MergeUsers puts the content of an exported user on the local account with the same username,
+	// without it the user is imported under a new name
+	MergeUsers bool
+}
+
+// Header describes an export
+// ID tells exports apart, importing records from the same export twice only creates them once
+type Header struct {
+	Format            string    `json:"format"`
+	Version           int       `json:"version"`
+	ID                string    `json:"id"`
+	ExportedAt        time.Time `json:"exported_at"`
+	IncludesPasswords bool      `json:"includes_passwords"`
+}
+
+// Record is a single exported row, Data is one of the record types below or a repo model matching Kind
+type Record struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// User is the data of a user record, Password is only set when the export includes passwords
+type User struct {
+	ID        int64            `json:"id"`
+	Username  string           `json:"username"`
+	Role      string           `json:"role"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	Password  string           `json:"password,omitempty"`
+}
+
+// Poll is the data of a poll record, the poll along with its options and everyone's votes
+type Poll struct {
+	repo.Poll
+	Options []repo.PollOption           `json:"options"`
+	Ballots []repo.ExportPollBallotsRow `json:"ballots"`
+}
+
+// Counts is a number of records by kind
+type Counts map[string]int64
+
+// Report is the outcome of an import
+// Skipped records were imported by an earlier run from the same export
+// RenamedUsers are the exported users imported under a new name since theirs was taken by a local account
+// MergedUsers are the exported users put on a local account with the same username, which only ImportOptions.MergeUsers does
+type Report struct {
+	Imported     Counts
+	Skipped      Counts
+	RenamedUsers []RenamedUser
+	MergedUsers  []string
+}
+
+// RenamedUser is an exported user that was imported under another username
+type RenamedUser struct {
+	From string
+	To   string
+}
+
+type Service interface {
+	Export(ctx context.Context, w io.Writer, opts ExportOptions) (Counts, error)
+	Validate(ctx context.Context, r io.Reader) (Counts, error)
+	Import(ctx context.Context, r io.Reader, opts ImportOptions) (Report, error)
+}
@@ -0,0 +1,34 @@
+package bookmarks
+
+import (
+	"context"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+)
+
+// cachedService invalidates the user's cached listings, which mark what they saved
+type cachedService struct {
+	Service
+	cache *cache.Cache
+}
+
+func NewCachedService(service Service, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c}
+}
+
+func (s *cachedService) Save(ctx context.Context, params repo.UpsertBookmarkParams) (repo.Bookmark, error) {
+	bookmark, err := s.Service.Save(ctx, params)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Bookmarks(params.UserID))
+	}
+	return bookmark, err
+}
+
+func (s *cachedService) Unsave(ctx context.Context, params repo.DeleteBookmarkParams) (repo.Bookmark, error) {
+	bookmark, err := s.Service.Unsave(ctx, params)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Bookmarks(params.UserID))
+	}
+	return bookmark, err
+}
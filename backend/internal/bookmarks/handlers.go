@@ -0,0 +1,228 @@
+package bookmarks
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// writeError maps service errors onto the matching status code
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrInvalidTarget), errors.Is(err, ErrNameRequired):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrCollectionExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function that handles the Save API
+func (h *handler) Save(w http.ResponseWriter, r *http.Request) {
+	var saveParams repo.UpsertBookmarkParams
+	if err := json.Read(r, &saveParams); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	saveParams.UserID = userID
+
+	bookmark, err := h.service.Save(r.Context(), saveParams)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, bookmark)
+}
+
+// Function that handles the Unsave API
+func (h *handler) Unsave(w http.ResponseWriter, r *http.Request) {
+	var unsaveParams repo.DeleteBookmarkParams
+	if err := json.Read(r, &unsaveParams); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	unsaveParams.UserID = userID
+
+	bookmark, err := h.service.Unsave(r.Context(), unsaveParams)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, bookmark)
+}
+
+// Function that handles the ListSaved API
+// Lists saved items newest first, optionally only those in one collection
+func (h *handler) ListSaved(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		CollectionID pgtype.Int8 `json:"collection_id"`
+		Limit        int32       `json:"limit"`
+		Offset       int32       `json:"offset"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	saved, err := h.service.ListSaved(r.Context(), repo.ListBookmarksParams{
+		UserID:       userID,
+		CollectionID: data.CollectionID,
+		RowLimit:     data.Limit,
+		RowOffset:    data.Offset,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, saved)
+}
+
+// Function that handles the CreateCollection API
+func (h *handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	var createCollectionParams repo.CreateBookmarkCollectionParams
+	if err := json.Read(r, &createCollectionParams); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	createCollectionParams.UserID = userID
+
+	collection, err := h.service.CreateCollection(r.Context(), createCollectionParams)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, collection)
+}
+
+// Function that handles the ListCollections API
+func (h *handler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	collections, err := h.service.ListCollections(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, collections)
+}
+
+// Function that handles the RenameCollection API
+func (h *handler) RenameCollection(w http.ResponseWriter, r *http.Request) {
+	var renameCollectionParams repo.RenameBookmarkCollectionParams
+	if err := json.Read(r, &renameCollectionParams); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	renameCollectionParams.UserID = userID
+
+	collection, err := h.service.RenameCollection(r.Context(), renameCollectionParams)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, collection)
+}
+
+// Function that handles the DeleteCollection API
+func (h *handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	collection, err := h.service.DeleteCollection(r.Context(), data.ID, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, collection)
+}
@@ -0,0 +1,135 @@
+package bookmarks
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+// Save bookmarks a post or comment, saving it again moves it to the given collection
+func (s *svc) Save(ctx context.Context, params repo.UpsertBookmarkParams) (repo.Bookmark, error) {
+	// only live, published content can be saved, hidden content is reported like missing content
+	switch params.TargetType {
+	case TargetPost:
+		if err := s.checkPost(ctx, params.TargetID); err != nil {
+			return repo.Bookmark{}, err
+		}
+	case TargetComment:
+		comment, err := s.repo.GetComment(ctx, params.TargetID)
+		if err != nil {
+			return repo.Bookmark{}, err
+		}
+		if comment.DeletedAt.Valid || comment.Status != contentfilter.StatusPublished {
+			return repo.Bookmark{}, pgx.ErrNoRows
+		}
+		if err := s.checkPost(ctx, comment.PostID); err != nil {
+			return repo.Bookmark{}, err
+		}
+	default:
+		return repo.Bookmark{}, ErrInvalidTarget
+	}
+
+	// the collection has to belong to the same user
+	if params.CollectionID.Valid {
+		_, err := s.repo.GetBookmarkCollection(ctx, repo.GetBookmarkCollectionParams{
+			ID:     params.CollectionID.Int64,
+			UserID: params.UserID,
+		})
+		if err != nil {
+			return repo.Bookmark{}, err
+		}
+	}
+
+	return s.repo.UpsertBookmark(ctx, params)
+}
+
+// checkPost makes sure the post is published and neither it nor its topic is deleted
+func (s *svc) checkPost(ctx context.Context, id int64) error {
+	post, err := s.repo.GetPost(ctx, id)
+	if err != nil {
+		return err
+	}
+	if post.DeletedAt.Valid || post.Status != contentfilter.StatusPublished {
+		return pgx.ErrNoRows
+	}
+
+	topic, err := s.repo.GetTopic(ctx, post.TopicID)
+	if err != nil {
+		return err
+	}
+	if topic.DeletedAt.Valid {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (s *svc) Unsave(ctx context.Context, params repo.DeleteBookmarkParams) (repo.Bookmark, error) {
+	return s.repo.DeleteBookmark(ctx, params)
+}
+
+func (s *svc) ListSaved(ctx context.Context, params repo.ListBookmarksParams) ([]repo.ListBookmarksRow, error) {
+	if params.RowLimit <= 0 || params.RowLimit > 100 {
+		params.RowLimit = 25
+	}
+	return s.repo.ListBookmarks(ctx, params)
+}
+
+func (s *svc) CreateCollection(ctx context.Context, params repo.CreateBookmarkCollectionParams) (repo.BookmarkCollection, error) {
+	// validate the params
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return repo.BookmarkCollection{}, ErrNameRequired
+	}
+
+	collection, err := s.repo.CreateBookmarkCollection(ctx, params)
+	if err != nil {
+		return repo.BookmarkCollection{}, collectionError(err)
+	}
+	return collection, nil
+}
+
+func (s *svc) ListCollections(ctx context.Context, userID int64) ([]repo.BookmarkCollection, error) {
+	return s.repo.ListBookmarkCollections(ctx, userID)
+}
+
+func (s *svc) RenameCollection(ctx context.Context, params repo.RenameBookmarkCollectionParams) (repo.BookmarkCollection, error) {
+	// validate the params
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return repo.BookmarkCollection{}, ErrNameRequired
+	}
+
+	collection, err := s.repo.RenameBookmarkCollection(ctx, params)
+	if err != nil {
+		return repo.BookmarkCollection{}, collectionError(err)
+	}
+	return collection, nil
+}
+
+// DeleteCollection removes the collection, the items in it stay saved
+func (s *svc) DeleteCollection(ctx context.Context, id int64, userID int64) (repo.BookmarkCollection, error) {
+	return s.repo.DeleteBookmarkCollection(ctx, repo.DeleteBookmarkCollectionParams{
+		ID:     id,
+		UserID: userID,
+	})
+}
+
+// collectionError turns a clash on the (user_id, name) constraint into ErrCollectionExists
+func collectionError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return ErrCollectionExists
+	}
+	return err
+}
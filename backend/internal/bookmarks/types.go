@@ -0,0 +1,41 @@
+package bookmarks
+
+import (
+	"context"
+	"errors"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+// values stored in bookmarks.target_type
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+)
+
+var (
+	ErrInvalidTarget    = errors.New("target_type must be either post or comment")
+	ErrNameRequired     = errors.New("name is required")
+	ErrCollectionExists = errors.New("you already have a collection with this name")
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+type Service interface {
+	Save(ctx context.Context, params repo.UpsertBookmarkParams) (repo.Bookmark, error)
+	Unsave(ctx context.Context, params repo.DeleteBookmarkParams) (repo.Bookmark, error)
+	ListSaved(ctx context.Context, params repo.ListBookmarksParams) ([]repo.ListBookmarksRow, error)
+	CreateCollection(ctx context.Context, params repo.CreateBookmarkCollectionParams) (repo.BookmarkCollection, error)
+	ListCollections(ctx context.Context, userID int64) ([]repo.BookmarkCollection, error)
+	RenameCollection(ctx context.Context, params repo.RenameBookmarkCollectionParams) (repo.BookmarkCollection, error)
+	DeleteCollection(ctx context.Context, id int64, userID int64) (repo.BookmarkCollection, error)
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	hits   = expvar.NewInt("cache_hits")
+	misses = expvar.NewInt("cache_misses")
+)
+
+// Store keeps encoded responses along with a generation number for every scope
+// An entry's key holds the generations it was built on, so bumping a scope leaves its old entries unreachable until they expire
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Generations returns the current generation of each scope, 0 for a scope without one
+	Generations(ctx context.Context, scopes []string) ([]int64, error)
+	// Bump moves the scopes to a generation none of their entries were stored under, which is kept for ttl
+	// Once it expires the scope is back at 0, which is safe as long as ttl is longer than the entries live
+	Bump(ctx context.Context, scopes []string, ttl time.Duration) error
+}
+
+// Cache sits in front of the read methods of the services
+// A nil *Cache is valid and caches nothing, which is what the command line tools use
+type Cache struct {
+	store Store
+	ttl   time.Duration
+	group singleflight.Group
+}
+
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{store: store, ttl: ttl}
+}
+
+// Load returns the entry for key if none of its scopes changed since it was stored, otherwise it calls load and stores the result
+// Callers missing the same entry at the same time share a single load
+// The cache never fails a read, when the store is unavailable load is called directly
+func Load[T any](ctx context.Context, c *Cache, key string, scopes []string, load func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if c == nil {
+		return load(ctx)
+	}
+
+	scopes = append([]string{All}, scopes...)
+	generations, err := c.store.Generations(ctx, scopes)
+	if err != nil {
+		slog.Warn("cache unavailable, reading from the database", "key", key, "error", err)
+		return load(ctx)
+	}
+	key = versionedKey(key, generations)
+
+	data, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		slog.Warn("cache read failed", "key", key, "error", err)
+	}
+	if ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			hits.Add(1)
+			return value, nil
+		}
+	}
+	misses.Add(1)
+
+	ch := c.group.DoChan(key, func() (any, error) {
+		// the load is shared, so one caller going away must not cancel it for the others
+		ctx := context.WithoutCancel(ctx)
+
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store.Set(ctx, key, data, c.ttl); err != nil {
+			slog.Warn("cache write failed", "key", key, "error", err)
+		}
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		// every caller decodes its own copy, so none of them can change what the others see
+		var value T
+		if err := json.Unmarshal(res.Val.([]byte), &value); err != nil {
+			return zero, err
+		}
+		return value, nil
+	}
+}
+
+// Invalidate moves the scopes to a new generation after a write
+// The write has already happened by then, so a failure is logged and the stale entries expire with the TTL
+func (c *Cache) Invalidate(ctx context.Context, scopes ...string) {
+	if c == nil || len(scopes) == 0 {
+		return
+	}
+
+	// by the time the generation expires every entry stored under an older one has expired as well
+	if err := c.store.Bump(context.WithoutCancel(ctx), scopes, 2*c.ttl); err != nil {
+		slog.Error("could not invalidate cached responses", "scopes", scopes, "error", err)
+	}
+}
+
+func versionedKey(key string, generations []int64) string {
+	var b strings.Builder
+	b.WriteString(key)
+	b.WriteByte('@')
+	for i, g := range generations {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.FormatInt(g, 10))
+	}
+	return b.String()
+}
@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stores runs a test against every store, Redis only when REDIS_ADDR points at a server
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	stores := map[string]Store{"memory": NewMemory(100)}
+
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		// a prefix of its own keeps runs apart from each other and from real data
+		prefix := "cache-test:" + strconv.FormatInt(time.Now().UnixNano(), 10) + ":"
+		store, err := NewRedis(context.Background(), RedisConfig{Addr: addr, Prefix: prefix})
+		if err != nil {
+			t.Fatalf("connecting to %s: %v", addr, err)
+		}
+		t.Cleanup(func() { store.Close() })
+		stores["redis"] = store
+	}
+
+	return stores
+}
+
+// counter is a load that returns how many times it has been called
+type counter struct {
+	calls atomic.Int64
+}
+
+func (c *counter) load(ctx context.Context) (int64, error) {
+	return c.calls.Add(1), nil
+}
+
+func TestLoadNilCache(t *testing.T) {
+	ctx := context.Background()
+	var c *Cache
+	var load counter
+
+	for want := int64(1); want <= 2; want++ {
+		got, err := Load(ctx, c, "key", []string{Topics}, load.load)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if got != want {
+			t.Errorf("Load = %d, want %d since nothing is cached", got, want)
+		}
+	}
+
+	// invalidating nothing must not panic
+	c.Invalidate(ctx, Topics)
+}
+
+func TestLoadGenerations(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c := New(store, time.Minute)
+			var load counter
+
+			get := func() int64 {
+				t.Helper()
+				got, err := Load(ctx, c, "topics", []string{Topics, TopicPosts(1)}, load.load)
+				if err != nil {
+					t.Fatalf("Load: %v", err)
+				}
+				return got
+			}
+
+			steps := []struct {
+				name       string
+				invalidate []string
+				want       int64
+			}{
+				{name: "first read loads", want: 1},
+				{name: "second read hits", want: 1},
+				{name: "unrelated scope keeps the entry", invalidate: []string{TopicPosts(2), Bookmarks(1)}, want: 1},
+				{name: "own scope reloads", invalidate: []string{TopicPosts(1)}, want: 2},
+				{name: "reload is cached", want: 2},
+				{name: "All reloads", invalidate: []string{All}, want: 3},
+			}
+			for _, step := range steps {
+				if len(step.invalidate) > 0 {
+					c.Invalidate(ctx, step.invalidate...)
+				}
+				if got := get(); got != step.want {
+					t.Errorf("%s: Load = %d, want %d", step.name, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadErrorsAreNotCached(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c := New(store, time.Minute)
+			failure := errors.New("database is down")
+
+			_, err := Load(ctx, c, "failing", nil, func(ctx context.Context) (int64, error) {
+				return 0, failure
+			})
+			if !errors.Is(err, failure) {
+				t.Fatalf("Load error = %v, want %v", err, failure)
+			}
+
+			got, err := Load(ctx, c, "failing", nil, func(ctx context.Context) (int64, error) {
+				return 7, nil
+			})
+			if err != nil || got != 7 {
+				t.Errorf("Load after a failure = %d, %v, want 7", got, err)
+			}
+		})
+	}
+}
+
+func TestLoadSharesConcurrentMisses(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c := New(store, time.Minute)
+
+			var calls atomic.Int64
+			release := make(chan struct{})
+			load := func(ctx context.Context) (int64, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			}
+
+			const callers = 10
+			var wg sync.WaitGroup
+			results := make([]int64, callers)
+			errs := make([]error, callers)
+			for i := range callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					results[i], errs[i] = Load(ctx, c, "shared", nil, load)
+				}()
+			}
+
+			// give every caller time to miss before the first load returns
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if n := calls.Load(); n != 1 {
+				t.Errorf("load called %d times, want 1", n)
+			}
+			for i := range callers {
+				if errs[i] != nil || results[i] != 42 {
+					t.Errorf("caller %d got %d, %v, want 42", i, results[i], errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerationsExpire(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			scopes := []string{Post(1)}
+			ttl := 50 * time.Millisecond
+
+			if err := store.Bump(ctx, scopes, ttl); err != nil {
+				t.Fatalf("Bump: %v", err)
+			}
+			first, err := store.Generations(ctx, scopes)
+			if err != nil {
+				t.Fatalf("Generations: %v", err)
+			}
+			if first[0] == 0 {
+				t.Fatal("a bumped scope is still at generation 0")
+			}
+
+			time.Sleep(2 * ttl)
+			expired, err := store.Generations(ctx, scopes)
+			if err != nil {
+				t.Fatalf("Generations: %v", err)
+			}
+			if expired[0] != 0 {
+				t.Errorf("generation after it expired = %d, want 0", expired[0])
+			}
+
+			// a scope starting over must not land on a generation entries were stored under before
+			if err := store.Bump(ctx, scopes, ttl); err != nil {
+				t.Fatalf("Bump: %v", err)
+			}
+			second, err := store.Generations(ctx, scopes)
+			if err != nil {
+				t.Fatalf("Generations: %v", err)
+			}
+			if second[0] <= first[0] {
+				t.Errorf("generation after starting over = %d, want more than %d", second[0], first[0])
+			}
+		})
+	}
+}
+
+func TestMemorySweepsExpiredGenerations(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+	ttl := 20 * time.Millisecond
+
+	if err := m.Bump(ctx, []string{Bookmarks(1), Bookmarks(2)}, ttl); err != nil {
+		t.Fatalf("Bump: %v", err)
+	}
+	time.Sleep(2 * ttl)
+	if err := m.Bump(ctx, []string{Bookmarks(3)}, ttl); err != nil {
+		t.Fatalf("Bump: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.generations) != 1 {
+		t.Errorf("%d generations kept, want only the one just bumped", len(m.generations))
+	}
+}
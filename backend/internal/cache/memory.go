@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps entries in the process, dropping the least recently used one once it is full
+// It suits a single server, run several behind a load balancer with Redis instead
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    *list.List // most recently used first
+	index      map[string]*list.Element
+
+	// generations outlive every entry stored before their last bump, see Bump
+	generations map[string]generation
+	sweepAt     time.Time
+}
+
+type generation struct {
+	value   int64
+	expires time.Time
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{
+		maxEntries:  maxEntries,
+		entries:     list.New(),
+		index:       make(map[string]*list.Element),
+		generations: make(map[string]generation),
+	}
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.remove(el)
+		return nil, false, nil
+	}
+
+	m.entries.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if el, ok := m.index[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value, entry.expires = value, expires
+		m.entries.MoveToFront(el)
+		return nil
+	}
+
+	m.index[key] = m.entries.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.entries.Len() > m.maxEntries {
+		m.remove(m.entries.Back())
+	}
+	return nil
+}
+
+func (m *Memory) Generations(ctx context.Context, scopes []string) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	generations := make([]int64, len(scopes))
+	for i, scope := range scopes {
+		if g, ok := m.generations[scope]; ok && now.Before(g.expires) {
+			generations[i] = g.value
+		}
+	}
+	return generations, nil
+}
+
+// Bump starts a scope that has no generation from the clock, so it never goes back to a value entries were stored under
+func (m *Memory) Bump(ctx context.Context, scopes []string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, scope := range scopes {
+		g, ok := m.generations[scope]
+		if !ok || !now.Before(g.expires) {
+			g.value = now.UnixMicro()
+		}
+		m.generations[scope] = generation{value: g.value + 1, expires: now.Add(ttl)}
+	}
+
+	// scopes of users that went away would otherwise pile up
+	if now.After(m.sweepAt) {
+		for scope, g := range m.generations {
+			if !now.Before(g.expires) {
+				delete(m.generations, scope)
+			}
+		}
+		m.sweepAt = now.Add(ttl)
+	}
+	return nil
+}
+
+func (m *Memory) remove(el *list.Element) {
+	m.entries.Remove(el)
+	delete(m.index, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig points the Redis store at a server, which every API server then shares
+type RedisConfig struct {
+	Addr     string // host and port
+	Password string
+	DB       int
+	Prefix   string // put in front of every key, so one server can hold several deployments
+}
+
+// Redis keeps entries in Redis, expiring them with the TTL
+// Generations are counters that expire some time after their last bump, see Bump
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis connects to the server and checks that it answers
+func NewRedis(ctx context.Context, cfg RedisConfig) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &Redis{client: client, prefix: cfg.Prefix}, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+"entry:"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+"entry:"+key, value, ttl).Err()
+}
+
+// Generations reads every scope in one round trip
+func (r *Redis) Generations(ctx context.Context, scopes []string) ([]int64, error) {
+	keys := make([]string, len(scopes))
+	for i, scope := range scopes {
+		keys[i] = r.prefix + "gen:" + scope
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	generations := make([]int64, len(scopes))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue // never bumped
+		}
+		if generations[i], err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return generations, nil
+}
+
+// bumpScript starts a missing counter from the server's clock in microseconds before incrementing it
+// TIME comes back as seconds and microseconds, joined as strings since Lua numbers would lose digits
+var bumpScript = redis.NewScript(`
+local now = redis.call('TIME')
+local seed = now[1] .. string.format('%06d', tonumber(now[2]))
+for _, key in ipairs(KEYS) do
+	redis.call('SET', key, seed, 'NX')
+	redis.call('INCR', key)
+	redis.call('PEXPIRE', key, ARGV[1])
+end
+return 0
+`)
+
+// Bump starts a scope that has no generation from the clock, so it never goes back to a value entries were stored under
+func (r *Redis) Bump(ctx context.Context, scopes []string, ttl time.Duration) error {
+	keys := make([]string, len(scopes))
+	for i, scope := range scopes {
+		keys[i] = r.prefix + "gen:" + scope
+	}
+	return bumpScript.Run(ctx, r.client, keys, ttl.Milliseconds()).Err()
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
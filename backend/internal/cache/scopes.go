@@ -0,0 +1,45 @@
+package cache
+
+import "strconv"
+
+// Anyone is the user shared listings are built for, no user has this id so nothing in them is marked as theirs
+// Each user's own marks are cached apart and laid over the shared listing
+const Anyone int64 = 0
+
+// Scopes name what a cached response was built from, a write invalidates the scopes it touched
+const (
+	// All is part of every entry, bulk jobs and moderation decisions invalidate it to start over
+	All = "all"
+	// Topics covers the topic listing, post counts included
+	Topics = "topics"
+)
+
+// Topic covers what a post page shows about the topic it is in, post count included
+func Topic(topicID int64) string {
+	return "topic:" + strconv.FormatInt(topicID, 10)
+}
+
+// TopicPosts covers the post listing of a topic, comment counts included
+func TopicPosts(topicID int64) string {
+	return "topic:" + strconv.FormatInt(topicID, 10) + ":posts"
+}
+
+// Post covers the post page and the comments under it
+func Post(postID int64) string {
+	return "post:" + strconv.FormatInt(postID, 10)
+}
+
+// TopicState covers the topics a user subscribed to and the ones they have read
+func TopicState(userID int64) string {
+	return "user:" + strconv.FormatInt(userID, 10) + ":topics"
+}
+
+// Bookmarks covers what a user has saved
+func Bookmarks(userID int64) string {
+	return "user:" + strconv.FormatInt(userID, 10) + ":bookmarks"
+}
+
+// Author covers the role, post count and followers shown next to a user's posts
+func Author(userID int64) string {
+	return "user:" + strconv.FormatInt(userID, 10) + ":author"
+}
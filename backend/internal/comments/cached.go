@@ -0,0 +1,158 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/jackc/pgx/v5"
+)
+
+// cachedService serves comment listings from the cache and invalidates them on every comment write
+type cachedService struct {
+	Service
+	cache *cache.Cache
+
+	// looks up the topic of a post, whose listing shows comment counts, and the bookmarks laid over the shared listing
+	repo *repo.Queries
+}
+
+func NewCachedService(service Service, repo *repo.Queries, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c, repo: repo}
+}
+
+// ListComments caches one listing of the post for everyone and the comments the user saved apart from it
+func (s *cachedService) ListComments(ctx context.Context, postId int64, userID int64) ([]repo.ListCommentsRow, error) {
+	if s.cache == nil {
+		return s.Service.ListComments(ctx, postId, userID)
+	}
+
+	scopes, err := s.listScopes(ctx, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := cache.Load(ctx, s.cache, fmt.Sprintf("comments:list:%d", postId), scopes,
+		func(ctx context.Context) ([]repo.ListCommentsRow, error) {
+			return s.Service.ListComments(ctx, postId, cache.Anyone)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := cache.Load(ctx, s.cache, fmt.Sprintf("comments:saved:%d:%d", postId, userID), []string{cache.Bookmarks(userID)},
+		func(ctx context.Context) ([]int64, error) {
+			return s.repo.ListSavedCommentIDs(ctx, repo.ListSavedCommentIDsParams{UserID: userID, PostID: postId})
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	isSaved := make(map[int64]bool, len(saved))
+	for _, id := range saved {
+		isSaved[id] = true
+	}
+	for i := range comments {
+		comments[i].Saved = isSaved[comments[i].ID]
+	}
+	return comments, nil
+}
+
+func (s *cachedService) ListCommentsVersion(ctx context.Context, postId int64, userID int64) (httpcache.Version, error) {
+	scopes, err := s.listScopes(ctx, postId)
+	if err != nil {
+		return httpcache.Version{}, err
+	}
+
+	return cache.Load(ctx, s.cache, fmt.Sprintf("comments:version:%d:%d", postId, userID), append(scopes, cache.Bookmarks(userID)),
+		func(ctx context.Context) (httpcache.Version, error) {
+			return s.Service.ListCommentsVersion(ctx, postId, userID)
+		})
+}
+
+// listScopes lists what the comments of a post are built from, the post and its topic, whose deletion hides them
+func (s *cachedService) listScopes(ctx context.Context, postID int64) ([]string, error) {
+	if s.cache == nil {
+		return nil, nil
+	}
+
+	// the topic of a post never changes, so the lookup is cached for good
+	topicID, err := cache.Load(ctx, s.cache, fmt.Sprintf("comments:ref:%d", postID), nil,
+		func(ctx context.Context) (int64, error) {
+			post, err := s.repo.GetPost(ctx, postID)
+			return post.TopicID, err
+		})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// a post that doesn't exist has no comments to list either
+		return []string{cache.Post(postID)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []string{cache.Post(postID), cache.Topic(topicID)}, nil
+}
+
+func (s *cachedService) CreateComment(ctx context.Context, req CreateCommentRequest) (repo.Comment, error) {
+	comment, err := s.Service.CreateComment(ctx, req)
+	if err == nil {
+		s.invalidateCounts(ctx, comment.PostID)
+	}
+	return comment, err
+}
+
+// an edit leaves the counts alone unless it was held for review, otherwise only the post page and its comments change
+func (s *cachedService) UpdateComment(ctx context.Context, req UpdateCommentRequest, editorID int64) (repo.Comment, error) {
+	comment, err := s.Service.UpdateComment(ctx, req, editorID)
+	if err == nil && comment.Status == contentfilter.StatusPending {
+		s.invalidateCounts(ctx, comment.PostID)
+	} else if err == nil {
+		s.cache.Invalidate(ctx, cache.Post(comment.PostID))
+	}
+	return comment, err
+}
+
+func (s *cachedService) DeleteComment(ctx context.Context, id int64, userID int64) (repo.Comment, error) {
+	comment, err := s.Service.DeleteComment(ctx, id, userID)
+	if err == nil {
+		s.invalidateCounts(ctx, comment.PostID)
+	}
+	return comment, err
+}
+
+func (s *cachedService) RestoreComment(ctx context.Context, id int64, userID int64, role string) (repo.Comment, error) {
+	comment, err := s.Service.RestoreComment(ctx, id, userID, role)
+	if err == nil {
+		s.invalidateCounts(ctx, comment.PostID)
+	}
+	return comment, err
+}
+
+func (s *cachedService) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	n, err := s.Service.PurgeDeleted(ctx, cutoff)
+	if n > 0 {
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return n, err
+}
+
+// invalidateCounts drops the post page along with the listing of its topic, which shows the comment count
+func (s *cachedService) invalidateCounts(ctx context.Context, postID int64) {
+	if s.cache == nil {
+		return
+	}
+
+	post, err := s.repo.GetPost(ctx, postID)
+	if err != nil {
+		// without the topic the listings cannot be picked out, so they all start over
+		slog.Warn("could not look up the topic of a post, clearing the cache", "post_id", postID, "error", err)
+		s.cache.Invalidate(ctx, cache.All)
+		return
+	}
+	s.cache.Invalidate(ctx, cache.Post(postID), cache.TopicPosts(post.TopicID))
+}
@@ -1,12 +1,22 @@
 package comments
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
-	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/attachments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
 	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/poststate"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/sanctions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/softdelete"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 )
 
 // NewHandler
@@ -19,17 +29,33 @@ func NewHandler(service Service) *handler {
 
 // Function that handles the ListComments API
 func (h *handler) ListComments(w http.ResponseWriter, r *http.Request) {
-	var data struct {
-		PostId int64 `json:"post_id"`
+	postID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
 	}
-	if err := json.Read(r, &data); err != nil {
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// a client that already has this version of the listing gets a 304 without it being built
+	version, err := h.service.ListCommentsVersion(r.Context(), postID, userID)
+	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if httpcache.NotModified(w, r, version) {
 		return
 	}
 
 	// Call this service -> ListComments
-	comments, err := h.service.ListComments(r.Context(), data.PostId)
+	comments, err := h.service.ListComments(r.Context(), postID, userID)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -44,10 +70,10 @@ func (h *handler) ListComments(w http.ResponseWriter, r *http.Request) {
 func (h *handler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 	// get the comment params from the request body
-	var createCommentParams repo.CreateCommentParams
+	var createCommentParams CreateCommentRequest
 	if err := json.Read(r, &createCommentParams); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
@@ -72,31 +98,88 @@ func (h *handler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	createdComment, err := h.service.CreateComment(r.Context(), createCommentParams)
 	if err != nil {
 		log.Println(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, sanctions.ErrMutedInTopic) || errors.Is(err, poststate.ErrLocked) || errors.Is(err, poststate.ErrArchived) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		var blocked *contentfilter.BlockedError
+		if errors.As(err, &blocked) || errors.Is(err, attachments.ErrUnavailable) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.Write(w, http.StatusOK, createdComment)
+	// flagged content is stored but only published once a moderator approves it
+	status := http.StatusOK
+	if createdComment.Status == contentfilter.StatusPending {
+		status = http.StatusAccepted
+	}
+
+	json.Write(w, status, createdComment)
 }
 
 // Function that handles the UpdateComment API
 func (h *handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 	// get the comment params from the request body
-	var updateCommentParams repo.UpdateCommentParams
+	var updateCommentParams UpdateCommentRequest
 	if err := json.Read(r, &updateCommentParams); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
-	updatedComment, err := h.service.UpdateComment(r.Context(), updateCommentParams)
+	expected, err := optimistic.FromRequest(r, updateCommentParams.Version)
+	if err != nil {
+		optimistic.WriteError(w, err)
+		return
+	}
+	updateCommentParams.Expected = expected
+
+	// Get user ID from context, recorded as the editor of the revision
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	updatedComment, err := h.service.UpdateComment(r.Context(), updateCommentParams, userID)
 	if err != nil {
 		log.Println(err)
+		if optimistic.WriteError(w, err) {
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "comment not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, poststate.ErrArchived) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		var blocked *contentfilter.BlockedError
+		if errors.As(err, &blocked) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.Write(w, http.StatusOK, updatedComment)
+	w.Header().Set("ETag", optimistic.ETag(updatedComment.Version))
+	// a flagged edit is kept but hidden until a moderator approves it
+	status := http.StatusOK
+	if updatedComment.Status == contentfilter.StatusPending {
+		status = http.StatusAccepted
+	}
+
+	json.Write(w, status, updatedComment)
 }
 
 // Function that handles the DeleteComment API
@@ -106,16 +189,71 @@ func (h *handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.Read(r, &data); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context, recorded as the one who deleted it
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	deletedComment, err := h.service.DeleteComment(r.Context(), data.ID)
+	deletedComment, err := h.service.DeleteComment(r.Context(), data.ID, userID)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// also what deleting it a second time gets
+			http.Error(w, "comment not found", http.StatusNotFound)
+		case errors.Is(err, poststate.ErrArchived):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
 	json.Write(w, http.StatusOK, deletedComment)
 }
+
+// Function that handles the RestoreComment API
+func (h *handler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	restoredComment, err := h.service.RestoreComment(r.Context(), data.ID, userID, role)
+	if err != nil {
+		log.Println(err)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			http.Error(w, "comment not found", http.StatusNotFound)
+		case errors.Is(err, softdelete.ErrForbidden), errors.Is(err, softdelete.ErrWindowPassed):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, softdelete.ErrNotDeleted):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	json.Write(w, http.StatusOK, restoredComment)
+}
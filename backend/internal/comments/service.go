@@ -3,20 +3,51 @@ package comments
 import (
 	"context"
 	"fmt"
+	"time"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/attachments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/markdown"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/poststate"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/revisions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/sanctions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/softdelete"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func NewService(repo *repo.Queries, pool db.Pool) Service {
-	return &svc{repo: repo, db: pool}
+func NewService(repo *repo.Queries, pool db.Pool, restoreWindow time.Duration, filter *contentfilter.Pipeline) Service {
+	return &svc{repo: repo, db: pool, restoreWindow: restoreWindow, filter: filter}
 }
 
-func (s *svc) ListComments(ctx context.Context, postId int64) ([]repo.Comment, error) {
-	return s.repo.ListComments(ctx, postId)
+func (s *svc) ListComments(ctx context.Context, postId int64, userID int64) ([]repo.ListCommentsRow, error) {
+	// saved tells the user which comments they bookmarked
+	return s.repo.ListComments(ctx, repo.ListCommentsParams{
+		UserID: userID,
+		PostID: postId,
+	})
 }
 
-func (s *svc) CreateComment(ctx context.Context, params repo.CreateCommentParams) (repo.Comment, error) {
+// ListCommentsVersion tells what ListComments would return for the user without building the listing
+func (s *svc) ListCommentsVersion(ctx context.Context, postId int64, userID int64) (httpcache.Version, error) {
+	row, err := s.repo.GetPostCommentsVersion(ctx, repo.GetPostCommentsVersionParams{
+		UserID: userID,
+		PostID: postId,
+	})
+	if err != nil {
+		return httpcache.Version{}, err
+	}
+	return httpcache.NewVersion(row.LastModified.Time, row.CommentCount, row.SavedCount), nil
+}
+
+func (s *svc) CreateComment(ctx context.Context, req CreateCommentRequest) (repo.Comment, error) {
+	params := req.CreateCommentParams
+
 	// validate the params
 	if params.Content == "" {
 		return repo.Comment{}, fmt.Errorf("content is required")
@@ -29,11 +60,64 @@ func (s *svc) CreateComment(ctx context.Context, params repo.CreateCommentParams
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
+	// the post decides whether comments are allowed, mutes apply to its whole topic
+	post, err := qtx.GetPost(ctx, params.PostID)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	// a deleted post, or one in a deleted topic, is reported like a missing one
+	if post.DeletedAt.Valid {
+		return repo.Comment{}, pgx.ErrNoRows
+	}
+	topic, err := qtx.GetTopic(ctx, post.TopicID)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	if topic.DeletedAt.Valid {
+		return repo.Comment{}, pgx.ErrNoRows
+	}
+	if err := poststate.CanComment(post); err != nil {
+		return repo.Comment{}, err
+	}
+
+	muted, err := qtx.IsMutedInTopic(ctx, repo.IsMutedInTopicParams{
+		TopicID: post.TopicID,
+		UserID:  params.UserID,
+	})
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	if muted {
+		return repo.Comment{}, sanctions.ErrMutedInTopic
+	}
+
+	// the filter may reject the comment, mask words in it or hold it back for review
+	sub := contentfilter.Submission{AuthorID: params.UserID, Content: params.Content}
+	if err := s.filter.Run(ctx, qtx, &sub); err != nil {
+		return repo.Comment{}, err
+	}
+	params.Content = sub.Content
+	params.Status = sub.Status()
+	params.FlagReason = sub.FlagReason()
+	params.ContentHash = sub.Hash
+
+	// render the markdown once on write so that reads can serve the cached HTML
+	contentHtml, err := markdown.Render(params.Content)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	params.ContentHtml = contentHtml
+
 	comment, err := qtx.CreateComment(ctx, params)
 	if err != nil {
 		return repo.Comment{}, err
 	}
 
+	err = attachments.Attach(ctx, qtx, comment.UserID, attachments.TargetComment, comment.ID, req.AttachmentIDs)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return repo.Comment{}, err
 	}
@@ -41,7 +125,9 @@ func (s *svc) CreateComment(ctx context.Context, params repo.CreateCommentParams
 	return comment, nil
 }
 
-func (s *svc) UpdateComment(ctx context.Context, params repo.UpdateCommentParams) (repo.Comment, error) {
+func (s *svc) UpdateComment(ctx context.Context, req UpdateCommentRequest, editorID int64) (repo.Comment, error) {
+	params := req.UpdateCommentParams
+
 	// validate the params
 	if params.Content == "" {
 		return repo.Comment{}, fmt.Errorf("content is required")
@@ -54,11 +140,71 @@ func (s *svc) UpdateComment(ctx context.Context, params repo.UpdateCommentParams
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
+	// snapshot the previous text under a row lock before overwriting it
+	current, err := qtx.GetCommentForUpdate(ctx, params.ID)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	// comments under an archived post are frozen along with it
+	post, err := qtx.GetPost(ctx, current.PostID)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	if err := poststate.CanEdit(post); err != nil {
+		return repo.Comment{}, err
+	}
+
+	if err := optimistic.Check(req.Expected, current.Version, current); err != nil {
+		return repo.Comment{}, err
+	}
+
+	// edits go through the same filter as new comments, a flagged edit hides the comment until it is approved
+	sub := contentfilter.Submission{AuthorID: current.UserID, Content: params.Content, Previous: current.ContentHash}
+	if err := s.filter.Run(ctx, qtx, &sub); err != nil {
+		return repo.Comment{}, err
+	}
+	params.Content = sub.Content
+	params.Status, params.FlagReason = current.Status, current.FlagReason
+	if sub.Status() == contentfilter.StatusPending {
+		params.Status, params.FlagReason = contentfilter.StatusPending, sub.FlagReason()
+	}
+	params.ContentHash = sub.Hash
+
+	contentHtml, err := markdown.Render(params.Content)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	params.ContentHtml = contentHtml
+
+	err = qtx.CreateRevision(ctx, repo.CreateRevisionParams{
+		TargetType: revisions.TargetComment,
+		TargetID:   current.ID,
+		Revision:   current.EditCount,
+		Content:    current.Content,
+		EditedBy:   pgtype.Int8{Int64: editorID, Valid: true},
+		Status:     current.Status,
+	})
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
 	comment, err := qtx.UpdateComment(ctx, params)
 	if err != nil {
 		return repo.Comment{}, err
 	}
 
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "comment.update",
+		TargetType: "comment",
+		TargetID:   comment.ID,
+		Before:     current,
+		After:      comment,
+	})
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return repo.Comment{}, err
 	}
@@ -66,7 +212,7 @@ func (s *svc) UpdateComment(ctx context.Context, params repo.UpdateCommentParams
 	return comment, nil
 }
 
-func (s *svc) DeleteComment(ctx context.Context, id int64) (repo.Comment, error) {
+func (s *svc) DeleteComment(ctx context.Context, id int64, userID int64) (repo.Comment, error) {
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return repo.Comment{}, err
@@ -74,7 +220,35 @@ func (s *svc) DeleteComment(ctx context.Context, id int64) (repo.Comment, error)
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
-	comment, err := qtx.DeleteComment(ctx, id)
+	current, err := qtx.GetComment(ctx, id)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	// comments under an archived post are frozen along with it
+	post, err := qtx.GetPost(ctx, current.PostID)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	if err := poststate.CanEdit(post); err != nil {
+		return repo.Comment{}, err
+	}
+
+	comment, err := qtx.DeleteComment(ctx, repo.DeleteCommentParams{
+		ID:        id,
+		DeletedBy: pgtype.Int8{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "comment.delete",
+		TargetType: "comment",
+		TargetID:   comment.ID,
+		Before:     current,
+		After:      comment,
+	})
 	if err != nil {
 		return repo.Comment{}, err
 	}
@@ -85,3 +259,78 @@ func (s *svc) DeleteComment(ctx context.Context, id int64) (repo.Comment, error)
 
 	return comment, nil
 }
+
+func (s *svc) RestoreComment(ctx context.Context, id int64, userID int64, role string) (repo.Comment, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	existing, err := qtx.GetComment(ctx, id)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	if err := softdelete.CanRestore(existing.DeletedAt, existing.UserID, userID, role, s.restoreWindow); err != nil {
+		return repo.Comment{}, err
+	}
+
+	comment, err := qtx.RestoreComment(ctx, id)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "comment.restore",
+		TargetType: "comment",
+		TargetID:   comment.ID,
+		Before:     existing,
+		After:      comment,
+	})
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Comment{}, err
+	}
+
+	return comment, nil
+}
+
+func (s *svc) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return s.repo.PurgeDeletedComments(ctx, pgtype.Timestamp{Time: cutoff, Valid: true})
+}
+
+// Reindex renders the markdown and hashes the text of every comment again, in batches so it can run on a live database
+func (s *svc) Reindex(ctx context.Context) (int64, error) {
+	var afterID, total int64
+	for {
+		rows, err := s.repo.ListCommentsForReindex(ctx, repo.ListCommentsForReindexParams{AfterID: afterID, RowLimit: reindexBatchSize})
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		for _, row := range rows {
+			contentHtml, err := markdown.Render(row.Content)
+			if err != nil {
+				return total, err
+			}
+			err = s.repo.SetCommentIndex(ctx, repo.SetCommentIndexParams{
+				ID:          row.ID,
+				ContentHtml: contentHtml,
+				ContentHash: contentfilter.Hash(row.Content),
+			})
+			if err != nil {
+				return total, err
+			}
+			afterID = row.ID
+			total++
+		}
+	}
+}
@@ -2,24 +2,55 @@ package comments
 
 import (
 	"context"
+	"time"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
 )
 
 type handler struct {
 	service Service
 }
 
+// rows rendered and hashed per query by Reindex
+const reindexBatchSize = 500
+
 type svc struct {
 	// database
 	repo *repo.Queries
 	db   db.Pool
+
+	// how long the owner has to restore something they deleted
+	restoreWindow time.Duration
+
+	// checks new content before it is stored, nil skips them
+	filter *contentfilter.Pipeline
+}
+
+// CreateCommentRequest is the body of the CreateComment API, the comment plus the uploads to attach to it
+type CreateCommentRequest struct {
+	repo.CreateCommentParams
+	AttachmentIDs []int64 `json:"attachment_ids"`
+}
+
+// UpdateCommentRequest is the body of the UpdateComment API
+// Version is the version being edited, an If-Match header can be sent instead
+type UpdateCommentRequest struct {
+	repo.UpdateCommentParams
+	Version  *int32              `json:"version"`
+	Expected optimistic.Expected `json:"-"`
 }
 
 type Service interface {
-	ListComments(ctx context.Context, postId int64) ([]repo.Comment, error)
-	CreateComment(ctx context.Context, params repo.CreateCommentParams) (repo.Comment, error)
-	UpdateComment(ctx context.Context, params repo.UpdateCommentParams) (repo.Comment, error)
-	DeleteComment(ctx context.Context, id int64) (repo.Comment, error)
+	ListComments(ctx context.Context, postId int64, userID int64) ([]repo.ListCommentsRow, error)
+	ListCommentsVersion(ctx context.Context, postId int64, userID int64) (httpcache.Version, error)
+	CreateComment(ctx context.Context, req CreateCommentRequest) (repo.Comment, error)
+	UpdateComment(ctx context.Context, req UpdateCommentRequest, editorID int64) (repo.Comment, error)
+	DeleteComment(ctx context.Context, id int64, userID int64) (repo.Comment, error)
+	RestoreComment(ctx context.Context, id int64, userID int64, role string) (repo.Comment, error)
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	Reindex(ctx context.Context) (int64, error)
 }
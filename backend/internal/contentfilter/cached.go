@@ -0,0 +1,33 @@
+package contentfilter
+
+import (
+	"context"
+
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+)
+
+// cachedService invalidates cached listings once held content is approved or rejected
+type cachedService struct {
+	Service
+	cache *cache.Cache
+}
+
+func NewCachedService(service Service, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c}
+}
+
+func (s *cachedService) ReviewContent(ctx context.Context, targetType string, targetID int64, decision string, moderatorID int64) (Review, error) {
+	review, err := s.Service.ReviewContent(ctx, targetType, targetID, decision, moderatorID)
+	if err != nil {
+		return review, err
+	}
+
+	switch {
+	case review.Post != nil:
+		s.cache.Invalidate(ctx, cache.Topics, cache.Topic(review.Post.TopicID), cache.TopicPosts(review.Post.TopicID), cache.Post(review.Post.ID), cache.Author(review.Post.UserID))
+	case review.Comment != nil:
+		// the topic of the comment is not at hand, reviews are rare enough to start over
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return review, nil
+}
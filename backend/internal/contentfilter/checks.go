@@ -0,0 +1,147 @@
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BannedWords applies the admin-managed word list
+// The list is read on every submission so changes take effect straight away
+type BannedWords struct{}
+
+// compiled word patterns, keyed by word
+var wordPatterns sync.Map
+
+func (BannedWords) Check(ctx context.Context, q *repo.Queries, sub *Submission) error {
+	words, err := q.ListBannedWords(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, word := range words {
+		pattern := wordPattern(word.Word)
+		if !pattern.MatchString(sub.Title) && !pattern.MatchString(sub.Content) {
+			continue
+		}
+
+		switch word.Action {
+		case ActionBlock:
+			return &BlockedError{Reason: "content contains a banned word"}
+		case ActionMask:
+			sub.Title = mask(pattern, sub.Title)
+			sub.Content = mask(pattern, sub.Content)
+		case ActionFlag:
+			sub.Flag(fmt.Sprintf("contains %q", word.Word))
+		}
+	}
+	return nil
+}
+
+// wordPattern matches the word on its own, in any case
+func wordPattern(word string) *regexp.Regexp {
+	if pattern, ok := wordPatterns.Load(word); ok {
+		return pattern.(*regexp.Regexp)
+	}
+
+	pattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+	wordPatterns.Store(word, pattern)
+	return pattern
+}
+
+func mask(pattern *regexp.Regexp, text string) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(match))
+	})
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkLimit rejects submissions from new accounts that carry more than MaxLinks links
+type LinkLimit struct {
+	MaxLinks   int
+	AccountAge time.Duration // accounts younger than this are limited
+}
+
+func (l LinkLimit) Check(ctx context.Context, q *repo.Queries, sub *Submission) error {
+	links := len(linkPattern.FindAllString(sub.Title, -1)) + len(linkPattern.FindAllString(sub.Content, -1))
+	if links <= l.MaxLinks {
+		return nil
+	}
+
+	author, err := q.FetchUserByID(ctx, sub.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().UTC().Sub(author.CreatedAt.Time) >= l.AccountAge {
+		return nil
+	}
+
+	return &BlockedError{Reason: fmt.Sprintf("new accounts can include at most %d links", l.MaxLinks)}
+}
+
+// Duplicates flags text that the author has already posted MaxRepeats times within Window,
+// across every topic and in both posts and comments
+type Duplicates struct {
+	Window     time.Duration
+	MaxRepeats int64
+	MinLength  int // shorter text such as "thanks!" is expected to repeat
+}
+
+func (d Duplicates) Check(ctx context.Context, q *repo.Queries, sub *Submission) error {
+	if utf8.RuneCountInString(strings.TrimSpace(sub.Content)) < d.MinLength {
+		return nil
+	}
+
+	// an edit that keeps the text would only find itself
+	if sub.Hash == sub.Previous {
+		return nil
+	}
+
+	count, err := q.CountRecentDuplicates(ctx, repo.CountRecentDuplicatesParams{
+		UserID:      sub.AuthorID,
+		ContentHash: sub.Hash,
+		Since:       pgtype.Timestamp{Time: time.Now().UTC().Add(-d.Window), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if count >= d.MaxRepeats {
+		sub.Flag(fmt.Sprintf("same text posted %d times in the last %s", count, d.Window))
+	}
+	return nil
+}
+
+// Scorer rates how likely a submission is to be spam or abuse, from 0 to 1
+// This is the extension point for classifiers
+type Scorer interface {
+	Score(ctx context.Context, sub Submission) (float64, error)
+}
+
+// Score flags submissions that Scorer rates at or above Threshold
+type Score struct {
+	Name      string // shown to moderators in the flag reason
+	Scorer    Scorer
+	Threshold float64
+}
+
+func (s Score) Check(ctx context.Context, q *repo.Queries, sub *Submission) error {
+	score, err := s.Scorer.Score(ctx, *sub)
+	if err != nil {
+		return err
+	}
+
+	if score >= s.Threshold {
+		sub.Flag(fmt.Sprintf("%s scored %.2f", s.Name, score))
+	}
+	return nil
+}
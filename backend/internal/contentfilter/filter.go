@@ -0,0 +1,94 @@
+package contentfilter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+)
+
+// Submission is a post or comment on its way into the database
+// Checks can rewrite Title and Content, and anything they add to Flags holds it back for review
+type Submission struct {
+	AuthorID int64
+	Title    string // empty for comments
+	Content  string
+	Hash     string // hash of the content as it was submitted, set by Run
+	Previous string // hash of the content an edit replaces, empty for new submissions
+	Flags    []string
+}
+
+// Flag holds the submission back for review, reason is shown to moderators
+func (s *Submission) Flag(reason string) {
+	s.Flags = append(s.Flags, reason)
+}
+
+// Status is the status the submission should be stored with
+func (s *Submission) Status() string {
+	if len(s.Flags) > 0 {
+		return StatusPending
+	}
+	return StatusPublished
+}
+
+// FlagReason joins the flags into the text stored in flag_reason
+func (s *Submission) FlagReason() string {
+	return strings.Join(s.Flags, "; ")
+}
+
+// BlockedError rejects a submission outright, Reason is safe to show to the author
+type BlockedError struct {
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return e.Reason
+}
+
+// Check is a single stage of the pipeline
+// Returning a *BlockedError rejects the submission, any other error aborts the request
+type Check interface {
+	Check(ctx context.Context, q *repo.Queries, sub *Submission) error
+}
+
+// CheckFunc lets a plain function be used as a Check
+type CheckFunc func(ctx context.Context, q *repo.Queries, sub *Submission) error
+
+func (f CheckFunc) Check(ctx context.Context, q *repo.Queries, sub *Submission) error {
+	return f(ctx, q, sub)
+}
+
+// Pipeline runs every check in order against new posts and comments
+type Pipeline struct {
+	checks []Check
+}
+
+func New(checks ...Check) *Pipeline {
+	return &Pipeline{checks: checks}
+}
+
+// Run passes the submission through every check, stopping at the first error
+// Pass the transaction's queries so lookups see the same data as the insert that follows
+// A nil pipeline only sets the hash
+func (p *Pipeline) Run(ctx context.Context, q *repo.Queries, sub *Submission) error {
+	sub.Hash = Hash(sub.Content)
+	if p == nil {
+		return nil
+	}
+
+	for _, check := range p.checks {
+		if err := check.Check(ctx, q, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Hash returns a hash of the text that ignores case and whitespace differences
+func Hash(content string) string {
+	normalised := strings.Join(strings.Fields(strings.ToLower(content)), " ")
+	sum := sha256.Sum256([]byte(normalised))
+	return hex.EncodeToString(sum[:])
+}
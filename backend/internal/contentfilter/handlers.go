@@ -0,0 +1,146 @@
+package contentfilter
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// writeError maps service errors onto the matching status code
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrWordRequired), errors.Is(err, ErrInvalidAction), errors.Is(err, ErrInvalidTarget), errors.Is(err, ErrInvalidDecision):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotPending):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function that handles the ListBannedWords API
+func (h *handler) ListBannedWords(w http.ResponseWriter, r *http.Request) {
+	words, err := h.service.ListBannedWords(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, words)
+}
+
+// Function that handles the AddBannedWord API
+func (h *handler) AddBannedWord(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Word   string `json:"word"`
+		Action string `json:"action"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	adminID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	word, err := h.service.AddBannedWord(r.Context(), data.Word, data.Action, adminID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, word)
+}
+
+// Function that handles the DeleteBannedWord API
+func (h *handler) DeleteBannedWord(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	word, err := h.service.DeleteBannedWord(r.Context(), data.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, word)
+}
+
+// Function that handles the ListPending API
+func (h *handler) ListPending(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Limit  int32 `json:"limit"`
+		Offset int32 `json:"offset"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	pending, err := h.service.ListPending(r.Context(), data.Limit, data.Offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, pending)
+}
+
+// Function that handles the ReviewContent API
+func (h *handler) ReviewContent(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TargetType string `json:"target_type"`
+		TargetID   int64  `json:"target_id"`
+		Decision   string `json:"decision"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	moderatorID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	review, err := h.service.ReviewContent(r.Context(), data.TargetType, data.TargetID, data.Decision, moderatorID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, review)
+}
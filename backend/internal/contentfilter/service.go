@@ -0,0 +1,278 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/markdown"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/notifications"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+func (s *svc) ListBannedWords(ctx context.Context) ([]repo.BannedWord, error) {
+	return s.repo.ListBannedWords(ctx)
+}
+
+func (s *svc) AddBannedWord(ctx context.Context, word string, action string, adminID int64) (repo.BannedWord, error) {
+	// validate the params
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return repo.BannedWord{}, ErrWordRequired
+	}
+	if action != ActionBlock && action != ActionMask && action != ActionFlag {
+		return repo.BannedWord{}, ErrInvalidAction
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.BannedWord{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	// adding a word that is already listed changes its action
+	banned, err := qtx.UpsertBannedWord(ctx, repo.UpsertBannedWordParams{
+		Word:      word,
+		Action:    action,
+		CreatedBy: pgtype.Int8{Int64: adminID, Valid: true},
+	})
+	if err != nil {
+		return repo.BannedWord{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "banned_word.add",
+		TargetType: "banned_word",
+		TargetID:   banned.ID,
+		After:      banned,
+	})
+	if err != nil {
+		return repo.BannedWord{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.BannedWord{}, err
+	}
+
+	return banned, nil
+}
+
+func (s *svc) DeleteBannedWord(ctx context.Context, id int64) (repo.BannedWord, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.BannedWord{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	banned, err := qtx.DeleteBannedWord(ctx, id)
+	if err != nil {
+		return repo.BannedWord{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "banned_word.delete",
+		TargetType: "banned_word",
+		TargetID:   banned.ID,
+		Before:     banned,
+	})
+	if err != nil {
+		return repo.BannedWord{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.BannedWord{}, err
+	}
+
+	return banned, nil
+}
+
+func (s *svc) ListPending(ctx context.Context, limit int32, offset int32) (PendingContent, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	posts, err := s.repo.ListPendingPosts(ctx, repo.ListPendingPostsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return PendingContent{}, err
+	}
+
+	comments, err := s.repo.ListPendingComments(ctx, repo.ListPendingCommentsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return PendingContent{}, err
+	}
+
+	return PendingContent{Posts: posts, Comments: comments}, nil
+}
+
+func (s *svc) ReviewContent(ctx context.Context, targetType string, targetID int64, decision string, moderatorID int64) (Review, error) {
+	// validate the params
+	if decision != DecisionApprove && decision != DecisionReject {
+		return Review{}, ErrInvalidDecision
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return Review{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	review := Review{Decision: decision}
+	var before, after any
+
+	switch targetType {
+	case TargetPost:
+		current, err := qtx.GetPostForUpdate(ctx, targetID)
+		if err != nil {
+			return Review{}, err
+		}
+		if current.Status != StatusPending {
+			return Review{}, ErrNotPending
+		}
+
+		// rejected content is soft deleted so it can still be restored by an admin
+		// a held edit of a published post only loses the edit, the post goes back to what was published
+		var post repo.Post
+		switch {
+		case decision == DecisionApprove:
+			post, err = qtx.ApprovePost(ctx, targetID)
+			// followers already heard about a post that was published before its edit was held
+			if err == nil && !current.PublishedAt.Valid {
+				err = notifications.NotifyFollowersOfPost(ctx, qtx, post)
+			}
+		case current.PublishedAt.Valid:
+			post, err = s.revertPost(ctx, qtx, current, moderatorID)
+		default:
+			post, err = qtx.DeletePost(ctx, repo.DeletePostParams{ID: targetID, DeletedBy: pgtype.Int8{Int64: moderatorID, Valid: true}})
+		}
+		if err != nil {
+			return Review{}, err
+		}
+		review.Post, before, after = &post, current, post
+	case TargetComment:
+		current, err := qtx.GetCommentForUpdate(ctx, targetID)
+		if err != nil {
+			return Review{}, err
+		}
+		if current.Status != StatusPending {
+			return Review{}, ErrNotPending
+		}
+
+		var comment repo.Comment
+		switch {
+		case decision == DecisionApprove:
+			comment, err = qtx.ApproveComment(ctx, targetID)
+		case current.PublishedAt.Valid:
+			comment, err = s.revertComment(ctx, qtx, current, moderatorID)
+		default:
+			comment, err = qtx.DeleteComment(ctx, repo.DeleteCommentParams{ID: targetID, DeletedBy: pgtype.Int8{Int64: moderatorID, Valid: true}})
+		}
+		if err != nil {
+			return Review{}, err
+		}
+		review.Comment, before, after = &comment, current, comment
+	default:
+		return Review{}, ErrInvalidTarget
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     targetType + "." + decision,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		return Review{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Review{}, err
+	}
+
+	return review, nil
+}
+
+// revertPost puts a post whose edit was rejected back to its latest published revision
+// The rejected text is kept as a revision of its own, like any other edit
+// Without a published revision to go back to the post is soft deleted, as new content would be
+func (s *svc) revertPost(ctx context.Context, qtx *repo.Queries, current repo.Post, moderatorID int64) (repo.Post, error) {
+	published, err := qtx.GetLatestPublishedRevision(ctx, repo.GetLatestPublishedRevisionParams{TargetType: TargetPost, TargetID: current.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return qtx.DeletePost(ctx, repo.DeletePostParams{ID: current.ID, DeletedBy: pgtype.Int8{Int64: moderatorID, Valid: true}})
+	}
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	contentHtml, err := markdown.Render(published.Content)
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	err = qtx.CreateRevision(ctx, repo.CreateRevisionParams{
+		TargetType: TargetPost,
+		TargetID:   current.ID,
+		Revision:   current.EditCount,
+		Title:      pgtype.Text{String: current.Title, Valid: true},
+		Content:    current.Content,
+		EditedBy:   pgtype.Int8{Int64: moderatorID, Valid: true},
+		Status:     current.Status,
+	})
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	return qtx.RevertPost(ctx, repo.RevertPostParams{
+		ID:          current.ID,
+		Title:       published.Title.String,
+		Content:     published.Content,
+		ContentHtml: contentHtml,
+		ContentHash: Hash(published.Content),
+	})
+}
+
+// revertComment puts a comment whose edit was rejected back to its latest published revision, see revertPost
+func (s *svc) revertComment(ctx context.Context, qtx *repo.Queries, current repo.Comment, moderatorID int64) (repo.Comment, error) {
+	published, err := qtx.GetLatestPublishedRevision(ctx, repo.GetLatestPublishedRevisionParams{TargetType: TargetComment, TargetID: current.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return qtx.DeleteComment(ctx, repo.DeleteCommentParams{ID: current.ID, DeletedBy: pgtype.Int8{Int64: moderatorID, Valid: true}})
+	}
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	contentHtml, err := markdown.Render(published.Content)
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	err = qtx.CreateRevision(ctx, repo.CreateRevisionParams{
+		TargetType: TargetComment,
+		TargetID:   current.ID,
+		Revision:   current.EditCount,
+		Content:    current.Content,
+		EditedBy:   pgtype.Int8{Int64: moderatorID, Valid: true},
+		Status:     current.Status,
+	})
+	if err != nil {
+		return repo.Comment{}, err
+	}
+
+	return qtx.RevertComment(ctx, repo.RevertCommentParams{
+		ID:          current.ID,
+		Content:     published.Content,
+		ContentHtml: contentHtml,
+		ContentHash: Hash(published.Content),
+	})
+}
@@ -0,0 +1,73 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+// values stored in posts.status and comments.status
+const (
+	StatusPublished = "published"
+	StatusPending   = "pending"
+)
+
+// values stored in banned_words.action
+const (
+	ActionBlock = "block"
+	ActionMask  = "mask"
+	ActionFlag  = "flag"
+)
+
+// targets that can be reviewed
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+)
+
+// decisions a moderator can make on pending content
+const (
+	DecisionApprove = "approve"
+	DecisionReject  = "reject"
+)
+
+var (
+	ErrWordRequired    = errors.New("word is required")
+	ErrInvalidAction   = errors.New("action must be one of block, mask or flag")
+	ErrInvalidTarget   = errors.New("target_type must be either post or comment")
+	ErrInvalidDecision = errors.New("decision must be either approve or reject")
+	ErrNotPending      = errors.New("this content is not waiting for review")
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+// PendingContent is the review queue, oldest first
+type PendingContent struct {
+	Posts    []repo.Post    `json:"posts"`
+	Comments []repo.Comment `json:"comments"`
+}
+
+// Review is the outcome of a moderator's decision, only the reviewed target is set
+type Review struct {
+	Decision string        `json:"decision"`
+	Post     *repo.Post    `json:"post,omitempty"`
+	Comment  *repo.Comment `json:"comment,omitempty"`
+}
+
+type Service interface {
+	ListBannedWords(ctx context.Context) ([]repo.BannedWord, error)
+	AddBannedWord(ctx context.Context, word string, action string, adminID int64) (repo.BannedWord, error)
+	DeleteBannedWord(ctx context.Context, id int64) (repo.BannedWord, error)
+	ListPending(ctx context.Context, limit int32, offset int32) (PendingContent, error)
+	ReviewContent(ctx context.Context, targetType string, targetID int64, decision string, moderatorID int64) (Review, error)
+}
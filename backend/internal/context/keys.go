@@ -6,4 +6,6 @@ type contextKey string
 const (
 	UserIDKey   contextKey = "userID"
 	UsernameKey contextKey = "username"
+	RoleKey     contextKey = "role"
+	SanctionKey contextKey = "sanction" // active suspension, if any
 )
@@ -1,6 +1,11 @@
 package env
 
-import "os"
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
 
 func GetString(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
@@ -9,3 +14,48 @@ func GetString(key, fallback string) string {
 
 	return fallback
 }
+
+func GetInt64(key string, fallback int64) int64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		slog.Warn("invalid integer in environment, using fallback", "key", key, "value", val)
+		return fallback
+	}
+
+	return n
+}
+
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		slog.Warn("invalid duration in environment, using fallback", "key", key, "value", val)
+		return fallback
+	}
+
+	return d
+}
+
+func GetBool(key string, fallback bool) bool {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		slog.Warn("invalid boolean in environment, using fallback", "key", key, "value", val)
+		return fallback
+	}
+
+	return b
+}
@@ -0,0 +1,34 @@
+package feed
+
+import (
+	"context"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+)
+
+// cachedService invalidates the user's cached topic listing, which shows what they subscribed to
+type cachedService struct {
+	Service
+	cache *cache.Cache
+}
+
+func NewCachedService(service Service, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c}
+}
+
+func (s *cachedService) Subscribe(ctx context.Context, userID int64, topicID int64) (repo.TopicSubscription, error) {
+	subscription, err := s.Service.Subscribe(ctx, userID, topicID)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.TopicState(userID))
+	}
+	return subscription, err
+}
+
+func (s *cachedService) Unsubscribe(ctx context.Context, userID int64, topicID int64) (repo.TopicSubscription, error) {
+	subscription, err := s.Service.Unsubscribe(ctx, userID, topicID)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.TopicState(userID))
+	}
+	return subscription, err
+}
@@ -0,0 +1,65 @@
+package feed
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// cursor marks the last item of a page, the next page starts right after it
+// It is handed to clients as an opaque string
+type cursor struct {
+	CreatedAt time.Time
+	ID        int64
+	Kind      string // only set in the following feed, where posts and comments are mixed
+}
+
+func (c cursor) encode() string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixMicro(), c.ID)
+	if c.Kind != "" {
+		raw += ":" + c.Kind
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	// cursors handed out before the following feed have no kind, they still work in the subscription feed
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return cursor{}, ErrInvalidCursor
+	}
+	micros, id, kind := parts[0], parts[1], ""
+	if len(parts) == 3 {
+		kind = parts[2]
+	}
+
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	itemID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+
+	// postgres keeps microseconds, so this round trips exactly
+	return cursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: itemID, Kind: kind}, nil
+}
+
+// params turns an optional cursor into the nullable query arguments
+func (c *cursor) params() (pgtype.Timestamp, pgtype.Int8) {
+	if c == nil {
+		return pgtype.Timestamp{}, pgtype.Int8{}
+	}
+	return pgtype.Timestamp{Time: c.CreatedAt, Valid: true}, pgtype.Int8{Int64: c.ID, Valid: true}
+}
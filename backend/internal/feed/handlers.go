@@ -0,0 +1,166 @@
+package feed
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// writeError maps service errors onto the matching status code
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrInvalidCursor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function that handles the Subscribe API
+func (h *handler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TopicID int64 `json:"topic_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	subscription, err := h.service.Subscribe(r.Context(), userID, data.TopicID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, subscription)
+}
+
+// Function that handles the Unsubscribe API
+func (h *handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TopicID int64 `json:"topic_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	subscription, err := h.service.Unsubscribe(r.Context(), userID, data.TopicID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, subscription)
+}
+
+// Function that handles the ListSubscriptions API
+func (h *handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	topics, err := h.service.ListSubscriptions(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, topics)
+}
+
+// Function that handles the Feed API
+func (h *handler) Feed(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Cursor string `json:"cursor"`
+		Limit  int32  `json:"limit"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	page, err := h.service.Feed(r.Context(), userID, data.Cursor, data.Limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, page)
+}
+
+// Function that handles the FollowingFeed API
+func (h *handler) FollowingFeed(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Cursor string `json:"cursor"`
+		Limit  int32  `json:"limit"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	page, err := h.service.FollowingFeed(r.Context(), userID, data.Cursor, data.Limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, page)
+}
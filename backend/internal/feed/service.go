@@ -0,0 +1,186 @@
+package feed
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgx/v5"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+func (s *svc) Subscribe(ctx context.Context, userID int64, topicID int64) (repo.TopicSubscription, error) {
+	// deleted topics cannot be subscribed to
+	topic, err := s.repo.GetTopic(ctx, topicID)
+	if err != nil {
+		return repo.TopicSubscription{}, err
+	}
+	if topic.DeletedAt.Valid {
+		return repo.TopicSubscription{}, pgx.ErrNoRows
+	}
+
+	// subscribing twice keeps the original subscription
+	return s.repo.CreateTopicSubscription(ctx, repo.CreateTopicSubscriptionParams{
+		UserID:  userID,
+		TopicID: topicID,
+	})
+}
+
+func (s *svc) Unsubscribe(ctx context.Context, userID int64, topicID int64) (repo.TopicSubscription, error) {
+	return s.repo.DeleteTopicSubscription(ctx, repo.DeleteTopicSubscriptionParams{
+		UserID:  userID,
+		TopicID: topicID,
+	})
+}
+
+func (s *svc) ListSubscriptions(ctx context.Context, userID int64) ([]repo.Topic, error) {
+	return s.repo.ListTopicSubscriptions(ctx, userID)
+}
+
+// Feed lists posts from the user's subscribed topics newest first, like the topic listings
+func (s *svc) Feed(ctx context.Context, userID int64, after string, limit int32) (Page, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+
+	var start *cursor
+	if after != "" {
+		c, err := decodeCursor(after)
+		if err != nil {
+			return Page{}, err
+		}
+		start = &c
+	}
+	cursorCreatedAt, cursorID := start.params()
+
+	// fetch one extra post to know whether there is another page
+	posts, err := s.repo.ListFeed(ctx, repo.ListFeedParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		RowLimit:        limit + 1,
+	})
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{Posts: posts}
+	if len(posts) > int(limit) {
+		page.Posts = posts[:limit]
+		last := page.Posts[limit-1]
+		page.NextCursor = cursor{CreatedAt: last.CreatedAt.Time, ID: last.ID}.encode()
+	}
+	if page.Posts == nil {
+		page.Posts = []repo.Post{}
+	}
+
+	return page, nil
+}
+
+// FollowingFeed merges recent posts and comments by followed users, newest first
+// Items created at the same moment are ordered posts first, then by descending id
+func (s *svc) FollowingFeed(ctx context.Context, userID int64, after string, limit int32) (ActivityPage, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+
+	var postsAfter, commentsAfter *cursor
+	if after != "" {
+		c, err := decodeCursor(after)
+		if err != nil {
+			return ActivityPage{}, err
+		}
+		if c.Kind != KindPost && c.Kind != KindComment {
+			return ActivityPage{}, ErrInvalidCursor
+		}
+
+		// each table needs its own bound for rows sharing the cursor's timestamp,
+		// posts there sort before comments so a comment cursor has no posts left at that time
+		postBound, commentBound := c, c
+		if c.Kind == KindComment {
+			postBound.ID = 0
+		} else {
+			commentBound.ID = math.MaxInt64
+		}
+		postsAfter, commentsAfter = &postBound, &commentBound
+	}
+
+	// fetch a full page plus one from both tables, the merge decides what makes it in
+	postCreatedAt, postID := postsAfter.params()
+	posts, err := s.repo.ListFollowedPosts(ctx, repo.ListFollowedPostsParams{
+		UserID:          userID,
+		CursorCreatedAt: postCreatedAt,
+		CursorID:        postID,
+		RowLimit:        limit + 1,
+	})
+	if err != nil {
+		return ActivityPage{}, err
+	}
+
+	commentCreatedAt, commentID := commentsAfter.params()
+	comments, err := s.repo.ListFollowedComments(ctx, repo.ListFollowedCommentsParams{
+		UserID:          userID,
+		CursorCreatedAt: commentCreatedAt,
+		CursorID:        commentID,
+		RowLimit:        limit + 1,
+	})
+	if err != nil {
+		return ActivityPage{}, err
+	}
+
+	items := make([]Activity, 0, len(posts)+len(comments))
+	for _, post := range posts {
+		items = append(items, Activity{
+			Kind:        KindPost,
+			ID:          post.ID,
+			UserID:      post.UserID,
+			Username:    post.Username,
+			TopicID:     post.TopicID,
+			PostID:      post.ID,
+			Title:       post.Title,
+			ContentHtml: post.ContentHtml,
+			CreatedAt:   post.CreatedAt,
+		})
+	}
+	for _, comment := range comments {
+		items = append(items, Activity{
+			Kind:        KindComment,
+			ID:          comment.ID,
+			UserID:      comment.UserID,
+			Username:    comment.Username,
+			TopicID:     comment.TopicID,
+			PostID:      comment.PostID,
+			Title:       comment.PostTitle,
+			ContentHtml: comment.ContentHtml,
+			CreatedAt:   comment.CreatedAt,
+		})
+	}
+
+	slices.SortFunc(items, func(a, b Activity) int {
+		if c := b.CreatedAt.Time.Compare(a.CreatedAt.Time); c != 0 {
+			return c
+		}
+		if a.Kind != b.Kind {
+			if a.Kind == KindPost {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	page := ActivityPage{Items: items}
+	if len(items) > int(limit) {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = cursor{CreatedAt: last.CreatedAt.Time, ID: last.ID, Kind: last.Kind}.encode()
+	}
+
+	return page, nil
+}
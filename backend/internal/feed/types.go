@@ -0,0 +1,64 @@
+package feed
+
+import (
+	"context"
+	"errors"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// kinds of items in the following feed
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+var (
+	ErrInvalidCursor = errors.New("cursor is not valid")
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+// Page is one page of the feed, NextCursor is empty on the last page
+type Page struct {
+	Posts      []repo.Post `json:"posts"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+// Activity is a post or comment by someone the user follows
+// For comments, Title is the title of the post they were left on
+type Activity struct {
+	Kind        string           `json:"kind"`
+	ID          int64            `json:"id"`
+	UserID      int64            `json:"user_id"`
+	Username    string           `json:"username"`
+	TopicID     int64            `json:"topic_id"`
+	PostID      int64            `json:"post_id"`
+	Title       string           `json:"title"`
+	ContentHtml string           `json:"content_html"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+// ActivityPage is one page of the following feed, NextCursor is empty on the last page
+type ActivityPage struct {
+	Items      []Activity `json:"items"`
+	NextCursor string     `json:"next_cursor"`
+}
+
+type Service interface {
+	Subscribe(ctx context.Context, userID int64, topicID int64) (repo.TopicSubscription, error)
+	Unsubscribe(ctx context.Context, userID int64, topicID int64) (repo.TopicSubscription, error)
+	ListSubscriptions(ctx context.Context, userID int64) ([]repo.Topic, error)
+	Feed(ctx context.Context, userID int64, after string, limit int32) (Page, error)
+	FollowingFeed(ctx context.Context, userID int64, after string, limit int32) (ActivityPage, error)
+}
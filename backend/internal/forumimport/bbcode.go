@@ -0,0 +1,118 @@
+package forumimport
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// phpBB 3.2+ stores posts as XML around the original BBCode, e.g. <r><B><s>[b]</s>bold<e>[/b]</e></B></r>
+	xmlTag = regexp.MustCompile(`<[^>]*>`)
+	// phpBB 3.0 and 3.1 mark smilies and magic links with comments, and show smilies as images
+	smileyImg   = regexp.MustCompile(`<img[^>]*alt="([^"]*)"[^>]*>`)
+	htmlComment = regexp.MustCompile(`<!--.*?-->`)
+	linkHTML    = regexp.MustCompile(`<a [^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+
+	bbcodeRules = []struct {
+		pattern *regexp.Regexp
+		replace string
+	}{
+		{regexp.MustCompile(`(?is)\[b\](.*?)\[/b\]`), "**$1**"},
+		{regexp.MustCompile(`(?is)\[i\](.*?)\[/i\]`), "*$1*"},
+		{regexp.MustCompile(`(?is)\[u\](.*?)\[/u\]`), "$1"},
+		{regexp.MustCompile(`(?is)\[s\](.*?)\[/s\]`), "~~$1~~"},
+		{regexp.MustCompile(`(?is)\[url=([^\]]+)\](.*?)\[/url\]`), "[$2]($1)"},
+		{regexp.MustCompile(`(?is)\[url\](.*?)\[/url\]`), "<$1>"},
+		{regexp.MustCompile(`(?is)\[email\](.*?)\[/email\]`), "<$1>"},
+		{regexp.MustCompile(`(?is)\[img\](.*?)\[/img\]`), "![]($1)"},
+		{regexp.MustCompile(`(?is)\[(?:color|size|font|align)=[^\]]*\](.*?)\[/(?:color|size|font|align)\]`), "$1"},
+		{regexp.MustCompile(`(?is)\[list(?:=[^\]]*)?\]`), "\n"},
+		{regexp.MustCompile(`(?i)\[/list(?::[a-z])?\]`), "\n"},
+		{regexp.MustCompile(`\[\*\]`), "\n- "},
+		{regexp.MustCompile(`(?i)\[/\*(?::m)?\]`), ""},
+	}
+
+	codeBlock = regexp.MustCompile(`(?is)\[code(?:=[^\]]*)?\](.*?)\[/code\]`)
+)
+
+// bbcodeToMarkdown converts a phpBB post into Markdown
+// uid is the bbcode_uid phpBB appends to the tags of the post, e.g. [b:2ab3cd4e]
+func bbcodeToMarkdown(text string, uid string) string {
+	if strings.HasPrefix(text, "<r>") || strings.HasPrefix(text, "<t>") {
+		text = xmlTag.ReplaceAllString(text, "")
+	} else {
+		text = smileyImg.ReplaceAllString(text, "$1")
+		text = htmlComment.ReplaceAllString(text, "")
+		text = linkHTML.ReplaceAllString(text, "[$2]($1)")
+	}
+
+	if uid != "" {
+		text = strings.ReplaceAll(text, ":"+uid+"]", "]")
+	}
+	text = html.UnescapeString(text)
+
+	// code is kept as it is, so it is held aside under a numbered marker until the other tags are converted
+	var blocks []string
+	text = codeBlock.ReplaceAllStringFunc(text, func(m string) string {
+		code := codeBlock.FindStringSubmatch(m)[1]
+		blocks = append(blocks, "\n```\n"+strings.Trim(code, "\n")+"\n```\n")
+		return fmt.Sprintf("\x00%d\x00", len(blocks)-1)
+	})
+
+	for _, rule := range bbcodeRules {
+		text = rule.pattern.ReplaceAllString(text, rule.replace)
+	}
+	text = convertQuotes(text)
+
+	for i, block := range blocks {
+		text = restoreBlock(text, fmt.Sprintf("\x00%d\x00", i), block)
+	}
+	return strings.TrimSpace(text)
+}
+
+// restoreBlock puts a code block back in place of its marker, quoted as deeply as the line it ended up on
+func restoreBlock(text string, marker string, block string) string {
+	at := strings.Index(text, marker)
+	if at < 0 {
+		return text
+	}
+
+	lineStart := strings.LastIndexByte(text[:at], '\n') + 1
+	if prefix := text[lineStart:at]; prefix != "" && strings.Trim(prefix, "> ") == "" {
+		block = strings.ReplaceAll(block, "\n", "\n"+prefix)
+	}
+	return text[:at] + block + text[at+len(marker):]
+}
+
+// convertQuotes turns [quote] blocks into Markdown block quotes, innermost first so nested quotes nest
+func convertQuotes(text string) string {
+	for {
+		start := strings.LastIndex(text, "[quote")
+		if start < 0 {
+			return text
+		}
+		openEnd := strings.IndexByte(text[start:], ']')
+		end := strings.Index(text[start:], "[/quote]")
+		if openEnd < 0 || end < openEnd {
+			return text
+		}
+
+		// [quote="name" post_id=1 time=2] in phpBB 3.2+, [quote="name"] or [quote=name] before it
+		author := strings.TrimPrefix(text[start+len("[quote"):start+openEnd], "=")
+		if strings.HasPrefix(author, `"`) {
+			author, _, _ = strings.Cut(author[1:], `"`)
+		} else {
+			author, _, _ = strings.Cut(author, " ")
+		}
+
+		body := strings.Trim(text[start+openEnd+1:start+end], "\n")
+		if author != "" {
+			body = author + " wrote:\n\n" + body
+		}
+		quoted := "\n> " + strings.ReplaceAll(body, "\n", "\n> ") + "\n\n"
+
+		text = text[:start] + quoted + text[start+end+len("[/quote]"):]
+	}
+}
@@ -0,0 +1,98 @@
+package forumimport
+
+import "testing"
+
+func TestBBCodeToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		uid  string
+		want string
+	}{
+		{
+			name: "inline tags",
+			text: "[b]bold[/b], [i]italic[/i], [u]under[/u] and [s]gone[/s]",
+			want: "**bold**, *italic*, under and ~~gone~~",
+		},
+		{
+			name: "uid is stripped from every tag",
+			text: "[b:2ab3cd4e]bold[/b:2ab3cd4e] [url=https://example.com:2ab3cd4e]site[/url:2ab3cd4e]",
+			uid:  "2ab3cd4e",
+			want: "**bold** [site](https://example.com)",
+		},
+		{
+			name: "uid of another post is left alone",
+			text: "[b:2ab3cd4e]bold[/b:2ab3cd4e]",
+			uid:  "ffffffff",
+			want: "[b:2ab3cd4e]bold[/b:2ab3cd4e]",
+		},
+		{
+			name: "links and images",
+			text: "[url]https://example.com[/url] [img]https://example.com/a.png[/img]",
+			want: "<https://example.com> ![](https://example.com/a.png)",
+		},
+		{
+			name: "html entities are unescaped",
+			text: "fish &amp; chips &lt;3 &quot;yes&quot;",
+			want: `fish & chips <3 "yes"`,
+		},
+		{
+			name: "code is kept as it is",
+			text: "[code]if a [b]x[/b] then[/code]",
+			want: "```\nif a [b]x[/b] then\n```",
+		},
+		{
+			name: "code inside a quote stays quoted",
+			text: "[quote]look:\n[code]a\nb[/code][/quote]",
+			want: "> look:\n> \n> ```\n> a\n> b\n> ```\n>",
+		},
+		{
+			name: "quote without an author",
+			text: "[quote]first line\nsecond line[/quote]",
+			want: "> first line\n> second line",
+		},
+		{
+			name: "quote with a quoted author and phpBB 3.2 attributes",
+			text: `[quote="alice" post_id=1 time=2]hi[/quote]reply`,
+			want: "> alice wrote:\n> \n> hi\n\nreply",
+		},
+		{
+			name: "quote with a bare author",
+			text: "[quote=bob]hi[/quote]",
+			want: "> bob wrote:\n> \n> hi",
+		},
+		{
+			name: "nested quotes",
+			text: "[quote=a]outer [quote=b]inner[/quote][/quote]",
+			want: "> a wrote:\n> \n> outer \n> > b wrote:\n> > \n> > inner",
+		},
+		{
+			name: "unterminated quote is left alone",
+			text: "[quote]never closed",
+			want: "[quote]never closed",
+		},
+		{
+			name: "phpBB 3.2 XML",
+			text: "<r><B><s>[b]</s>bold<e>[/b]</e></B> &amp; more</r>",
+			want: "**bold** & more",
+		},
+		{
+			name: "phpBB 3.0 smilies and magic links",
+			text: `<!-- s:) --><img src="smile.gif" alt=":)" /><!-- s:) --> <!-- m --><a class="postlink" href="https://example.com">example</a><!-- m -->`,
+			want: ":) [example](https://example.com)",
+		},
+		{
+			name: "lists",
+			text: "[list][*]one[*]two[/list]",
+			want: "- one\n- two",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bbcodeToMarkdown(tt.text, tt.uid); got != tt.want {
+				t.Errorf("bbcodeToMarkdown(%q, %q)\n got %q\nwant %q", tt.text, tt.uid, got, tt.want)
+			}
+		})
+	}
+}
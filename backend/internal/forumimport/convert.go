@@ -0,0 +1,400 @@
+package forumimport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/backup"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/markdown"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/tags"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/users"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// converter assigns every record of the forum its ID in the export and keeps track of the placeholders it made
+type converter struct {
+	source   string
+	exportID string
+	now      time.Time
+	report   Report
+
+	// the database the export is going to be imported into
+	q      *repo.Queries
+	filter *contentfilter.Pipeline
+
+	users    []backup.User
+	userIDs  map[string]int64 // source user ID to export ID
+	accounts map[string]int64 // username to export ID
+
+	// placeholder name to export ID, the account may have been exported under another name when this one was taken
+	placeholders map[string]int64
+}
+
+// Convert writes the forum as a regular export, ready for the import command
+// exportID has to stay the same for the same source data, so that converting and importing it again skips what is already there
+// q reads the database the export will be imported into, so that source users never take over a local account of the same name
+// Every thread and reply goes through filter like a new post, its checks can't rely on the author since none of them exist yet
+func Convert(ctx context.Context, w io.Writer, f Forum, source string, exportID string, q *repo.Queries, filter *contentfilter.Pipeline) (Report, error) {
+	c := &converter{
+		source:   source,
+		exportID: exportID,
+		now:      time.Now().UTC(),
+		report:   Report{Converted: map[string]int64{}, Skipped: f.Skipped},
+		q:        q,
+		filter:   filter,
+		userIDs:  map[string]int64{},
+		accounts: map[string]int64{},
+
+		placeholders: map[string]int64{},
+	}
+
+	for _, u := range f.Users {
+		name := strings.TrimSpace(u.Username)
+		switch {
+		case u.ID == "" || name == "":
+			c.skip(TypeUser, u.ID, "user has no ID or username")
+		case c.userIDs[u.ID] != 0:
+			c.skip(TypeUser, u.ID, "duplicate user ID")
+		case c.accounts[name] != 0:
+			// the import would merge them anyway since usernames are unique
+			c.userIDs[u.ID] = c.accounts[name]
+			c.skip(TypeUser, u.ID, fmt.Sprintf("username %q is already used by another user, their content was merged", name))
+		default:
+			username, err := c.freeUsername(ctx, name)
+			if err != nil {
+				return c.report, err
+			}
+			c.userIDs[u.ID] = c.addUser(username, u.CreatedAt)
+		}
+	}
+
+	var topics []repo.Topic
+	topicIDs := map[string]int64{}
+	for _, cat := range f.Categories {
+		name := strings.TrimSpace(cat.Name)
+		switch {
+		case cat.ID == "" || name == "":
+			c.skip(TypeCategory, cat.ID, "category has no ID or name")
+		case topicIDs[cat.ID] != 0:
+			c.skip(TypeCategory, cat.ID, "duplicate category ID")
+		default:
+			ownerID, owner, err := c.placeholder(ctx, c.source+"-import")
+			if err != nil {
+				return c.report, err
+			}
+			topic := repo.Topic{
+				ID:          int64(len(topics) + 1),
+				Name:        name,
+				Description: cat.Description,
+				UserID:      ownerID,
+				Username:    owner,
+				CreatedAt:   c.timestamp(cat.CreatedAt),
+			}
+			topics = append(topics, topic)
+			topicIDs[cat.ID] = topic.ID
+		}
+	}
+
+	var posts []repo.ExportPostsRow
+	postIDs := map[string]int64{}
+	for _, t := range f.Threads {
+		topicID, ok := topicIDs[t.CategoryID]
+		switch {
+		case t.ID == "":
+			c.skip(TypeThread, t.ID, "thread has no ID")
+			continue
+		case postIDs[t.ID] != 0:
+			c.skip(TypeThread, t.ID, "duplicate thread ID")
+			continue
+		case !ok:
+			c.skip(TypeThread, t.ID, fmt.Sprintf("category %q was not imported", t.CategoryID))
+			continue
+		case strings.TrimSpace(t.Title) == "" || strings.TrimSpace(t.Body) == "":
+			c.skip(TypeThread, t.ID, "thread has no title or body")
+			continue
+		}
+
+		sub := contentfilter.Submission{Title: strings.TrimSpace(t.Title), Content: t.Body}
+		blocked, err := c.runFilter(ctx, &sub)
+		if err != nil {
+			return c.report, err
+		}
+		if blocked != "" {
+			c.skip(TypeThread, t.ID, blocked)
+			continue
+		}
+
+		contentHtml, err := markdown.Render(sub.Content)
+		if err != nil {
+			c.skip(TypeThread, t.ID, "body could not be rendered: "+err.Error())
+			continue
+		}
+
+		userID, username, err := c.author(ctx, t.AuthorID, t.AuthorName)
+		if err != nil {
+			return c.report, err
+		}
+		post := repo.ExportPostsRow{
+			ID:          int64(len(posts) + 1),
+			Title:       sub.Title,
+			Content:     sub.Content,
+			ContentHtml: contentHtml,
+			UserID:      userID,
+			Username:    username,
+			TopicID:     topicID,
+			CreatedAt:   c.timestamp(t.CreatedAt),
+			Status:      sub.Status(),
+			FlagReason:  sub.FlagReason(),
+			ContentHash: sub.Hash,
+			Pinned:      t.Pinned,
+			Locked:      t.Locked,
+			Tags:        convertTags(t.Tags),
+		}
+		posts = append(posts, post)
+		postIDs[t.ID] = post.ID
+	}
+
+	var comments []repo.Comment
+	commentIDs := map[string]bool{}
+	for _, r := range f.Replies {
+		postID, ok := postIDs[r.ThreadID]
+		switch {
+		case r.ID == "":
+			c.skip(TypeReply, r.ID, "reply has no ID")
+			continue
+		case commentIDs[r.ID]:
+			c.skip(TypeReply, r.ID, "duplicate reply ID")
+			continue
+		case !ok:
+			c.skip(TypeReply, r.ID, fmt.Sprintf("thread %q was not imported", r.ThreadID))
+			continue
+		case strings.TrimSpace(r.Body) == "":
+			c.skip(TypeReply, r.ID, "reply has no body")
+			continue
+		}
+
+		sub := contentfilter.Submission{Content: r.Body}
+		blocked, err := c.runFilter(ctx, &sub)
+		if err != nil {
+			return c.report, err
+		}
+		if blocked != "" {
+			c.skip(TypeReply, r.ID, blocked)
+			continue
+		}
+
+		contentHtml, err := markdown.Render(sub.Content)
+		if err != nil {
+			c.skip(TypeReply, r.ID, "body could not be rendered: "+err.Error())
+			continue
+		}
+
+		userID, username, err := c.author(ctx, r.AuthorID, r.AuthorName)
+		if err != nil {
+			return c.report, err
+		}
+		comments = append(comments, repo.Comment{
+			ID:          int64(len(comments) + 1),
+			Content:     sub.Content,
+			ContentHtml: contentHtml,
+			UserID:      userID,
+			Username:    username,
+			PostID:      postID,
+			CreatedAt:   c.timestamp(r.CreatedAt),
+			Status:      sub.Status(),
+			FlagReason:  sub.FlagReason(),
+			ContentHash: sub.Hash,
+		})
+		commentIDs[r.ID] = true
+	}
+
+	c.report.Converted[TypeUser] = int64(len(c.users))
+	c.report.Converted[TypeCategory] = int64(len(topics))
+	c.report.Converted[TypeThread] = int64(len(posts))
+	c.report.Converted[TypeReply] = int64(len(comments))
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	err := enc.Encode(backup.Header{Format: backup.Format, Version: backup.Version, ID: exportID, ExportedAt: c.now})
+	if err != nil {
+		return c.report, err
+	}
+	if err := writeRecords(enc, backup.KindUser, c.users); err != nil {
+		return c.report, err
+	}
+	if err := writeRecords(enc, backup.KindTopic, topics); err != nil {
+		return c.report, err
+	}
+	if err := writeRecords(enc, backup.KindPost, posts); err != nil {
+		return c.report, err
+	}
+	if err := writeRecords(enc, backup.KindComment, comments); err != nil {
+		return c.report, err
+	}
+
+	return c.report, buf.Flush()
+}
+
+func writeRecords[T any](enc *json.Encoder, kind string, rows []T) error {
+	for _, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(backup.Record{Kind: kind, Data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *converter) skip(recordType string, id string, reason string) {
+	c.report.Skipped = append(c.report.Skipped, Skipped{Type: recordType, ID: id, Reason: reason})
+}
+
+func (c *converter) addUser(username string, createdAt time.Time) int64 {
+	id := int64(len(c.users) + 1)
+	c.users = append(c.users, backup.User{
+		ID:        id,
+		Username:  username,
+		Role:      users.RoleUser,
+		CreatedAt: c.timestamp(createdAt),
+	})
+	c.accounts[username] = id
+	return id
+}
+
+// placeholder returns the account with the given name, creating a placeholder the first time it is needed
+// The name goes through freeUsername like any other, so a local account that has it never takes over the placeholder's content
+func (c *converter) placeholder(ctx context.Context, name string) (int64, string, error) {
+	if id, ok := c.placeholders[name]; ok {
+		return id, c.users[id-1].Username, nil
+	}
+
+	username, err := c.freeUsername(ctx, name)
+	if err != nil {
+		return 0, "", err
+	}
+	c.report.Placeholders = append(c.report.Placeholders, username)
+	id := c.addUser(username, time.Time{})
+	c.placeholders[name] = id
+	return id, username, nil
+}
+
+// author returns the account a thread or reply is credited to
+// Placeholders carry the source as a prefix, which keeps them apart from source users, and are renamed when a local account has the name
+func (c *converter) author(ctx context.Context, authorID string, authorName string) (int64, string, error) {
+	if id, ok := c.userIDs[authorID]; ok && authorID != "" {
+		return id, c.users[id-1].Username, nil
+	}
+
+	name := strings.TrimSpace(authorName)
+	switch {
+	case name != "":
+		name = fmt.Sprintf("%s-guest-%s", c.source, name)
+	case authorID != "":
+		name = fmt.Sprintf("%s-user-%s", c.source, authorID)
+	default:
+		name = c.source + "-guest"
+	}
+	return c.placeholder(ctx, name)
+}
+
+// freeUsername returns the name a source user or placeholder is exported under
+// A name taken by a local account gets the source as a prefix, unless it already has it, and a number after it until it is free,
+// unless the account is the one an earlier import of this export created
+func (c *converter) freeUsername(ctx context.Context, name string) (string, error) {
+	base := name
+	if !strings.HasPrefix(name, c.source+"-") {
+		base = c.source + "-" + name
+	}
+
+	candidate := name
+	for n := 1; ; {
+		taken, err := c.taken(ctx, candidate)
+		if err != nil || !taken {
+			if candidate != name {
+				c.report.Renamed = append(c.report.Renamed, Renamed{From: name, To: candidate})
+			}
+			return candidate, err
+		}
+		if candidate == name && base != name {
+			candidate = base
+			continue
+		}
+		n++
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// taken reports whether username is already used, by a local account or by a user converted before it
+func (c *converter) taken(ctx context.Context, username string) (bool, error) {
+	if c.accounts[username] != 0 {
+		return true, nil
+	}
+
+	user, err := c.q.FetchUserByUsername(ctx, username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// the user this name would get in the export, when an earlier run imported it as this account the name is still ours
+	next := int64(len(c.users) + 1)
+	imported, err := c.q.GetImportMapping(ctx, repo.GetImportMappingParams{Source: c.exportID, Kind: backup.KindUser, OldID: next})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return imported != user.ID, nil
+}
+
+// runFilter passes a thread or reply through the content filter, returning the reason when the filter blocks it
+func (c *converter) runFilter(ctx context.Context, sub *contentfilter.Submission) (string, error) {
+	err := c.filter.Run(ctx, c.q, sub)
+	var blocked *contentfilter.BlockedError
+	if errors.As(err, &blocked) {
+		return "blocked by the content filter: " + blocked.Reason, nil
+	}
+	return "", err
+}
+
+// timestamp stores times in UTC like the rest of the database, records without one get the time of the conversion
+func (c *converter) timestamp(t time.Time) pgtype.Timestamp {
+	if t.IsZero() {
+		t = c.now
+	}
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}
+}
+
+// convertTags keeps the tags that are valid here, up to the limit a post can carry
+func convertTags(names []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		tag, err := tags.Normalize(name)
+		if err != nil || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+		if len(out) == tags.MaxPerPost {
+			break
+		}
+	}
+	return out
+}
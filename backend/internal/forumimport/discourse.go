@@ -0,0 +1,164 @@
+package forumimport
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// discourseDump is the shape ReadDiscourse expects, the lists Discourse returns from its admin and topic APIs
+// (/admin/users/list/all.json, /categories.json, /t/{id}.json, /posts/{id}.json with raw) gathered into one document
+type discourseDump struct {
+	Users []struct {
+		ID        int64     `json:"id"`
+		Username  string    `json:"username"`
+		CreatedAt time.Time `json:"created_at"`
+	} `json:"users"`
+	Categories []struct {
+		ID             int64     `json:"id"`
+		Name           string    `json:"name"`
+		Description    string    `json:"description_text"`
+		ReadRestricted bool      `json:"read_restricted"`
+		CreatedAt      time.Time `json:"created_at"`
+	} `json:"categories"`
+	Topics []struct {
+		ID         int64             `json:"id"`
+		Title      string            `json:"title"`
+		CategoryID int64             `json:"category_id"`
+		UserID     int64             `json:"user_id"`
+		CreatedAt  time.Time         `json:"created_at"`
+		Pinned     bool              `json:"pinned"`
+		Closed     bool              `json:"closed"`
+		Archetype  string            `json:"archetype"`
+		DeletedAt  *time.Time        `json:"deleted_at"`
+		Tags       []json.RawMessage `json:"tags"`
+	} `json:"topics"`
+	Posts []struct {
+		ID         int64      `json:"id"`
+		TopicID    int64      `json:"topic_id"`
+		UserID     int64      `json:"user_id"`
+		Username   string     `json:"username"`
+		PostNumber int        `json:"post_number"`
+		PostType   int        `json:"post_type"`
+		Raw        string     `json:"raw"`
+		CreatedAt  time.Time  `json:"created_at"`
+		DeletedAt  *time.Time `json:"deleted_at"`
+	} `json:"posts"`
+}
+
+// regular posts, the other types are moderator notes, small actions like "closed this topic" and whispers
+const discoursePostRegular = 1
+
+// ReadDiscourse reads a Discourse JSON dump
+// Subcategories become topics of their own, and private messages, whispers and deleted content are skipped
+func ReadDiscourse(r io.Reader) (Forum, error) {
+	var dump discourseDump
+	if err := json.NewDecoder(r).Decode(&dump); err != nil {
+		return Forum{}, err
+	}
+
+	var f Forum
+	for _, u := range dump.Users {
+		f.Users = append(f.Users, User{ID: id(u.ID), Username: u.Username, CreatedAt: u.CreatedAt})
+	}
+
+	for _, c := range dump.Categories {
+		if c.ReadRestricted {
+			f.skip(TypeCategory, id(c.ID), "category is private")
+			continue
+		}
+		f.Categories = append(f.Categories, Category{ID: id(c.ID), Name: c.Name, Description: c.Description, CreatedAt: c.CreatedAt})
+	}
+
+	// the first post of a Discourse topic is the body of the thread
+	firstPosts := map[int64]int{}
+	for i, p := range dump.Posts {
+		if p.PostNumber == 1 {
+			firstPosts[p.TopicID] = i
+		}
+	}
+
+	threads := map[int64]bool{}
+	for _, t := range dump.Topics {
+		switch {
+		case t.Archetype == "private_message":
+			f.skip(TypeThread, id(t.ID), "private message")
+			continue
+		case t.DeletedAt != nil:
+			f.skip(TypeThread, id(t.ID), "topic was deleted")
+			continue
+		}
+
+		i, ok := firstPosts[t.ID]
+		if !ok {
+			f.skip(TypeThread, id(t.ID), "topic has no first post")
+			continue
+		}
+		first := dump.Posts[i]
+
+		f.Threads = append(f.Threads, Thread{
+			ID:         id(t.ID),
+			CategoryID: id(t.CategoryID),
+			AuthorID:   id(t.UserID),
+			AuthorName: first.Username,
+			Title:      t.Title,
+			Body:       first.Raw,
+			CreatedAt:  t.CreatedAt,
+			Pinned:     t.Pinned,
+			Locked:     t.Closed,
+			Tags:       discourseTags(t.Tags),
+		})
+		threads[t.ID] = true
+	}
+
+	for _, p := range dump.Posts {
+		switch {
+		case p.PostNumber == 1:
+			continue
+		case !threads[p.TopicID]:
+			// the whole topic was skipped already, its replies go with it
+			continue
+		case p.PostType != discoursePostRegular:
+			f.skip(TypeReply, id(p.ID), "not a regular post (moderator note, small action or whisper)")
+			continue
+		case p.DeletedAt != nil:
+			f.skip(TypeReply, id(p.ID), "post was deleted")
+			continue
+		}
+
+		f.Replies = append(f.Replies, Reply{
+			ID:         id(p.ID),
+			ThreadID:   id(p.TopicID),
+			AuthorID:   id(p.UserID),
+			AuthorName: p.Username,
+			Body:       p.Raw,
+			CreatedAt:  p.CreatedAt,
+		})
+	}
+
+	return f, nil
+}
+
+// discourseTags accepts tags as plain names or as {"name": ...} objects, newer versions return the latter
+func discourseTags(raw []json.RawMessage) []string {
+	names := make([]string, 0, len(raw))
+	for _, r := range raw {
+		var name string
+		if json.Unmarshal(r, &name) == nil {
+			names = append(names, name)
+			continue
+		}
+		var tag struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(r, &tag) == nil && tag.Name != "" {
+			names = append(names, tag.Name)
+		}
+	}
+	return names
+}
+
+func id(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,30 @@
+// Package forumimport moves the content of another forum into this one
+//
+// Adapters read the export of another forum (Discourse, phpBB) into the intermediate format below,
+// which can also be written by hand or by a script for any other forum.
+// Convert then turns it into a regular export (see the backup package) that the import command loads.
+//
+// # Intermediate format
+//
+// JSON Lines, one object per line with a "type" of user, category, thread or reply. Lines may come in any order.
+// IDs are strings chosen by the source forum, they only have to be unique within their type.
+// Times are RFC 3339, for example "2019-05-01T10:30:00Z", and default to the time of the import when left out.
+//
+//	{"type": "user", "id": "7", "username": "alice", "created_at": "2019-05-01T10:30:00Z"}
+//	{"type": "category", "id": "2", "name": "General", "description": "Anything goes"}
+//	{"type": "thread", "id": "40", "category_id": "2", "author_id": "7", "title": "Hello", "body": "First **post**",
+//	 "created_at": "2019-05-02T08:00:00Z", "pinned": false, "locked": false, "tags": ["intro"]}
+//	{"type": "reply", "id": "41", "thread_id": "40", "author_id": "9", "author_name": "bob", "body": "Welcome!"}
+//
+// Categories become topics, threads become posts and replies become comments. Bodies are Markdown.
+// A thread or reply whose author is missing from the users gets a placeholder account, named
+// <source>-guest-<author_name> when the source has a name for them (guest posts) and otherwise <source>-user-<author_id>.
+// Categories have no owner in most forums, so their topics belong to a <source>-import placeholder.
+// A placeholder whose name a local account already has gets a number after it, <source>-import-2 and so on.
+// Users whose username is already taken by an account here are renamed to <source>-<username> instead of
+// being merged into it, the Report lists every rename. Threads and replies go through the content filter
+// like new posts: blocked ones are skipped, flagged ones are imported as pending.
+//
+// Records that can't be imported, such as a reply to a thread that doesn't exist, are skipped and
+// listed in the Report together with the reason, instead of failing the whole import.
+package forumimport
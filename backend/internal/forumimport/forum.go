@@ -0,0 +1,96 @@
+package forumimport
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReadForum reads the intermediate format
+func ReadForum(r io.Reader) (Forum, error) {
+	var f Forum
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	for n := 1; dec.More(); n++ {
+		var line json.RawMessage
+		if err := dec.Decode(&line); err != nil {
+			return f, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		var head struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(line, &head); err != nil {
+			return f, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		var err error
+		switch head.Type {
+		case TypeUser:
+			var u User
+			err = json.Unmarshal(line, &u)
+			f.Users = append(f.Users, u)
+		case TypeCategory:
+			var c Category
+			err = json.Unmarshal(line, &c)
+			f.Categories = append(f.Categories, c)
+		case TypeThread:
+			var t Thread
+			err = json.Unmarshal(line, &t)
+			f.Threads = append(f.Threads, t)
+		case TypeReply:
+			var r Reply
+			err = json.Unmarshal(line, &r)
+			f.Replies = append(f.Replies, r)
+		default:
+			err = fmt.Errorf("unknown type %q", head.Type)
+		}
+		if err != nil {
+			return f, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+
+	return f, nil
+}
+
+// WriteForum writes a forum in the intermediate format, so what an adapter read can be checked or fixed by hand
+func WriteForum(w io.Writer, f Forum) error {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	for _, u := range f.Users {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			User
+		}{TypeUser, u}); err != nil {
+			return err
+		}
+	}
+	for _, c := range f.Categories {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			Category
+		}{TypeCategory, c}); err != nil {
+			return err
+		}
+	}
+	for _, t := range f.Threads {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			Thread
+		}{TypeThread, t}); err != nil {
+			return err
+		}
+	}
+	for _, r := range f.Replies {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			Reply
+		}{TypeReply, r}); err != nil {
+			return err
+		}
+	}
+
+	return buf.Flush()
+}
@@ -0,0 +1,134 @@
+package forumimport
+
+import (
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// phpBB column values the adapter cares about
+const (
+	phpbbUserIgnore    = "2" // users.user_type of bots and the anonymous guest account
+	phpbbForumPost     = "1" // forums.forum_type of forums that hold topics, 0 is a category and 2 a link
+	phpbbTopicLocked   = "1" // topics.topic_status
+	phpbbTopicNormal   = "0" // topics.topic_type, anything else is a sticky or an announcement
+	phpbbItemApproved  = "1" // topics.topic_visibility and posts.post_visibility
+	phpbbNoMovedTarget = "0" // topics.topic_moved_id, set on the shadow left behind when a topic is moved
+)
+
+// ReadPhpBB reads the users, forums, topics and posts tables out of a MySQL dump of a phpBB 3 board
+// Any table prefix works. Forums become categories, and phpBB categories and link forums are skipped since
+// topics here can't be nested. The dump needs column names in its INSERT statements (mysqldump --complete-insert)
+func ReadPhpBB(r io.Reader) (Forum, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Forum{}, err
+	}
+
+	tables, err := readInserts(string(data), phpbbTable)
+	if err != nil {
+		return Forum{}, err
+	}
+
+	var f Forum
+	for _, u := range tables["users"] {
+		if u["user_type"] == phpbbUserIgnore {
+			// their posts are credited to a placeholder named after post_username instead
+			continue
+		}
+		f.Users = append(f.Users, User{ID: u["user_id"], Username: unescape(u["username"]), CreatedAt: unixTime(u["user_regdate"])})
+	}
+
+	for _, forum := range tables["forums"] {
+		if forum["forum_type"] != phpbbForumPost {
+			f.skip(TypeCategory, forum["forum_id"], "phpBB category or link, its forums are imported on their own")
+			continue
+		}
+		f.Categories = append(f.Categories, Category{
+			ID:          forum["forum_id"],
+			Name:        unescape(forum["forum_name"]),
+			Description: bbcodeToMarkdown(forum["forum_desc"], forum["forum_desc_uid"]),
+		})
+	}
+
+	firstPosts := map[string]string{} // topic_first_post_id to topic_id
+	threads := map[string]*Thread{}
+	for _, t := range tables["topics"] {
+		switch {
+		case t["topic_moved_id"] != "" && t["topic_moved_id"] != phpbbNoMovedTarget:
+			f.skip(TypeThread, t["topic_id"], "shadow of a moved topic")
+			continue
+		case t["topic_visibility"] != "" && t["topic_visibility"] != phpbbItemApproved:
+			f.skip(TypeThread, t["topic_id"], "topic is unapproved or deleted")
+			continue
+		}
+
+		f.Threads = append(f.Threads, Thread{
+			ID:         t["topic_id"],
+			CategoryID: t["forum_id"],
+			AuthorID:   t["topic_poster"],
+			AuthorName: unescape(t["topic_first_poster_name"]),
+			Title:      unescape(t["topic_title"]),
+			CreatedAt:  unixTime(t["topic_time"]),
+			Pinned:     t["topic_type"] != "" && t["topic_type"] != phpbbTopicNormal,
+			Locked:     t["topic_status"] == phpbbTopicLocked,
+		})
+		firstPosts[t["topic_first_post_id"]] = t["topic_id"]
+	}
+	for i := range f.Threads {
+		threads[f.Threads[i].ID] = &f.Threads[i]
+	}
+
+	for _, p := range tables["posts"] {
+		thread, ok := threads[p["topic_id"]]
+		switch {
+		case !ok:
+			// replies to topics that were skipped or never existed are reported by Convert
+		case firstPosts[p["post_id"]] == p["topic_id"]:
+			// the first post is the body of the topic
+			thread.Body = bbcodeToMarkdown(p["post_text"], p["bbcode_uid"])
+			continue
+		}
+
+		if p["post_visibility"] != "" && p["post_visibility"] != phpbbItemApproved {
+			f.skip(TypeReply, p["post_id"], "post is unapproved or deleted")
+			continue
+		}
+
+		f.Replies = append(f.Replies, Reply{
+			ID:         p["post_id"],
+			ThreadID:   p["topic_id"],
+			AuthorID:   p["poster_id"],
+			AuthorName: unescape(p["post_username"]),
+			Body:       bbcodeToMarkdown(p["post_text"], p["bbcode_uid"]),
+			CreatedAt:  unixTime(p["post_time"]),
+		})
+	}
+
+	return f, nil
+}
+
+// phpbbTable returns which of the needed tables name is, whatever prefix the board was installed with
+func phpbbTable(name string) string {
+	for _, table := range []string{"users", "forums", "topics", "posts"} {
+		if name == table || strings.HasSuffix(name, "_"+table) {
+			return table
+		}
+	}
+	return ""
+}
+
+// unescape undoes the entity encoding phpBB applies to names and titles
+func unescape(s string) string {
+	return html.UnescapeString(s)
+}
+
+func unixTime(s string) time.Time {
+	secs, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || secs <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0).UTC()
+}
@@ -0,0 +1,297 @@
+package forumimport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// row is one row of an INSERT statement by column name, NULL reads as an empty string
+type row map[string]string
+
+// readInserts collects the rows inserted into the tables of a MySQL dump that table returns a name for
+// Every other statement is ignored, which keeps the parser small: it only has to tell strings, comments and
+// the punctuation of INSERT ... VALUES (...), (...) apart
+func readInserts(dump string, table func(name string) string) (map[string][]row, error) {
+	l := &lexer{src: dump}
+	rows := map[string][]row{}
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokEOF {
+			return rows, nil
+		}
+
+		// anything that is not an INSERT is skipped up to the end of its statement
+		if tok.kind != tokWord || !strings.EqualFold(tok.text, "INSERT") {
+			if err := l.skipStatement(tok); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		name, columns, err := l.insertHeader()
+		if err != nil {
+			return nil, err
+		}
+
+		wanted := table(name)
+		if wanted != "" && columns == nil {
+			return nil, fmt.Errorf("table %s: %w", name, ErrNoColumnList)
+		}
+
+		for {
+			values, err := l.tuple()
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %w", name, err)
+			}
+			if wanted != "" {
+				if len(values) != len(columns) {
+					return nil, fmt.Errorf("table %s: %d values for %d columns", name, len(values), len(columns))
+				}
+				r := make(row, len(columns))
+				for i, c := range columns {
+					r[c] = values[i]
+				}
+				rows[wanted] = append(rows[wanted], r)
+			}
+
+			sep, err := l.next()
+			if err != nil {
+				return nil, err
+			}
+			if sep.kind == tokPunct && sep.text == "," {
+				continue
+			}
+			if sep.kind == tokPunct && sep.text == ";" || sep.kind == tokEOF {
+				break
+			}
+			return nil, fmt.Errorf("table %s: unexpected %q after a row", name, sep.text)
+		}
+	}
+}
+
+const (
+	tokEOF = iota
+	tokWord
+	tokString
+	tokPunct
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.pos++
+		case c == '#' || strings.HasPrefix(l.src[l.pos:], "-- ") || strings.HasPrefix(l.src[l.pos:], "--\n"):
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			if end < 0 {
+				l.pos = len(l.src)
+			} else {
+				l.pos += end + 1
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return token{}, fmt.Errorf("unterminated comment")
+			}
+			l.pos += end + 4
+		case c == '\'' || c == '"':
+			s, err := l.quoted(c)
+			return token{tokString, s}, err
+		case c == '`':
+			end := strings.IndexByte(l.src[l.pos+1:], '`')
+			if end < 0 {
+				return token{}, fmt.Errorf("unterminated identifier")
+			}
+			word := l.src[l.pos+1 : l.pos+1+end]
+			l.pos += end + 2
+			return token{tokWord, word}, nil
+		case isWordByte(c):
+			start := l.pos
+			for l.pos < len(l.src) && isWordByte(l.src[l.pos]) {
+				l.pos++
+			}
+			return token{tokWord, l.src[start:l.pos]}, nil
+		default:
+			l.pos++
+			return token{tokPunct, string(c)}, nil
+		}
+	}
+	return token{kind: tokEOF}, nil
+}
+
+// quoted reads a MySQL string literal, undoing backslash escapes and doubled quotes
+func (l *lexer) quoted(quote byte) (string, error) {
+	var b strings.Builder
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.src):
+			l.pos += 2
+			switch e := l.src[l.pos-1]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '0':
+				b.WriteByte(0)
+			case 'Z':
+				b.WriteByte(26)
+			default:
+				b.WriteByte(e)
+			}
+		case c == quote && l.pos+1 < len(l.src) && l.src[l.pos+1] == quote:
+			b.WriteByte(quote)
+			l.pos += 2
+		case c == quote:
+			l.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// skipStatement moves past the ; ending the statement that started with tok
+func (l *lexer) skipStatement(tok token) error {
+	for !(tok.kind == tokPunct && tok.text == ";") && tok.kind != tokEOF {
+		var err error
+		if tok, err = l.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertHeader reads `[IGNORE] INTO name [(columns)] VALUES`, columns is nil when the statement has no column list
+func (l *lexer) insertHeader() (string, []string, error) {
+	tok, err := l.next()
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.EqualFold(tok.text, "IGNORE") {
+		if tok, err = l.next(); err != nil {
+			return "", nil, err
+		}
+	}
+	if !strings.EqualFold(tok.text, "INTO") {
+		return "", nil, fmt.Errorf("expected INTO after INSERT, got %q", tok.text)
+	}
+
+	name, err := l.next()
+	if err != nil {
+		return "", nil, err
+	}
+	if name.kind != tokWord {
+		return "", nil, fmt.Errorf("expected a table name after INSERT INTO, got %q", name.text)
+	}
+
+	tok, err = l.next()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var columns []string
+	if tok.kind == tokPunct && tok.text == "(" {
+		for {
+			col, err := l.next()
+			if err != nil {
+				return "", nil, err
+			}
+			if col.kind != tokWord {
+				return "", nil, fmt.Errorf("table %s: bad column list", name.text)
+			}
+			columns = append(columns, col.text)
+
+			sep, err := l.next()
+			if err != nil {
+				return "", nil, err
+			}
+			if sep.text == ")" {
+				break
+			}
+			if sep.text != "," {
+				return "", nil, fmt.Errorf("table %s: bad column list", name.text)
+			}
+		}
+		if tok, err = l.next(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if !strings.EqualFold(tok.text, "VALUES") && !strings.EqualFold(tok.text, "VALUE") {
+		return "", nil, fmt.Errorf("table %s: only INSERT ... VALUES is supported", name.text)
+	}
+	return name.text, columns, nil
+}
+
+// tuple reads one parenthesised row of values
+func (l *lexer) tuple() ([]string, error) {
+	tok, err := l.next()
+	if err != nil {
+		return nil, err
+	}
+	if tok.text != "(" {
+		return nil, fmt.Errorf("expected ( at the start of a row, got %q", tok.text)
+	}
+
+	var values []string
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case tok.kind == tokString:
+			values = append(values, tok.text)
+		case tok.kind == tokWord && strings.EqualFold(tok.text, "NULL"):
+			values = append(values, "")
+		case tok.kind == tokWord:
+			values = append(values, tok.text)
+		case tok.kind == tokPunct && tok.text == "-":
+			// negative numbers come through as a minus followed by the digits
+			num, err := l.next()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, "-"+num.text)
+		default:
+			return nil, fmt.Errorf("unexpected %q in a row", tok.text)
+		}
+
+		sep, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if sep.text == ")" {
+			return values, nil
+		}
+		if sep.text != "," {
+			return nil, fmt.Errorf("unexpected %q in a row", sep.text)
+		}
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,117 @@
+package forumimport
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadInserts(t *testing.T) {
+	// every table is read under its own name
+	all := func(name string) string { return name }
+
+	tests := []struct {
+		name string
+		dump string
+		want map[string][]row
+	}{
+		{
+			name: "several rows in one statement",
+			dump: "INSERT INTO `users` (`id`, `name`) VALUES (1,'alice'),(2,'bob');",
+			want: map[string][]row{"users": {{"id": "1", "name": "alice"}, {"id": "2", "name": "bob"}}},
+		},
+		{
+			name: "backslash escapes",
+			dump: `INSERT INTO t (v) VALUES ('it\'s'),('a\nb'),('tab\there'),('back\\slash'),('q\"q');`,
+			want: map[string][]row{"t": {{"v": "it's"}, {"v": "a\nb"}, {"v": "tab\there"}, {"v": `back\slash`}, {"v": `q"q`}}},
+		},
+		{
+			name: "doubled quotes",
+			dump: `INSERT INTO t (a, b) VALUES ('it''s', "say ""hi""");`,
+			want: map[string][]row{"t": {{"a": "it's", "b": `say "hi"`}}},
+		},
+		{
+			name: "negative numbers and NULL",
+			dump: "INSERT INTO t (a, b, c) VALUES (-5, NULL, -0.25);",
+			want: map[string][]row{"t": {{"a": "-5", "b": "", "c": "-0.25"}}},
+		},
+		{
+			name: "punctuation inside strings",
+			dump: "INSERT INTO t (v) VALUES ('a, b); INSERT INTO x -- not a comment');",
+			want: map[string][]row{"t": {{"v": "a, b); INSERT INTO x -- not a comment"}}},
+		},
+		{
+			name: "comments and other statements are skipped",
+			dump: "-- MySQL dump\n/*!40101 SET NAMES utf8 */;\n# note\nCREATE TABLE t (v int);\nLOCK TABLES t WRITE;\nINSERT IGNORE INTO t (v) VALUES (1);\nUNLOCK TABLES;",
+			want: map[string][]row{"t": {{"v": "1"}}},
+		},
+		{
+			name: "VALUE and a last statement without a semicolon",
+			dump: "INSERT INTO t (v) VALUE (1)",
+			want: map[string][]row{"t": {{"v": "1"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readInserts(tt.dump, all)
+			if err != nil {
+				t.Fatalf("readInserts: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readInserts\n got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadInsertsTables(t *testing.T) {
+	dump := "INSERT INTO phpbb_users (id) VALUES (1);\nINSERT INTO phpbb_sessions VALUES ('no column list');"
+
+	// tables that aren't wanted may leave out the column list
+	got, err := readInserts(dump, func(name string) string {
+		if name == "phpbb_users" {
+			return "users"
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatalf("readInserts: %v", err)
+	}
+	want := map[string][]row{"users": {{"id": "1"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readInserts\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestReadInsertsErrors(t *testing.T) {
+	all := func(name string) string { return name }
+
+	tests := []struct {
+		name string
+		dump string
+		want string // part of the error message
+		is   error
+	}{
+		{name: "no column list", dump: "INSERT INTO t VALUES (1);", is: ErrNoColumnList},
+		{name: "unterminated string", dump: "INSERT INTO t (v) VALUES ('open);", want: "unterminated string"},
+		{name: "unterminated comment", dump: "/* open", want: "unterminated comment"},
+		{name: "too few values", dump: "INSERT INTO t (a, b) VALUES (1);", want: "1 values for 2 columns"},
+		{name: "INSERT ... SELECT", dump: "INSERT INTO t (v) SELECT 1;", want: "only INSERT ... VALUES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readInserts(tt.dump, all)
+			switch {
+			case err == nil:
+				t.Fatal("readInserts succeeded")
+			case tt.is != nil && !errors.Is(err, tt.is):
+				t.Errorf("got %v, want %v", err, tt.is)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("got %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
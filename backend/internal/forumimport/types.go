@@ -0,0 +1,96 @@
+package forumimport
+
+import (
+	"errors"
+	"time"
+)
+
+// types of records in the intermediate format
+const (
+	TypeUser     = "user"
+	TypeCategory = "category"
+	TypeThread   = "thread"
+	TypeReply    = "reply"
+)
+
+// forums the import command has an adapter for, SourceForum is the intermediate format itself
+const (
+	SourceForum     = "forum"
+	SourceDiscourse = "discourse"
+	SourcePhpBB     = "phpbb"
+)
+
+var (
+	ErrUnknownSource = errors.New("source must be forum, discourse or phpbb")
+	ErrNoColumnList  = errors.New("INSERT statements need their column list, dump the database with mysqldump --complete-insert")
+)
+
+type User struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Category struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Thread struct {
+	ID         string    `json:"id"`
+	CategoryID string    `json:"category_id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name,omitempty"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	Pinned     bool      `json:"pinned"`
+	Locked     bool      `json:"locked"`
+	Tags       []string  `json:"tags,omitempty"`
+}
+
+type Reply struct {
+	ID         string    `json:"id"`
+	ThreadID   string    `json:"thread_id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name,omitempty"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Forum is everything read from a source, along with the records the adapter already had to leave out
+type Forum struct {
+	Users      []User
+	Categories []Category
+	Threads    []Thread
+	Replies    []Reply
+	Skipped    []Skipped
+}
+
+// Skipped is a record of the source that was not imported
+type Skipped struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// Renamed is a source user whose username was taken by a local account
+type Renamed struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Report is the outcome of converting a forum
+type Report struct {
+	// records written by type, placeholders included in the users
+	Converted    map[string]int64 `json:"converted"`
+	Placeholders []string         `json:"placeholders"`
+	Renamed      []Renamed        `json:"renamed"`
+	Skipped      []Skipped        `json:"skipped"`
+}
+
+func (f *Forum) skip(recordType string, id string, reason string) {
+	f.Skipped = append(f.Skipped, Skipped{Type: recordType, ID: id, Reason: reason})
+}
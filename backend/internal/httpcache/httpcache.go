@@ -0,0 +1,91 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultPolicy makes clients check back every time, which is cheap once they hold an ETag
+const DefaultPolicy = "private, no-cache"
+
+// Version identifies what a read would return without running it
+// It is worked out from the counts and latest timestamps of the rows behind the response
+type Version struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// NewVersion builds a weak ETag out of the latest change and the counts that go with it
+// Counts catch what timestamps cannot, such as a row being removed
+func NewVersion(lastModified time.Time, counts ...int64) Version {
+	h := sha256.New()
+	binary.Write(h, binary.BigEndian, lastModified.UnixNano())
+	for _, n := range counts {
+		binary.Write(h, binary.BigEndian, n)
+	}
+
+	return Version{
+		ETag:         `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`,
+		LastModified: lastModified.UTC(),
+	}
+}
+
+// NotModified sets the validators of v on the response and answers with a 304 when the client already has it
+// If-Modified-Since is only looked at when there is no If-None-Match, as RFC 9110 asks
+// It returns true when the 304 was sent and the handler has nothing left to do
+func NotModified(w http.ResponseWriter, r *http.Request, v Version) bool {
+	header := w.Header()
+	header.Set("ETag", v.ETag)
+	if !v.LastModified.IsZero() {
+		header.Set("Last-Modified", v.LastModified.Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	notModified := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		notModified = Matches(inm, v.ETag)
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !v.LastModified.IsZero() {
+		// Last-Modified only has whole seconds
+		if since, err := http.ParseTime(ims); err == nil {
+			notModified = !v.LastModified.Truncate(time.Second).After(since)
+		}
+	}
+
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// Matches reports whether an If-None-Match header lists etag, comparing weakly as RFC 9110 asks for
+func Matches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy
+// middleware that sets the Cache-Control header of a route, an empty policy falls back to DefaultPolicy
+func Policy(cacheControl string) func(http.Handler) http.Handler {
+	if cacheControl == "" {
+		cacheControl = DefaultPolicy
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", cacheControl)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
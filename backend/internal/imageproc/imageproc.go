@@ -0,0 +1,163 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder, WebP uploads are converted to JPEG or PNG
+)
+
+var (
+	ErrUnsupported   = errors.New("not an image that can be processed")
+	ErrTooManyPixels = errors.New("image dimensions are too large")
+)
+
+// Limits guard against decompression bombs, they are checked from the header before any pixels are decoded
+type Limits struct {
+	MaxPixels int64 // width times height
+	MaxSide   int   // longest edge
+}
+
+// Size is one of the scaled down copies made of every image
+type Size struct {
+	Name    string
+	MaxSide int // the image is scaled to fit in a square of this size
+}
+
+// Sizes are generated smallest first, a size is skipped when the original is already smaller
+var Sizes = []Size{
+	{Name: "thumb", MaxSide: 200},
+	{Name: "small", MaxSide: 480},
+	{Name: "medium", MaxSide: 960},
+	{Name: "large", MaxSide: 1600},
+}
+
+// Output is one encoded image
+type Output struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Process decodes an uploaded image and re-encodes it, which drops EXIF, GPS and any other metadata
+// It returns the full size image that replaces the upload along with the scaled down variants
+func Process(data []byte, limits Limits) (Output, []Output, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Output{}, nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 ||
+		cfg.Width > limits.MaxSide || cfg.Height > limits.MaxSide ||
+		int64(cfg.Width)*int64(cfg.Height) > limits.MaxPixels {
+		return Output{}, nil, ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Output{}, nil, ErrUnsupported
+	}
+
+	// phones store photos sideways and rely on the EXIF orientation, which is about to be dropped
+	if format == "jpeg" {
+		img = orient(img, orientation(data))
+	}
+
+	var full Output
+	if format == "gif" {
+		// GIFs stay GIFs so that animations keep every frame
+		full, err = encodeGIF(data)
+		if err != nil {
+			return Output{}, nil, err
+		}
+	} else {
+		// PNGs stay lossless, everything else becomes a JPEG unless it has transparency
+		full, err = encode("original", img, format == "png")
+		if err != nil {
+			return Output{}, nil, err
+		}
+	}
+
+	bounds := img.Bounds()
+	longest := max(bounds.Dx(), bounds.Dy())
+
+	var variants []Output
+	for _, size := range Sizes {
+		if size.MaxSide >= longest {
+			break
+		}
+		variant, err := encode(size.Name, scale(img, size.MaxSide), false)
+		if err != nil {
+			return Output{}, nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	return full, variants, nil
+}
+
+// encodeGIF writes the frames again, along with their timing and the loop count
+// Comment and application extension blocks, where XMP and other metadata live, are left behind
+func encodeGIF(data []byte) (Output, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return Output{}, ErrUnsupported
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		return Output{}, err
+	}
+	return Output{Name: "original", Data: buf.Bytes(), ContentType: "image/gif", Width: g.Config.Width, Height: g.Config.Height}, nil
+}
+
+// scale shrinks img so its longest edge is maxSide, keeping the aspect ratio
+func scale(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// encode writes photos as JPEG and anything with transparency, or when asked to, as PNG
+func encode(name string, img image.Image, lossless bool) (Output, error) {
+	var buf bytes.Buffer
+	contentType := "image/jpeg"
+
+	if !lossless && opaque(img) {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 82}); err != nil {
+			return Output{}, err
+		}
+	} else {
+		contentType = "image/png"
+		if err := png.Encode(&buf, img); err != nil {
+			return Output{}, err
+		}
+	}
+
+	b := img.Bounds()
+	return Output{Name: name, Data: buf.Bytes(), ContentType: contentType, Width: b.Dx(), Height: b.Dy()}, nil
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Archiver is implemented by the services whose rows go read-only after a period of inactivity
+type Archiver interface {
+	ArchiveInactive(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ArchiveInactive archives everything that has seen no activity since cutoff
+func ArchiveInactive(ctx context.Context, a Archiver, cutoff time.Time) error {
+	n, err := a.ArchiveInactive(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		slog.Info("archived inactive posts", "rows", n, "cutoff", cutoff)
+	}
+
+	return nil
+}
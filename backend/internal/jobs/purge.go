@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
+)
+
+// Purger is implemented by the services that soft delete rows
+type Purger interface {
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// Purge names the table a purger removes rows from, which is how its count shows in the audit log
+type Purge struct {
+	Table  string
+	Purger Purger
+}
+
+// PurgeDeleted hard deletes every row that was soft deleted before cutoff
+// Purges run in the order given, pass children before their parents so the counts reflect what each one removed
+// A run that removed anything leaves one audit entry with the count of every table, even when a later purge failed
+func PurgeDeleted(ctx context.Context, q *repo.Queries, cutoff time.Time, purges ...Purge) (int64, error) {
+	var total int64
+	counts := make(map[string]int64, len(purges))
+
+	var err error
+	for _, p := range purges {
+		var n int64
+		if n, err = p.Purger.PurgeDeleted(ctx, cutoff); err != nil {
+			break
+		}
+		counts[p.Table] = n
+		total += n
+	}
+
+	if total > 0 {
+		slog.Info("purged soft deleted rows", "rows", total, "cutoff", cutoff)
+
+		recordErr := audit.Record(ctx, q, audit.Entry{
+			Action:     "job.purge_deleted",
+			TargetType: "job",
+			Details: map[string]any{
+				"cutoff": cutoff,
+				"rows":   counts,
+				"total":  total,
+			},
+		})
+		if err == nil {
+			err = recordErr
+		}
+	}
+
+	return total, err
+}
@@ -1,19 +1,169 @@
 package json
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log"
+	"mime"
 	"net/http"
+	"strings"
 )
 
+// DefaultMaxBodyBytes is the body size cap used by Read when the route has not set its own limit
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1 MB
+
+// writeFailures counts responses that could not be encoded or written, exposed through /debug/vars
+var writeFailures = expvar.NewInt("json_write_failures")
+
+type contextKey string
+
+const maxBytesKey contextKey = "maxBodyBytes"
+
+// RequestError is returned by Read when the request body cannot be accepted,
+// carrying the HTTP status that should be sent back to the client
+type RequestError struct {
+	Status  int
+	Message string
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// StatusCode returns the HTTP status for an error returned by Read
+func StatusCode(err error) int {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.Status
+	}
+	return http.StatusBadRequest
+}
+
+// MaxBytes
+// middleware that caps the request body of a route at n bytes
+func MaxBytes(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			ctx := context.WithValue(r.Context(), maxBytesKey, n)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 // an agnostic function to send a JSON response
 func Write(w http.ResponseWriter, status int, data any) {
+	// encode first so that a failure can still be reported with a proper status code
+	body, err := json.Marshal(data)
+	if err != nil {
+		writeFailures.Add(1)
+		log.Println("failed to encode JSON response:", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		writeFailures.Add(1)
+		log.Println("failed to write JSON response:", err)
+	}
+}
+
+// WriteFailures returns the number of responses that failed to encode or write
+func WriteFailures() int64 {
+	return writeFailures.Value()
 }
 
 func Read(r *http.Request, data any) error {
+	if err := checkContentType(r); err != nil {
+		return err
+	}
+
+	// fall back to the default cap when the route did not set one with MaxBytes
+	limit, ok := r.Context().Value(maxBytesKey).(int64)
+	if !ok {
+		limit = DefaultMaxBodyBytes
+		r.Body = http.MaxBytesReader(nil, r.Body, limit)
+	}
+
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields() // to ensure there is no extra payload
-	return decoder.Decode(data)
+	if err := decoder.Decode(data); err != nil {
+		return decodeError(err, limit)
+	}
+
+	// reject anything after the first JSON value
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err, limit)
+		}
+		return &RequestError{Status: http.StatusBadRequest, Message: "request body must only contain a single JSON value"}
+	}
+
+	return nil
+}
+
+func checkContentType(r *http.Request) error {
+	// a GET carries no meaningful body type, clients such as browsers cannot even set one
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return &RequestError{Status: http.StatusUnsupportedMediaType, Message: "Content-Type header must be application/json"}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "application/json" {
+		return &RequestError{Status: http.StatusUnsupportedMediaType, Message: "Content-Type header must be application/json"}
+	}
+
+	return nil
+}
+
+// decodeError turns the errors from encoding/json into messages a client can act on
+func decodeError(err error, limit int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &RequestError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("request body must not be larger than %d bytes", limit),
+		}
+	case errors.As(err, &syntaxErr):
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("request body contains badly-formed JSON (at position %d)", syntaxErr.Offset),
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &RequestError{Status: http.StatusBadRequest, Message: "request body contains badly-formed JSON"}
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return &RequestError{
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("request body contains an invalid value for field %q (expected %s)", typeErr.Field, typeErr.Type),
+			}
+		}
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("request body contains an invalid value (at position %d)", typeErr.Offset),
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return &RequestError{Status: http.StatusBadRequest, Message: fmt.Sprintf("request body contains unknown field %s", field)}
+	case errors.Is(err, io.EOF):
+		return &RequestError{Status: http.StatusBadRequest, Message: "request body must not be empty"}
+	default:
+		return &RequestError{Status: http.StatusBadRequest, Message: err.Error()}
+	}
 }
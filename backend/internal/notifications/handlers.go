@@ -0,0 +1,83 @@
+package notifications
+
+import (
+	"log"
+	"net/http"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// Function that handles the ListNotifications API
+func (h *handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UnreadOnly bool  `json:"unread_only"`
+		Limit      int32 `json:"limit"`
+		Offset     int32 `json:"offset"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	inbox, err := h.service.ListNotifications(r.Context(), repo.ListNotificationsParams{
+		UserID:     userID,
+		UnreadOnly: data.UnreadOnly,
+		RowLimit:   data.Limit,
+		RowOffset:  data.Offset,
+	})
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.Write(w, http.StatusOK, inbox)
+}
+
+// Function that handles the MarkRead API
+func (h *handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		IDs []int64 `json:"ids"` // leave empty to mark everything as read
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	marked, err := h.service.MarkRead(r.Context(), userID, data.IDs)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.Write(w, http.StatusOK, map[string]int64{"marked": marked})
+}
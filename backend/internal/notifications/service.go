@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"context"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+// NotifyFollowersOfPost lets everyone following the author know about a newly published post
+// Pass the transaction's queries so nobody is notified about a post that never commits
+func NotifyFollowersOfPost(ctx context.Context, qtx *repo.Queries, post repo.Post) error {
+	_, err := qtx.NotifyFollowers(ctx, repo.NotifyFollowersParams{
+		Kind:       KindFollowedPost,
+		TargetType: "post",
+		TargetID:   post.ID,
+		ActorID:    post.UserID,
+	})
+	return err
+}
+
+func (s *svc) ListNotifications(ctx context.Context, params repo.ListNotificationsParams) (Inbox, error) {
+	if params.RowLimit <= 0 || params.RowLimit > 100 {
+		params.RowLimit = 25
+	}
+
+	notifications, err := s.repo.ListNotifications(ctx, params)
+	if err != nil {
+		return Inbox{}, err
+	}
+
+	unread, err := s.repo.CountUnreadNotifications(ctx, params.UserID)
+	if err != nil {
+		return Inbox{}, err
+	}
+
+	if notifications == nil {
+		notifications = []repo.Notification{}
+	}
+	return Inbox{Notifications: notifications, UnreadCount: unread}, nil
+}
+
+// MarkRead marks the given notifications as read, or all of them when ids is empty
+func (s *svc) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	if ids == nil {
+		ids = []int64{}
+	}
+
+	return s.repo.MarkNotificationsRead(ctx, repo.MarkNotificationsReadParams{
+		UserID: userID,
+		Ids:    ids,
+	})
+}
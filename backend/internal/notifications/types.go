@@ -0,0 +1,34 @@
+package notifications
+
+import (
+	"context"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+// values stored in notifications.kind
+const (
+	KindFollowedPost = "followed_post" // someone the user follows published a post
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+// Inbox is a page of notifications along with the total still unread
+type Inbox struct {
+	Notifications []repo.Notification `json:"notifications"`
+	UnreadCount   int64               `json:"unread_count"`
+}
+
+type Service interface {
+	ListNotifications(ctx context.Context, params repo.ListNotificationsParams) (Inbox, error)
+	MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error)
+}
@@ -0,0 +1,122 @@
+package optimistic
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+)
+
+var (
+	ErrVersionRequired = errors.New("send the version being edited, as version in the body or as an If-Match header")
+	ErrInvalidIfMatch  = errors.New("If-Match must hold an ETag returned by this API")
+)
+
+// Expected is the version a client based its edit on
+type Expected struct {
+	Version int32
+	// Any is set by If-Match: *, which accepts whatever the current version is
+	Any bool
+	// FromHeader is set when the version came from If-Match, a mismatch is then a 412 instead of a 409
+	FromHeader bool
+}
+
+// ConflictError is returned when the row was edited since the client read it
+// Current is the row as it is now, so the client can offer to merge
+type ConflictError struct {
+	Expected Expected
+	Version  int32
+	Current  any
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("edited by someone else since version %d, it is now at version %d", e.Expected.Version, e.Version)
+}
+
+// ETag is the entity tag of a version, `"v3"` for version 3
+func ETag(version int32) string {
+	return `"` + TagPrefix(version) + `"`
+}
+
+// TagPrefix starts the ETag of a response built around one row, so If-Match can still read the version from it
+func TagPrefix(version int32) string {
+	return "v" + strconv.FormatInt(int64(version), 10)
+}
+
+// Tagged puts the row version in front of a response's ETag, so the client can send it back in If-Match when editing
+// The tag is made strong since If-Match only takes strong tags, it is the version prefix that If-Match checks
+func Tagged(v httpcache.Version, version int32) httpcache.Version {
+	hash := strings.Trim(strings.TrimPrefix(v.ETag, "W/"), `"`)
+	v.ETag = `"` + TagPrefix(version) + "-" + hash + `"`
+	return v
+}
+
+// FromRequest works out the expected version from If-Match, falling back to the version sent in the body
+func FromRequest(r *http.Request, bodyVersion *int32) (Expected, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		if bodyVersion == nil {
+			return Expected{}, ErrVersionRequired
+		}
+		return Expected{Version: *bodyVersion}, nil
+	}
+
+	if ifMatch == "*" {
+		return Expected{Any: true, FromHeader: true}, nil
+	}
+
+	// If-Match compares strongly, so weak tags and lists are not accepted
+	tag, ok := strings.CutPrefix(ifMatch, `"v`)
+	if !ok || !strings.HasSuffix(tag, `"`) {
+		return Expected{}, ErrInvalidIfMatch
+	}
+	tag = strings.TrimSuffix(tag, `"`)
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		tag = tag[:i]
+	}
+
+	version, err := strconv.ParseInt(tag, 10, 32)
+	if err != nil {
+		return Expected{}, ErrInvalidIfMatch
+	}
+	return Expected{Version: int32(version), FromHeader: true}, nil
+}
+
+// Check compares the expected version with the current one, current is handed back to the client on a mismatch
+func Check(expected Expected, version int32, current any) error {
+	if expected.Any || expected.Version == version {
+		return nil
+	}
+	return &ConflictError{Expected: expected, Version: version, Current: current}
+}
+
+// WriteError answers with the status for err and reports whether err was one of this package's errors
+// A conflict is sent back along with the current copy and its ETag
+func WriteError(w http.ResponseWriter, err error) bool {
+	var conflict *ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		status := http.StatusConflict
+		if conflict.Expected.FromHeader {
+			status = http.StatusPreconditionFailed
+		}
+		w.Header().Set("ETag", ETag(conflict.Version))
+		json.Write(w, status, struct {
+			Error   string `json:"error"`
+			Version int32  `json:"version"`
+			Current any    `json:"current"`
+		}{conflict.Error(), conflict.Version, conflict.Current})
+		return true
+	case errors.Is(err, ErrVersionRequired):
+		http.Error(w, err.Error(), http.StatusPreconditionRequired)
+		return true
+	case errors.Is(err, ErrInvalidIfMatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package polls
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/poststate"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "poll not found", http.StatusNotFound)
+	case errors.Is(err, ErrNoChoice), errors.Is(err, ErrSingleChoice), errors.Is(err, ErrUnknownOption):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrPollClosed), errors.Is(err, poststate.ErrArchived):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrAlreadyVoted), errors.Is(err, ErrNotVoted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type ballotRequest struct {
+	PollID    int64   `json:"poll_id"`
+	OptionIDs []int64 `json:"option_ids"`
+}
+
+// Function that handles the Results API
+func (h *handler) Results(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		PostID int64 `json:"post_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	results, err := h.service.Results(r.Context(), data.PostID, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, results)
+}
+
+// Function that handles the Vote API
+func (h *handler) Vote(w http.ResponseWriter, r *http.Request) {
+	var data ballotRequest
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	results, err := h.service.Vote(r.Context(), data.PollID, userID, data.OptionIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, results)
+}
+
+// Function that handles the ChangeVote API
+func (h *handler) ChangeVote(w http.ResponseWriter, r *http.Request) {
+	var data ballotRequest
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	results, err := h.service.ChangeVote(r.Context(), data.PollID, userID, data.OptionIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, results)
+}
@@ -0,0 +1,88 @@
+package polls
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Validate trims the question and options and checks them against the limits
+func (p *NewPoll) Validate() error {
+	p.Question = strings.TrimSpace(p.Question)
+
+	if len(p.Options) < MinOptions || len(p.Options) > MaxOptions {
+		return ErrOptionCount
+	}
+
+	seen := make(map[string]bool, len(p.Options))
+	for i, label := range p.Options {
+		label = strings.TrimSpace(label)
+		key := strings.ToLower(label)
+		if label == "" || len(label) > MaxLabelLength || seen[key] {
+			return ErrInvalidOption
+		}
+		seen[key] = true
+		p.Options[i] = label
+	}
+
+	if p.ClosesAt != nil && !p.ClosesAt.After(time.Now()) {
+		return ErrClosesInPast
+	}
+
+	return nil
+}
+
+// Create attaches a poll to a freshly created post
+// Pass the transaction's queries so the post and its poll are stored together
+func Create(ctx context.Context, qtx *repo.Queries, post repo.Post, p NewPoll) (Results, error) {
+	if err := p.Validate(); err != nil {
+		return Results{}, err
+	}
+
+	params := repo.CreatePollParams{
+		PostID:         post.ID,
+		Question:       p.Question,
+		MultipleChoice: p.MultipleChoice,
+		HideResults:    p.HideResults,
+	}
+	if p.ClosesAt != nil {
+		params.ClosesAt = pgtype.Timestamp{Time: p.ClosesAt.UTC(), Valid: true}
+	}
+
+	poll, err := qtx.CreatePoll(ctx, params)
+	if err != nil {
+		return Results{}, err
+	}
+
+	results := Results{Poll: poll, Options: make([]OptionResult, 0, len(p.Options)), MyVotes: []int64{}}
+	for i, label := range p.Options {
+		option, err := qtx.CreatePollOption(ctx, repo.CreatePollOptionParams{
+			PollID:   poll.ID,
+			Position: int32(i),
+			Label:    label,
+		})
+		if err != nil {
+			return Results{}, err
+		}
+		results.Options = append(results.Options, OptionResult{ID: option.ID, Label: option.Label})
+	}
+
+	// a brand new poll has no votes, but only say so when the counts are visible
+	if !poll.HideResults {
+		var zero int64
+		results.Ballots = &zero
+		for i := range results.Options {
+			results.Options[i].Votes = new(int64)
+		}
+	}
+
+	return results, nil
+}
+
+// isClosed reports whether the poll stopped taking votes
+func isClosed(poll repo.Poll) bool {
+	return poll.ClosesAt.Valid && !poll.ClosesAt.Time.After(time.Now().UTC())
+}
@@ -0,0 +1,209 @@
+package polls
+
+import (
+	"context"
+	"errors"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/poststate"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+func (s *svc) Results(ctx context.Context, postID int64, userID int64) (Results, error) {
+	poll, err := s.repo.GetPollByPostID(ctx, postID)
+	if err != nil {
+		return Results{}, err
+	}
+
+	if _, err := livePost(ctx, s.repo, poll); err != nil {
+		return Results{}, err
+	}
+
+	return load(ctx, s.repo, poll, userID)
+}
+
+// Vote casts the user's ballot, a user votes once and changes it with ChangeVote afterwards
+func (s *svc) Vote(ctx context.Context, pollID int64, userID int64, optionIDs []int64) (Results, error) {
+	return s.castBallot(ctx, pollID, userID, optionIDs, false)
+}
+
+// ChangeVote replaces the choices on a ballot the user already cast
+func (s *svc) ChangeVote(ctx context.Context, pollID int64, userID int64, optionIDs []int64) (Results, error) {
+	return s.castBallot(ctx, pollID, userID, optionIDs, true)
+}
+
+func (s *svc) castBallot(ctx context.Context, pollID int64, userID int64, optionIDs []int64, change bool) (Results, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return Results{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	poll, err := qtx.GetPoll(ctx, pollID)
+	if err != nil {
+		return Results{}, err
+	}
+
+	// votes change the post, so an archived post takes no more of them
+	post, err := livePost(ctx, qtx, poll)
+	if err != nil {
+		return Results{}, err
+	}
+	if err := poststate.CanEdit(post); err != nil {
+		return Results{}, err
+	}
+	if isClosed(poll) {
+		return Results{}, ErrPollClosed
+	}
+
+	choices, err := checkChoices(ctx, qtx, poll, optionIDs)
+	if err != nil {
+		return Results{}, err
+	}
+
+	ballot := repo.CreatePollBallotParams{PollID: poll.ID, UserID: userID}
+	if change {
+		_, err = qtx.TouchPollBallot(ctx, repo.TouchPollBallotParams(ballot))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Results{}, ErrNotVoted
+		}
+		if err != nil {
+			return Results{}, err
+		}
+
+		err = qtx.DeleteBallotChoices(ctx, repo.DeleteBallotChoicesParams(ballot))
+		if err != nil {
+			return Results{}, err
+		}
+	} else {
+		_, err = qtx.CreatePollBallot(ctx, ballot)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return Results{}, ErrAlreadyVoted
+			}
+			return Results{}, err
+		}
+	}
+
+	for _, optionID := range choices {
+		err = qtx.CreatePollVote(ctx, repo.CreatePollVoteParams{
+			PollID:   poll.ID,
+			UserID:   userID,
+			OptionID: optionID,
+		})
+		if err != nil {
+			return Results{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Results{}, err
+	}
+
+	return load(ctx, s.repo, poll, userID)
+}
+
+// livePost returns the post a poll belongs to, polls on deleted or unpublished posts, or in deleted topics, are treated as missing
+func livePost(ctx context.Context, q *repo.Queries, poll repo.Poll) (repo.Post, error) {
+	post, err := q.GetPost(ctx, poll.PostID)
+	if err != nil {
+		return repo.Post{}, err
+	}
+	if post.DeletedAt.Valid || post.Status != contentfilter.StatusPublished {
+		return repo.Post{}, pgx.ErrNoRows
+	}
+
+	topic, err := q.GetTopic(ctx, post.TopicID)
+	if err != nil {
+		return repo.Post{}, err
+	}
+	if topic.DeletedAt.Valid {
+		return repo.Post{}, pgx.ErrNoRows
+	}
+	return post, nil
+}
+
+// checkChoices drops repeated options and makes sure the rest are valid for the poll
+func checkChoices(ctx context.Context, q *repo.Queries, poll repo.Poll, optionIDs []int64) ([]int64, error) {
+	options, err := q.ListPollResults(ctx, poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	valid := make(map[int64]bool, len(options))
+	for _, o := range options {
+		valid[o.ID] = true
+	}
+
+	choices := make([]int64, 0, len(optionIDs))
+	seen := make(map[int64]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, ErrUnknownOption
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		choices = append(choices, id)
+	}
+
+	if len(choices) == 0 {
+		return nil, ErrNoChoice
+	}
+	if len(choices) > 1 && !poll.MultipleChoice {
+		return nil, ErrSingleChoice
+	}
+	return choices, nil
+}
+
+// load builds the results of a poll for one user, leaving out the counts while they are hidden
+func load(ctx context.Context, q *repo.Queries, poll repo.Poll, userID int64) (Results, error) {
+	options, err := q.ListPollResults(ctx, poll.ID)
+	if err != nil {
+		return Results{}, err
+	}
+
+	mine, err := q.ListBallotChoices(ctx, repo.ListBallotChoicesParams{PollID: poll.ID, UserID: userID})
+	if err != nil {
+		return Results{}, err
+	}
+	if mine == nil {
+		mine = []int64{}
+	}
+
+	results := Results{
+		Poll:    poll,
+		Options: make([]OptionResult, 0, len(options)),
+		Closed:  isClosed(poll),
+		MyVotes: mine,
+	}
+	visible := !poll.HideResults || results.Closed
+
+	for _, o := range options {
+		option := OptionResult{ID: o.ID, Label: o.Label}
+		if visible {
+			option.Votes = &o.Votes
+		}
+		results.Options = append(results.Options, option)
+	}
+
+	if visible {
+		ballots, err := q.CountPollBallots(ctx, poll.ID)
+		if err != nil {
+			return Results{}, err
+		}
+		results.Ballots = &ballots
+	}
+
+	return results, nil
+}
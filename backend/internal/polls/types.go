@@ -0,0 +1,69 @@
+package polls
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+const (
+	MinOptions     = 2
+	MaxOptions     = 10
+	MaxLabelLength = 200
+)
+
+var (
+	ErrOptionCount   = errors.New("a poll needs between 2 and 10 options")
+	ErrInvalidOption = errors.New("poll options must be non-empty, unique and at most 200 characters long")
+	ErrClosesInPast  = errors.New("closes_at must be in the future")
+	ErrPollClosed    = errors.New("this poll is closed")
+	ErrNoChoice      = errors.New("pick at least one option")
+	ErrSingleChoice  = errors.New("this poll only allows one choice")
+	ErrUnknownOption = errors.New("option does not belong to this poll")
+	ErrAlreadyVoted  = errors.New("you have already voted in this poll, change your vote instead")
+	ErrNotVoted      = errors.New("you have not voted in this poll yet")
+)
+
+// NewPoll describes the poll to attach to a post when it is created
+type NewPoll struct {
+	Question       string     `json:"question"`
+	Options        []string   `json:"options"`
+	MultipleChoice bool       `json:"multiple_choice"`
+	HideResults    bool       `json:"hide_results"`
+	ClosesAt       *time.Time `json:"closes_at"`
+}
+
+// OptionResult is one option of a poll, Votes is nil while the results are hidden
+type OptionResult struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label"`
+	Votes *int64 `json:"votes"`
+}
+
+// Results is a poll as seen by one user
+type Results struct {
+	repo.Poll
+	Options []OptionResult `json:"options"`
+	Ballots *int64         `json:"ballots"` // nil while the results are hidden
+	Closed  bool           `json:"closed"`
+	MyVotes []int64        `json:"my_votes"` // the options the viewer picked, empty if they have not voted
+}
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+type Service interface {
+	Results(ctx context.Context, postID int64, userID int64) (Results, error)
+	Vote(ctx context.Context, pollID int64, userID int64, optionIDs []int64) (Results, error)
+	ChangeVote(ctx context.Context, pollID int64, userID int64, optionIDs []int64) (Results, error)
+}
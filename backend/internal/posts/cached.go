@@ -0,0 +1,192 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+)
+
+// cachedService serves post listings and post pages from the cache and invalidates them on every post write
+type cachedService struct {
+	Service
+	cache *cache.Cache
+
+	// loads the bookmarks laid over the shared listing and the topic and author of a post
+	repo *repo.Queries
+}
+
+// postRef is what a post page's scopes are picked from, neither changes once the post is written
+type postRef struct {
+	TopicID int64 `json:"topic_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+func NewCachedService(service Service, repo *repo.Queries, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c, repo: repo}
+}
+
+// ListPosts caches one listing of the topic for everyone and the posts the user saved apart from it
+func (s *cachedService) ListPosts(ctx context.Context, topicId int64, userID int64) ([]repo.ListPostsRow, error) {
+	if s.cache == nil {
+		return s.Service.ListPosts(ctx, topicId, userID)
+	}
+
+	posts, err := cache.Load(ctx, s.cache, fmt.Sprintf("posts:list:%d", topicId), []string{cache.TopicPosts(topicId)},
+		func(ctx context.Context) ([]repo.ListPostsRow, error) {
+			return s.Service.ListPosts(ctx, topicId, cache.Anyone)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := cache.Load(ctx, s.cache, fmt.Sprintf("posts:saved:%d:%d", topicId, userID), []string{cache.Bookmarks(userID)},
+		func(ctx context.Context) ([]int64, error) {
+			return s.repo.ListSavedPostIDs(ctx, repo.ListSavedPostIDsParams{UserID: userID, TopicID: topicId})
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	isSaved := make(map[int64]bool, len(saved))
+	for _, id := range saved {
+		isSaved[id] = true
+	}
+	for i := range posts {
+		posts[i].Saved = isSaved[posts[i].ID]
+	}
+	return posts, nil
+}
+
+func (s *cachedService) ListPostsVersion(ctx context.Context, topicId int64, userID int64) (httpcache.Version, error) {
+	return cache.Load(ctx, s.cache, fmt.Sprintf("posts:version:%d:%d", topicId, userID), []string{cache.TopicPosts(topicId), cache.Bookmarks(userID)},
+		func(ctx context.Context) (httpcache.Version, error) {
+			return s.Service.ListPostsVersion(ctx, topicId, userID)
+		})
+}
+
+// MarkTopicRead changes the unread counts in the user's topic listing
+func (s *cachedService) MarkTopicRead(ctx context.Context, topicId int64, userID int64) error {
+	if err := s.Service.MarkTopicRead(ctx, topicId, userID); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, cache.TopicState(userID))
+	return nil
+}
+
+func (s *cachedService) GetPost(ctx context.Context, id int64, userID int64) (PostDetail, error) {
+	scopes, err := s.postScopes(ctx, id, userID)
+	if err != nil {
+		return PostDetail{}, err
+	}
+	return cache.Load(ctx, s.cache, fmt.Sprintf("posts:get:%d:%d", id, userID), scopes,
+		func(ctx context.Context) (PostDetail, error) {
+			return s.Service.GetPost(ctx, id, userID)
+		})
+}
+
+func (s *cachedService) GetPostVersion(ctx context.Context, id int64, userID int64) (httpcache.Version, error) {
+	scopes, err := s.postScopes(ctx, id, userID)
+	if err != nil {
+		return httpcache.Version{}, err
+	}
+	return cache.Load(ctx, s.cache, fmt.Sprintf("posts:version:get:%d:%d", id, userID), scopes,
+		func(ctx context.Context) (httpcache.Version, error) {
+			return s.Service.GetPostVersion(ctx, id, userID)
+		})
+}
+
+// postScopes lists what a post page is built from, only its own topic and author rather than every topic and user
+func (s *cachedService) postScopes(ctx context.Context, id int64, userID int64) ([]string, error) {
+	if s.cache == nil {
+		return nil, nil
+	}
+
+	ref, err := cache.Load(ctx, s.cache, fmt.Sprintf("posts:ref:%d", id), nil,
+		func(ctx context.Context) (postRef, error) {
+			post, err := s.repo.GetPost(ctx, id)
+			return postRef{TopicID: post.TopicID, UserID: post.UserID}, err
+		})
+	if err != nil {
+		return nil, err
+	}
+	return []string{cache.Post(id), cache.Topic(ref.TopicID), cache.Author(ref.UserID), cache.Bookmarks(userID)}, nil
+}
+
+// CreatePost changes the counts of its topic and author, post pages elsewhere stay cached
+func (s *cachedService) CreatePost(ctx context.Context, req CreatePostRequest) (TaggedPost, error) {
+	post, err := s.Service.CreatePost(ctx, req)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Topics, cache.Topic(post.TopicID), cache.TopicPosts(post.TopicID), cache.Author(post.UserID))
+	}
+	return post, err
+}
+
+func (s *cachedService) UpdatePost(ctx context.Context, req UpdatePostRequest, editorID int64) (TaggedPost, error) {
+	post, err := s.Service.UpdatePost(ctx, req, editorID)
+	if err == nil && post.Status == contentfilter.StatusPending {
+		// a held edit hides the post, which changes the topic counts
+		s.invalidatePost(ctx, post.Post)
+	} else if err == nil {
+		s.cache.Invalidate(ctx, cache.TopicPosts(post.TopicID), cache.Post(post.ID))
+	}
+	return post, err
+}
+
+func (s *cachedService) DeletePost(ctx context.Context, id int64, userID int64) (repo.Post, error) {
+	post, err := s.Service.DeletePost(ctx, id, userID)
+	if err == nil {
+		s.invalidatePost(ctx, post)
+	}
+	return post, err
+}
+
+func (s *cachedService) RestorePost(ctx context.Context, id int64, userID int64, role string) (repo.Post, error) {
+	post, err := s.Service.RestorePost(ctx, id, userID, role)
+	if err == nil {
+		s.invalidatePost(ctx, post)
+	}
+	return post, err
+}
+
+func (s *cachedService) SetState(ctx context.Context, req SetStateRequest, userID int64, role string) (repo.Post, error) {
+	post, err := s.Service.SetState(ctx, req, userID, role)
+	if err == nil {
+		s.invalidatePost(ctx, post)
+	}
+	return post, err
+}
+
+// archiving, purging and repairing touch posts all over, so the whole cache starts over
+func (s *cachedService) ArchiveInactive(ctx context.Context, cutoff time.Time) (int64, error) {
+	n, err := s.Service.ArchiveInactive(ctx, cutoff)
+	if n > 0 {
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return n, err
+}
+
+func (s *cachedService) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	n, err := s.Service.PurgeDeleted(ctx, cutoff)
+	if n > 0 {
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return n, err
+}
+
+func (s *cachedService) RepairCounters(ctx context.Context) (int64, error) {
+	n, err := s.Service.RepairCounters(ctx)
+	if n > 0 {
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return n, err
+}
+
+// invalidatePost drops what shows the post, the topic and author counts included since it may have appeared or gone
+func (s *cachedService) invalidatePost(ctx context.Context, post repo.Post) {
+	s.cache.Invalidate(ctx, cache.Topics, cache.Topic(post.TopicID), cache.TopicPosts(post.TopicID), cache.Post(post.ID), cache.Author(post.UserID))
+}
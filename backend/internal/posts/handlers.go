@@ -1,12 +1,24 @@
 package posts
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
-	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/attachments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
 	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/polls"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/poststate"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/sanctions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/softdelete"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/tags"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 )
 
 // NewHandler
@@ -17,20 +29,43 @@ func NewHandler(service Service) *handler {
 	}
 }
 
+func isTagError(err error) bool {
+	return errors.Is(err, tags.ErrInvalidTag) || errors.Is(err, tags.ErrTooManyTags) || errors.Is(err, tags.ErrTagRestricted)
+}
+
+func isPollError(err error) bool {
+	return errors.Is(err, polls.ErrOptionCount) || errors.Is(err, polls.ErrInvalidOption) || errors.Is(err, polls.ErrClosesInPast)
+}
+
 // Function that handles the ListPosts API
 func (h *handler) ListPosts(w http.ResponseWriter, r *http.Request) {
-	var data struct {
-		TopicId int64 `json:"topic_id"`
+	topicID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid topic id", http.StatusBadRequest)
+		return
 	}
 
-	if err := json.Read(r, &data); err != nil {
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// a client that already has this version of the listing gets a 304 without it being built
+	version, err := h.service.ListPostsVersion(r.Context(), topicID, userID)
+	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if httpcache.NotModified(w, r, version) {
 		return
 	}
 
 	// Call this service -> ListPosts
-	topics, err := h.service.ListPosts(r.Context(), data.TopicId)
+	posts, err := h.service.ListPosts(r.Context(), topicID, userID)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -38,17 +73,91 @@ func (h *handler) ListPosts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return JSON in an HTTP response
-	json.Write(w, http.StatusOK, topics)
+	json.Write(w, http.StatusOK, posts)
+}
+
+// Function that handles the MarkTopicRead API
+// Kept apart from ListPosts so that reading the listing, or getting a 304 for it, never writes
+func (h *handler) MarkTopicRead(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TopicID int64 `json:"topic_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.MarkTopicRead(r.Context(), data.TopicID, userID); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Function that handles the GetPost API
+func (h *handler) GetPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// a client that already has this version of the post gets a 304 before the post and its comments are loaded
+	version, err := h.service.GetPostVersion(r.Context(), id, userID)
+	if err != nil {
+		log.Println(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if httpcache.NotModified(w, r, version) {
+		return
+	}
+
+	detail, err := h.service.GetPost(r.Context(), id, userID)
+	if err != nil {
+		log.Println(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.Write(w, http.StatusOK, detail)
 }
 
 // Function that handles the CreatePost API
 func (h *handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 
 	// get the topic params from the request body
-	var createPostParams repo.CreatePostParams
+	var createPostParams CreatePostRequest
 	if err := json.Read(r, &createPostParams); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
@@ -73,31 +182,88 @@ func (h *handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	createdPost, err := h.service.CreatePost(r.Context(), createPostParams)
 	if err != nil {
 		log.Println(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "topic not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, sanctions.ErrMutedInTopic) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		var blocked *contentfilter.BlockedError
+		if errors.As(err, &blocked) || isTagError(err) || isPollError(err) || errors.Is(err, attachments.ErrUnavailable) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.Write(w, http.StatusOK, createdPost)
+	// flagged content is stored but only published once a moderator approves it
+	status := http.StatusOK
+	if createdPost.Status == contentfilter.StatusPending {
+		status = http.StatusAccepted
+	}
+
+	json.Write(w, status, createdPost)
 }
 
 // Function that handles the UpdatePost API
 func (h *handler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	// get the post params from the request body
-	var updatePostParams repo.UpdatePostParams
+	var updatePostParams UpdatePostRequest
 	if err := json.Read(r, &updatePostParams); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
-	updatedPost, err := h.service.UpdatePost(r.Context(), updatePostParams)
+	expected, err := optimistic.FromRequest(r, updatePostParams.Version)
+	if err != nil {
+		optimistic.WriteError(w, err)
+		return
+	}
+	updatePostParams.Expected = expected
+
+	// Get user ID from context, recorded as the editor of the revision
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	updatedPost, err := h.service.UpdatePost(r.Context(), updatePostParams, userID)
 	if err != nil {
 		log.Println(err)
+		if optimistic.WriteError(w, err) {
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, poststate.ErrArchived) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		var blocked *contentfilter.BlockedError
+		if errors.As(err, &blocked) || isTagError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.Write(w, http.StatusOK, updatedPost)
+	w.Header().Set("ETag", optimistic.ETag(updatedPost.Version))
+	// a flagged edit is kept but hidden until a moderator approves it
+	status := http.StatusOK
+	if updatedPost.Status == contentfilter.StatusPending {
+		status = http.StatusAccepted
+	}
+
+	json.Write(w, status, updatedPost)
 }
 
 // Function that handles the DeletePost API
@@ -107,16 +273,106 @@ func (h *handler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.Read(r, &data); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
-	deletedPost, err := h.service.DeletePost(r.Context(), data.ID)
+	// Get user ID from context, recorded as the one who deleted it
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	deletedPost, err := h.service.DeletePost(r.Context(), data.ID, userID)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// also what deleting it a second time gets
+			http.Error(w, "post not found", http.StatusNotFound)
+		case errors.Is(err, poststate.ErrArchived):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
 	json.Write(w, http.StatusOK, deletedPost)
 }
+
+// Function that handles the RestorePost API
+func (h *handler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	restoredPost, err := h.service.RestorePost(r.Context(), data.ID, userID, role)
+	if err != nil {
+		log.Println(err)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			http.Error(w, "post not found", http.StatusNotFound)
+		case errors.Is(err, softdelete.ErrForbidden), errors.Is(err, softdelete.ErrWindowPassed):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, softdelete.ErrNotDeleted):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	json.Write(w, http.StatusOK, restoredPost)
+}
+
+// Function that handles the SetState API
+func (h *handler) SetState(w http.ResponseWriter, r *http.Request) {
+	var data SetStateRequest
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context, the topic owner and moderators may change the state
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	post, err := h.service.SetState(r.Context(), data, userID, role)
+	if err != nil {
+		log.Println(err)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			http.Error(w, "post not found", http.StatusNotFound)
+		case errors.Is(err, poststate.ErrForbidden):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	json.Write(w, http.StatusOK, post)
+}
@@ -3,66 +3,399 @@ package posts
 import (
 	"context"
 	"fmt"
+	"time"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/attachments"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/markdown"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/notifications"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/polls"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/poststate"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/revisions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/sanctions"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/softdelete"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/tags"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func NewService(repo *repo.Queries, pool db.Pool) Service {
-	return &svc{repo: repo, db: pool}
+func NewService(repo *repo.Queries, pool db.Pool, restoreWindow time.Duration, filter *contentfilter.Pipeline) Service {
+	return &svc{repo: repo, db: pool, restoreWindow: restoreWindow, filter: filter}
 }
 
-func (s *svc) ListPosts(ctx context.Context, topicId int64) ([]repo.Post, error) {
-	return s.repo.ListPosts(ctx, topicId)
+func (s *svc) ListPosts(ctx context.Context, topicId int64, userID int64) ([]repo.ListPostsRow, error) {
+	// saved tells the user which posts they bookmarked
+	posts, err := s.repo.ListPosts(ctx, repo.ListPostsParams{
+		UserID:  userID,
+		TopicID: topicId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// ListPostsVersion tells what ListPosts would return for the user without building the listing
+func (s *svc) ListPostsVersion(ctx context.Context, topicId int64, userID int64) (httpcache.Version, error) {
+	row, err := s.repo.GetTopicPostsVersion(ctx, repo.GetTopicPostsVersionParams{
+		UserID:  userID,
+		TopicID: topicId,
+	})
+	if err != nil {
+		return httpcache.Version{}, err
+	}
+	return httpcache.NewVersion(row.LastModified.Time,
+		row.PostCount, row.CommentCount, row.PinnedCount, row.LockedCount, row.ArchivedCount, row.SavedCount), nil
+}
+
+// MarkTopicRead records that the user has seen every post in the topic so far
+// Clients call it after showing the posts, the listing itself never writes
+func (s *svc) MarkTopicRead(ctx context.Context, topicId int64, userID int64) error {
+	return s.repo.RecordTopicVisit(ctx, repo.RecordTopicVisitParams{
+		UserID:  userID,
+		TopicID: topicId,
+	})
+}
+
+// GetPostVersion tells what GetPost would return for the user without loading the post or its comments
+// The tag starts with the post's version so it can also be sent back in If-Match when editing
+func (s *svc) GetPostVersion(ctx context.Context, id int64, userID int64) (httpcache.Version, error) {
+	row, err := s.repo.GetPostVersion(ctx, repo.GetPostVersionParams{UserID: userID, ID: id})
+	if err != nil {
+		return httpcache.Version{}, err
+	}
+	version := httpcache.NewVersion(row.LastModified.Time,
+		row.CommentCount, row.State, row.TopicPostCount, row.AuthorRole, row.AuthorPostCount,
+		row.AuthorFollowerCount, row.AuthorFollowedByViewer, row.SavedCount)
+	return optimistic.Tagged(version, row.Version), nil
 }
 
-func (s *svc) CreatePost(ctx context.Context, params repo.CreatePostParams) (repo.Post, error) {
+// GetPost loads a post with its topic, author and first page of comments
+// Both reads share a snapshot so the comments always match the counts next to them
+func (s *svc) GetPost(ctx context.Context, id int64, userID int64) (PostDetail, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return PostDetail{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	row, err := qtx.GetPostDetail(ctx, repo.GetPostDetailParams{UserID: userID, ID: id})
+	if err != nil {
+		return PostDetail{}, err
+	}
+
+	// one extra row tells whether there is a next page
+	comments, err := qtx.ListCommentsPage(ctx, repo.ListCommentsPageParams{
+		UserID:   userID,
+		PostID:   id,
+		RowLimit: commentPageSize + 1,
+	})
+	if err != nil {
+		return PostDetail{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PostDetail{}, err
+	}
+
+	detail := PostDetail{
+		Post: DetailedPost{
+			Post: repo.Post{
+				ID:             row.ID,
+				Title:          row.Title,
+				Content:        row.Content,
+				UserID:         row.UserID,
+				Username:       row.Username,
+				TopicID:        row.TopicID,
+				CreatedAt:      row.CreatedAt,
+				ContentHtml:    row.ContentHtml,
+				UpdatedAt:      row.UpdatedAt,
+				EditCount:      row.EditCount,
+				DeletedAt:      row.DeletedAt,
+				DeletedBy:      row.DeletedBy,
+				Status:         row.Status,
+				FlagReason:     row.FlagReason,
+				ContentHash:    row.ContentHash,
+				Pinned:         row.Pinned,
+				Locked:         row.Locked,
+				ArchivedAt:     row.ArchivedAt,
+				CommentCount:   row.CommentCount,
+				LastActivityAt: row.LastActivityAt,
+				Version:        row.Version,
+				PublishedAt:    row.PublishedAt,
+			},
+			Saved:   row.Saved,
+			Tags:    row.Tags,
+			HasPoll: row.HasPoll,
+		},
+		Topic: TopicSummary{
+			ID:          row.TopicID,
+			Name:        row.TopicName,
+			Description: row.TopicDescription,
+			PostCount:   row.TopicPostCount,
+		},
+		Author: AuthorSummary{
+			ID:               row.UserID,
+			Username:         row.Username,
+			Role:             row.AuthorRole,
+			CreatedAt:        row.AuthorCreatedAt,
+			PostCount:        row.AuthorPostCount,
+			FollowerCount:    row.AuthorFollowerCount,
+			FollowedByViewer: row.AuthorFollowedByViewer,
+		},
+		Comments: comments,
+	}
+	if len(comments) > commentPageSize {
+		detail.Comments = comments[:commentPageSize]
+		detail.HasMoreComments = true
+	}
+	if detail.Comments == nil {
+		detail.Comments = []repo.ListCommentsPageRow{}
+	}
+
+	return detail, nil
+}
+
+func (s *svc) CreatePost(ctx context.Context, req CreatePostRequest) (TaggedPost, error) {
+	params := req.CreatePostParams
+
 	// validate the params
 	if params.Title == "" {
-		return repo.Post{}, fmt.Errorf("title is required")
+		return TaggedPost{}, fmt.Errorf("title is required")
 	}
 
 	if params.Content == "" {
-		return repo.Post{}, fmt.Errorf("content is required")
+		return TaggedPost{}, fmt.Errorf("content is required")
+	}
+
+	if req.Poll != nil {
+		if err := req.Poll.Validate(); err != nil {
+			return TaggedPost{}, err
+		}
 	}
 
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return repo.Post{}, err
+		return TaggedPost{}, err
 	}
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
+	// posts cannot be added to a deleted topic, which is reported like a missing one
+	topic, err := qtx.GetTopic(ctx, params.TopicID)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+	if topic.DeletedAt.Valid {
+		return TaggedPost{}, pgx.ErrNoRows
+	}
+
+	muted, err := qtx.IsMutedInTopic(ctx, repo.IsMutedInTopicParams{
+		TopicID: params.TopicID,
+		UserID:  params.UserID,
+	})
+	if err != nil {
+		return TaggedPost{}, err
+	}
+	if muted {
+		return TaggedPost{}, sanctions.ErrMutedInTopic
+	}
+
+	// the filter may reject the post, mask words in it or hold it back for review
+	sub := contentfilter.Submission{AuthorID: params.UserID, Title: params.Title, Content: params.Content}
+	if err := s.filter.Run(ctx, qtx, &sub); err != nil {
+		return TaggedPost{}, err
+	}
+	params.Title, params.Content = sub.Title, sub.Content
+	params.Status = sub.Status()
+	params.FlagReason = sub.FlagReason()
+	params.ContentHash = sub.Hash
+
+	// render the markdown once on write so that reads can serve the cached HTML
+	contentHtml, err := markdown.Render(params.Content)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+	params.ContentHtml = contentHtml
+
 	post, err := qtx.CreatePost(ctx, params)
 	if err != nil {
-		return repo.Post{}, err
+		return TaggedPost{}, err
+	}
+
+	tagNames, err := tags.SetPostTags(ctx, qtx, post, req.Tags)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+
+	err = attachments.Attach(ctx, qtx, post.UserID, attachments.TargetPost, post.ID, req.AttachmentIDs)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+
+	created := TaggedPost{Post: post, Tags: tagNames}
+	if req.Poll != nil {
+		poll, err := polls.Create(ctx, qtx, post, *req.Poll)
+		if err != nil {
+			return TaggedPost{}, err
+		}
+		created.Poll = &poll
+	}
+
+	// posts held for review notify followers once they are approved instead
+	if post.Status == contentfilter.StatusPublished {
+		if err := notifications.NotifyFollowersOfPost(ctx, qtx, post); err != nil {
+			return TaggedPost{}, err
+		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return repo.Post{}, err
+		return TaggedPost{}, err
 	}
 
-	return post, nil
+	return created, nil
 }
 
-func (s *svc) UpdatePost(ctx context.Context, params repo.UpdatePostParams) (repo.Post, error) {
+func (s *svc) UpdatePost(ctx context.Context, req UpdatePostRequest, editorID int64) (TaggedPost, error) {
+	params := req.UpdatePostParams
+
 	// validate the params
 	if params.Title == "" {
-		return repo.Post{}, fmt.Errorf("title is required")
+		return TaggedPost{}, fmt.Errorf("title is required")
 	}
 
 	if params.Content == "" {
-		return repo.Post{}, fmt.Errorf("content is required")
+		return TaggedPost{}, fmt.Errorf("content is required")
 	}
 
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return repo.Post{}, err
+		return TaggedPost{}, err
 	}
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
+	// keep a copy of the version being replaced, the row lock stops concurrent edits from skipping a revision
+	current, err := qtx.GetPostForUpdate(ctx, params.ID)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+	if err := poststate.CanEdit(current); err != nil {
+		return TaggedPost{}, err
+	}
+
+	currentTags, err := qtx.ListPostTags(ctx, current.ID)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+
+	// someone else saved first, the editor gets their version back instead of overwriting it
+	if err := optimistic.Check(req.Expected, current.Version, TaggedPost{Post: current, Tags: currentTags}); err != nil {
+		return TaggedPost{}, err
+	}
+
+	// edits go through the same filter as new posts, a flagged edit takes the post out of view until it is approved
+	sub := contentfilter.Submission{AuthorID: current.UserID, Title: params.Title, Content: params.Content, Previous: current.ContentHash}
+	if err := s.filter.Run(ctx, qtx, &sub); err != nil {
+		return TaggedPost{}, err
+	}
+	params.Title, params.Content = sub.Title, sub.Content
+	params.Status, params.FlagReason = current.Status, current.FlagReason
+	if sub.Status() == contentfilter.StatusPending {
+		params.Status, params.FlagReason = contentfilter.StatusPending, sub.FlagReason()
+	}
+	params.ContentHash = sub.Hash
+
+	contentHtml, err := markdown.Render(params.Content)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+	params.ContentHtml = contentHtml
+
+	err = qtx.CreateRevision(ctx, repo.CreateRevisionParams{
+		TargetType: revisions.TargetPost,
+		TargetID:   current.ID,
+		Revision:   current.EditCount,
+		Title:      pgtype.Text{String: current.Title, Valid: true},
+		Content:    current.Content,
+		EditedBy:   pgtype.Int8{Int64: editorID, Valid: true},
+		Status:     current.Status,
+	})
+	if err != nil {
+		return TaggedPost{}, err
+	}
+
 	post, err := qtx.UpdatePost(ctx, params)
+	if err != nil {
+		return TaggedPost{}, err
+	}
+
+	// without a tags field the post keeps the ones it has
+	tagNames := currentTags
+	if req.Tags != nil {
+		tagNames, err = tags.SetPostTags(ctx, qtx, post, req.Tags)
+		if err != nil {
+			return TaggedPost{}, err
+		}
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "post.update",
+		TargetType: "post",
+		TargetID:   post.ID,
+		Before:     TaggedPost{Post: current, Tags: currentTags},
+		After:      TaggedPost{Post: post, Tags: tagNames},
+	})
+	if err != nil {
+		return TaggedPost{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return TaggedPost{}, err
+	}
+
+	return TaggedPost{Post: post, Tags: tagNames}, nil
+}
+
+func (s *svc) DeletePost(ctx context.Context, id int64, userID int64) (repo.Post, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Post{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	current, err := qtx.GetPost(ctx, id)
+	if err != nil {
+		return repo.Post{}, err
+	}
+	// archived posts are read-only, deleting them included
+	if err := poststate.CanEdit(current); err != nil {
+		return repo.Post{}, err
+	}
+
+	post, err := qtx.DeletePost(ctx, repo.DeletePostParams{
+		ID:        id,
+		DeletedBy: pgtype.Int8{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "post.delete",
+		TargetType: "post",
+		TargetID:   post.ID,
+		Before:     current,
+		After:      post,
+	})
 	if err != nil {
 		return repo.Post{}, err
 	}
@@ -74,7 +407,7 @@ func (s *svc) UpdatePost(ctx context.Context, params repo.UpdatePostParams) (rep
 	return post, nil
 }
 
-func (s *svc) DeletePost(ctx context.Context, id int64) (repo.Post, error) {
+func (s *svc) RestorePost(ctx context.Context, id int64, userID int64, role string) (repo.Post, error) {
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return repo.Post{}, err
@@ -82,7 +415,27 @@ func (s *svc) DeletePost(ctx context.Context, id int64) (repo.Post, error) {
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
-	post, err := qtx.DeletePost(ctx, id)
+	existing, err := qtx.GetPost(ctx, id)
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	if err := softdelete.CanRestore(existing.DeletedAt, existing.UserID, userID, role, s.restoreWindow); err != nil {
+		return repo.Post{}, err
+	}
+
+	post, err := qtx.RestorePost(ctx, id)
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "post.restore",
+		TargetType: "post",
+		TargetID:   post.ID,
+		Before:     existing,
+		After:      post,
+	})
 	if err != nil {
 		return repo.Post{}, err
 	}
@@ -93,3 +446,141 @@ func (s *svc) DeletePost(ctx context.Context, id int64) (repo.Post, error) {
 
 	return post, nil
 }
+
+// SetState pins, locks or archives a post, only the topic owner and moderators may do so
+func (s *svc) SetState(ctx context.Context, req SetStateRequest, userID int64, role string) (repo.Post, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Post{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	current, err := qtx.GetPostForUpdate(ctx, req.ID)
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	topic, err := qtx.GetTopic(ctx, current.TopicID)
+	if err != nil {
+		return repo.Post{}, err
+	}
+	if err := poststate.CanManage(topic.UserID, userID, role); err != nil {
+		return repo.Post{}, err
+	}
+
+	params := repo.SetPostStateParams{
+		ID:       current.ID,
+		Pinned:   current.Pinned,
+		Locked:   current.Locked,
+		Archived: current.ArchivedAt.Valid,
+	}
+	if req.Pinned != nil {
+		params.Pinned = *req.Pinned
+	}
+	if req.Locked != nil {
+		params.Locked = *req.Locked
+	}
+	if req.Archived != nil {
+		params.Archived = *req.Archived
+	}
+
+	post, err := qtx.SetPostState(ctx, params)
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "post.state",
+		TargetType: "post",
+		TargetID:   post.ID,
+		Before:     current,
+		After:      post,
+	})
+	if err != nil {
+		return repo.Post{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Post{}, err
+	}
+
+	return post, nil
+}
+
+// ArchiveInactive archives the posts with no activity since cutoff, recording the run in the audit log
+func (s *svc) ArchiveInactive(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	ids, err := qtx.ArchiveInactivePosts(ctx, pgtype.Timestamp{Time: cutoff, Valid: true})
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "job.archive_inactive",
+		TargetType: "job",
+		Details: map[string]any{
+			"cutoff":   cutoff,
+			"post_ids": ids,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), nil
+}
+
+func (s *svc) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return s.repo.PurgeDeletedPosts(ctx, pgtype.Timestamp{Time: cutoff, Valid: true})
+}
+
+// Reindex renders the markdown and hashes the text of every post again, in batches so it can run on a live database
+// The hash is what duplicate detection looks up, so this also brings it back in line after edits
+func (s *svc) Reindex(ctx context.Context) (int64, error) {
+	var afterID, total int64
+	for {
+		rows, err := s.repo.ListPostsForReindex(ctx, repo.ListPostsForReindexParams{AfterID: afterID, RowLimit: reindexBatchSize})
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		for _, row := range rows {
+			contentHtml, err := markdown.Render(row.Content)
+			if err != nil {
+				return total, err
+			}
+			err = s.repo.SetPostIndex(ctx, repo.SetPostIndexParams{
+				ID:          row.ID,
+				ContentHtml: contentHtml,
+				ContentHash: contentfilter.Hash(row.Content),
+			})
+			if err != nil {
+				return total, err
+			}
+			afterID = row.ID
+			total++
+		}
+	}
+}
+
+// RepairCounters recounts the comments and latest activity of every post, returning how many had drifted
+func (s *svc) RepairCounters(ctx context.Context) (int64, error) {
+	return s.repo.RepairPostCounters(ctx)
+}
@@ -2,24 +2,122 @@ package posts
 
 import (
 	"context"
+	"time"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/contentfilter"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/polls"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type handler struct {
 	service Service
 }
 
+const (
+	// rows rendered and hashed per query by Reindex
+	reindexBatchSize = 500
+	// comments GetPost embeds, the rest are fetched through GET /posts/{id}/comments
+	commentPageSize = 50
+)
+
 type svc struct {
 	// database
 	repo *repo.Queries
 	db   db.Pool
+
+	// how long the owner has to restore something they deleted
+	restoreWindow time.Duration
+
+	// checks new content before it is stored, nil skips them
+	filter *contentfilter.Pipeline
+}
+
+// CreatePostRequest is the body of the CreatePost API, the post itself plus its tags, uploads and an optional poll
+type CreatePostRequest struct {
+	repo.CreatePostParams
+	Tags          []string       `json:"tags"`
+	Poll          *polls.NewPoll `json:"poll"`
+	AttachmentIDs []int64        `json:"attachment_ids"`
+}
+
+// UpdatePostRequest is the body of the UpdatePost API, leaving tags out keeps the current ones
+// Version is the version being edited, an If-Match header can be sent instead
+type UpdatePostRequest struct {
+	repo.UpdatePostParams
+	Tags     []string            `json:"tags"`
+	Version  *int32              `json:"version"`
+	Expected optimistic.Expected `json:"-"`
+}
+
+// SetStateRequest is the body of the SetState API, flags left out keep their current value
+type SetStateRequest struct {
+	ID       int64 `json:"id"`
+	Pinned   *bool `json:"pinned"`
+	Locked   *bool `json:"locked"`
+	Archived *bool `json:"archived"`
+}
+
+// TaggedPost is a post along with the normalised names of its tags, and its poll when one was just created
+type TaggedPost struct {
+	repo.Post
+	Tags []string       `json:"tags"`
+	Poll *polls.Results `json:"poll,omitempty"`
+}
+
+// DetailedPost is a post along with the viewer's bookmark, its tags and whether it has a poll
+type DetailedPost struct {
+	repo.Post
+	Saved   bool     `json:"saved"`
+	Tags    []string `json:"tags"`
+	HasPoll bool     `json:"has_poll"`
+}
+
+// TopicSummary is the topic a post belongs to, as shown above it
+type TopicSummary struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	PostCount   int32  `json:"post_count"`
+}
+
+// AuthorSummary is the profile card shown next to a post
+type AuthorSummary struct {
+	ID               int64            `json:"id"`
+	Username         string           `json:"username"`
+	Role             string           `json:"role"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	PostCount        int64            `json:"post_count"`
+	FollowerCount    int64            `json:"follower_count"`
+	FollowedByViewer bool             `json:"followed_by_viewer"`
+}
+
+// PostDetail is the response of the GetPost API, everything a post page needs in one go
+// Comments holds the oldest comments, HasMoreComments says whether GET /posts/{id}/comments has more than that
+type PostDetail struct {
+	Post            DetailedPost               `json:"post"`
+	Topic           TopicSummary               `json:"topic"`
+	Author          AuthorSummary              `json:"author"`
+	Comments        []repo.ListCommentsPageRow `json:"comments"`
+	HasMoreComments bool                       `json:"has_more_comments"`
 }
 
 type Service interface {
-	ListPosts(ctx context.Context, topicId int64) ([]repo.Post, error)
-	CreatePost(ctx context.Context, params repo.CreatePostParams) (repo.Post, error)
-	UpdatePost(ctx context.Context, params repo.UpdatePostParams) (repo.Post, error)
-	DeletePost(ctx context.Context, id int64) (repo.Post, error)
+	ListPosts(ctx context.Context, topicId int64, userID int64) ([]repo.ListPostsRow, error)
+	ListPostsVersion(ctx context.Context, topicId int64, userID int64) (httpcache.Version, error)
+	MarkTopicRead(ctx context.Context, topicId int64, userID int64) error
+	GetPost(ctx context.Context, id int64, userID int64) (PostDetail, error)
+	GetPostVersion(ctx context.Context, id int64, userID int64) (httpcache.Version, error)
+	CreatePost(ctx context.Context, req CreatePostRequest) (TaggedPost, error)
+	UpdatePost(ctx context.Context, req UpdatePostRequest, editorID int64) (TaggedPost, error)
+	DeletePost(ctx context.Context, id int64, userID int64) (repo.Post, error)
+	RestorePost(ctx context.Context, id int64, userID int64, role string) (repo.Post, error)
+	SetState(ctx context.Context, req SetStateRequest, userID int64, role string) (repo.Post, error)
+	ArchiveInactive(ctx context.Context, cutoff time.Time) (int64, error)
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	Reindex(ctx context.Context) (int64, error)
+	RepairCounters(ctx context.Context) (int64, error)
 }
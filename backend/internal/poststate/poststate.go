@@ -0,0 +1,47 @@
+package poststate
+
+import (
+	"errors"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/users"
+)
+
+var (
+	ErrLocked    = errors.New("this post is locked and no longer accepts comments")
+	ErrArchived  = errors.New("this post is archived and can no longer be changed")
+	ErrForbidden = errors.New("only the topic owner or a moderator can change the state of this post")
+)
+
+// CanComment checks whether new comments may be added under post
+func CanComment(post repo.Post) error {
+	if post.ArchivedAt.Valid {
+		return ErrArchived
+	}
+	if post.Locked {
+		return ErrLocked
+	}
+	return nil
+}
+
+// CanEdit checks whether post, or a comment under it, may still be edited
+func CanEdit(post repo.Post) error {
+	if post.ArchivedAt.Valid {
+		return ErrArchived
+	}
+	return nil
+}
+
+// CanManage checks whether the viewer may pin, lock or archive posts in a topic owned by topicOwnerID
+func CanManage(topicOwnerID int64, viewerID int64, viewerRole string) error {
+	if users.IsModerator(viewerRole) || topicOwnerID == viewerID {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// Cutoff converts the inactivity period into the timestamp the auto-archive query compares activity against
+func Cutoff(inactivity time.Duration) time.Time {
+	return time.Now().UTC().Add(-inactivity)
+}
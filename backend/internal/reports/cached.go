@@ -0,0 +1,26 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+)
+
+// cachedService invalidates cached listings when a report ends with the content being removed
+type cachedService struct {
+	Service
+	cache *cache.Cache
+}
+
+func NewCachedService(service Service, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c}
+}
+
+func (s *cachedService) ResolveReports(ctx context.Context, targetType string, targetID int64, action string, note string, moderatorID int64) (Resolution, error) {
+	resolution, err := s.Service.ResolveReports(ctx, targetType, targetID, action, note, moderatorID)
+	if err == nil && action == ResolutionRemoveContent {
+		// removals are rare enough to start over rather than look up where the content was shown
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return resolution, err
+}
@@ -0,0 +1,136 @@
+package reports
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// writeError maps service errors onto the matching status code
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrInvalidTarget), errors.Is(err, ErrInvalidReason), errors.Is(err, ErrInvalidResolution):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrAlreadyReported), errors.Is(err, ErrNoOpenReports):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function that handles the FileReport API
+func (h *handler) FileReport(w http.ResponseWriter, r *http.Request) {
+	var createReportParams repo.CreateReportParams
+	if err := json.Read(r, &createReportParams); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	createReportParams.ReporterID = userID
+
+	report, err := h.service.FileReport(r.Context(), createReportParams)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, report)
+}
+
+// Function that handles the ListQueue API
+func (h *handler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Limit  int32 `json:"limit"`
+		Offset int32 `json:"offset"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	queue, err := h.service.ListQueue(r.Context(), data.Limit, data.Offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, queue)
+}
+
+// Function that handles the ListReports API
+func (h *handler) ListReports(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TargetType string `json:"target_type"`
+		TargetID   int64  `json:"target_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	reports, err := h.service.ListReports(r.Context(), data.TargetType, data.TargetID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, reports)
+}
+
+// Function that handles the ResolveReports API
+func (h *handler) ResolveReports(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TargetType string `json:"target_type"`
+		TargetID   int64  `json:"target_id"`
+		Action     string `json:"action"`
+		Note       string `json:"note"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	resolution, err := h.service.ResolveReports(r.Context(), data.TargetType, data.TargetID, data.Action, data.Note, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, resolution)
+}
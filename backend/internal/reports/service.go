@@ -0,0 +1,195 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+// authorOf returns the author of a post or comment that has not been deleted
+func authorOf(ctx context.Context, q *repo.Queries, targetType string, targetID int64) (int64, error) {
+	switch targetType {
+	case TargetPost:
+		post, err := q.GetPost(ctx, targetID)
+		if err != nil {
+			return 0, err
+		}
+		if post.DeletedAt.Valid {
+			return 0, pgx.ErrNoRows
+		}
+		return post.UserID, nil
+	case TargetComment:
+		comment, err := q.GetComment(ctx, targetID)
+		if err != nil {
+			return 0, err
+		}
+		if comment.DeletedAt.Valid {
+			return 0, pgx.ErrNoRows
+		}
+		return comment.UserID, nil
+	default:
+		return 0, ErrInvalidTarget
+	}
+}
+
+func (s *svc) FileReport(ctx context.Context, params repo.CreateReportParams) (repo.Report, error) {
+	// validate the params
+	if !reasons[params.Reason] {
+		return repo.Report{}, ErrInvalidReason
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Report{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	if _, err := authorOf(ctx, qtx, params.TargetType, params.TargetID); err != nil {
+		return repo.Report{}, err
+	}
+
+	report, err := qtx.CreateReport(ctx, params)
+	if err != nil {
+		// one open report per user and target
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return repo.Report{}, ErrAlreadyReported
+		}
+		return repo.Report{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Report{}, err
+	}
+
+	return report, nil
+}
+
+func (s *svc) ListQueue(ctx context.Context, limit int32, offset int32) ([]repo.ListReportQueueRow, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.ListReportQueue(ctx, repo.ListReportQueueParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (s *svc) ListReports(ctx context.Context, targetType string, targetID int64) ([]repo.Report, error) {
+	return s.repo.ListOpenReportsForTarget(ctx, repo.ListOpenReportsForTargetParams{
+		TargetType: targetType,
+		TargetID:   targetID,
+	})
+}
+
+func (s *svc) ResolveReports(ctx context.Context, targetType string, targetID int64, action string, note string, moderatorID int64) (Resolution, error) {
+	// validate the params
+	if action != ResolutionDismiss && action != ResolutionRemoveContent && action != ResolutionWarnUser {
+		return Resolution{}, ErrInvalidResolution
+	}
+	if targetType != TargetPost && targetType != TargetComment {
+		return Resolution{}, ErrInvalidTarget
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return Resolution{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	// every open report on the target is closed with the same outcome
+	resolved, err := qtx.ResolveReports(ctx, repo.ResolveReportsParams{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Resolution: pgtype.Text{String: action, Valid: true},
+		ResolvedBy: pgtype.Int8{Int64: moderatorID, Valid: true},
+	})
+	if err != nil {
+		return Resolution{}, err
+	}
+	if len(resolved) == 0 {
+		return Resolution{}, ErrNoOpenReports
+	}
+
+	// the content may have been deleted since it was reported, which only matters when warning its author
+	authorID, err := authorOf(ctx, qtx, targetType, targetID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return Resolution{}, err
+	}
+
+	switch action {
+	case ResolutionRemoveContent:
+		deletedBy := pgtype.Int8{Int64: moderatorID, Valid: true}
+		if targetType == TargetPost {
+			_, err = qtx.DeletePost(ctx, repo.DeletePostParams{ID: targetID, DeletedBy: deletedBy})
+		} else {
+			_, err = qtx.DeleteComment(ctx, repo.DeleteCommentParams{ID: targetID, DeletedBy: deletedBy})
+		}
+		// already gone is as good as removed
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return Resolution{}, err
+		}
+	case ResolutionWarnUser:
+		if authorID == 0 {
+			return Resolution{}, fmt.Errorf("cannot warn the author of a deleted %s", targetType)
+		}
+
+		reason := note
+		if reason == "" {
+			reason = "reported content"
+		}
+		_, err = qtx.CreateUserWarning(ctx, repo.CreateUserWarningParams{
+			UserID:     authorID,
+			IssuedBy:   pgtype.Int8{Int64: moderatorID, Valid: true},
+			Reason:     reason,
+			TargetType: targetType,
+			TargetID:   targetID,
+		})
+		if err != nil {
+			return Resolution{}, err
+		}
+	}
+
+	reportIDs := make([]int64, len(resolved))
+	for i, report := range resolved {
+		reportIDs[i] = report.ID
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "report." + action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details: map[string]any{
+			"report_ids": reportIDs,
+			"author_id":  authorID,
+			"note":       note,
+		},
+	})
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Resolution{}, err
+	}
+
+	return Resolution{Action: action, Reports: resolved}, nil
+}
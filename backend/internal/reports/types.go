@@ -0,0 +1,63 @@
+package reports
+
+import (
+	"context"
+	"errors"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+// values stored in reports.target_type
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+)
+
+// values stored in reports.resolution
+const (
+	ResolutionDismiss       = "dismiss"
+	ResolutionRemoveContent = "remove_content"
+	ResolutionWarnUser      = "warn_user"
+)
+
+// reasons accepted by the reports.reason check constraint
+var reasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate_speech":    true,
+	"misinformation": true,
+	"off_topic":      true,
+	"other":          true,
+}
+
+var (
+	ErrInvalidTarget     = errors.New("target_type must be either post or comment")
+	ErrInvalidReason     = errors.New("reason must be one of spam, harassment, hate_speech, misinformation, off_topic or other")
+	ErrInvalidResolution = errors.New("action must be one of dismiss, remove_content or warn_user")
+	ErrAlreadyReported   = errors.New("you have already reported this")
+	ErrNoOpenReports     = errors.New("there are no open reports for this target")
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+// Resolution is the outcome of resolving every open report on a target
+type Resolution struct {
+	Action  string        `json:"action"`
+	Reports []repo.Report `json:"reports"`
+}
+
+type Service interface {
+	FileReport(ctx context.Context, params repo.CreateReportParams) (repo.Report, error)
+	ListQueue(ctx context.Context, limit int32, offset int32) ([]repo.ListReportQueueRow, error)
+	ListReports(ctx context.Context, targetType string, targetID int64) ([]repo.Report, error)
+	ResolveReports(ctx context.Context, targetType string, targetID int64, action string, note string, moderatorID int64) (Resolution, error)
+}
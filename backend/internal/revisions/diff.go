@@ -0,0 +1,70 @@
+package revisions
+
+import "strings"
+
+// above this many lines multiplied together the diff falls back to replacing the whole text
+const maxDiffCells = 4_000_000
+
+// diffLines computes a line based diff from a to b using the longest common subsequence
+func diffLines(a, b string) []DiffLine {
+	x, y := splitLines(a), splitLines(b)
+	n, m := len(x), len(y)
+
+	if n*m > maxDiffCells {
+		out := make([]DiffLine, 0, n+m)
+		for _, line := range x {
+			out = append(out, DiffLine{Op: "delete", Text: line})
+		}
+		for _, line := range y {
+			out = append(out, DiffLine{Op: "insert", Text: line})
+		}
+		return out
+	}
+
+	// lcs[i][j] holds the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	out := make([]DiffLine, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, DiffLine{Op: "equal", Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{Op: "delete", Text: x[i]})
+			i++
+		default:
+			out = append(out, DiffLine{Op: "insert", Text: y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, DiffLine{Op: "delete", Text: x[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, DiffLine{Op: "insert", Text: y[j]})
+	}
+
+	return out
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
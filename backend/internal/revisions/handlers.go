@@ -0,0 +1,99 @@
+package revisions
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// writeError maps service errors onto the matching status code
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrRevisionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrInvalidTarget):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function that handles the ListRevisions API
+func (h *handler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TargetType string `json:"target_type"`
+		TargetID   int64  `json:"target_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	revisions, err := h.service.ListRevisions(r.Context(), data.TargetType, data.TargetID, userID, role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, revisions)
+}
+
+// Function that handles the DiffRevisions API
+func (h *handler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TargetType string `json:"target_type"`
+		TargetID   int64  `json:"target_id"`
+		From       int32  `json:"from"`
+		To         int32  `json:"to"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	diff, err := h.service.DiffRevisions(r.Context(), data.TargetType, data.TargetID, data.From, data.To, userID, role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, diff)
+}
@@ -0,0 +1,116 @@
+package revisions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/users"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+// snapshot is the text of a post or comment at a single revision
+type snapshot struct {
+	title   string
+	content string
+}
+
+// current loads the live version of the target along with its author and edit count
+func (s *svc) current(ctx context.Context, targetType string, targetID int64) (int64, int32, snapshot, error) {
+	switch targetType {
+	case TargetPost:
+		post, err := s.repo.GetPost(ctx, targetID)
+		if err != nil {
+			return 0, 0, snapshot{}, err
+		}
+		return post.UserID, post.EditCount, snapshot{title: post.Title, content: post.Content}, nil
+	case TargetComment:
+		comment, err := s.repo.GetComment(ctx, targetID)
+		if err != nil {
+			return 0, 0, snapshot{}, err
+		}
+		return comment.UserID, comment.EditCount, snapshot{content: comment.Content}, nil
+	default:
+		return 0, 0, snapshot{}, ErrInvalidTarget
+	}
+}
+
+func (s *svc) ListRevisions(ctx context.Context, targetType string, targetID int64, viewerID int64, viewerRole string) ([]repo.Revision, error) {
+	authorID, _, _, err := s.current(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorID != viewerID && !users.IsModerator(viewerRole) {
+		return nil, ErrForbidden
+	}
+
+	return s.repo.ListRevisions(ctx, repo.ListRevisionsParams{
+		TargetType: targetType,
+		TargetID:   targetID,
+	})
+}
+
+func (s *svc) DiffRevisions(ctx context.Context, targetType string, targetID int64, from int32, to int32, viewerID int64, viewerRole string) (RevisionDiff, error) {
+	authorID, editCount, live, err := s.current(ctx, targetType, targetID)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	if authorID != viewerID && !users.IsModerator(viewerRole) {
+		return RevisionDiff{}, ErrForbidden
+	}
+
+	// the newest revision number is the live version, which has no row in revisions
+	at := func(revision int32) (snapshot, error) {
+		if revision < 0 || revision > editCount {
+			return snapshot{}, fmt.Errorf("%w: asked for %d, latest is %d", ErrRevisionNotFound, revision, editCount)
+		}
+		if revision == editCount {
+			return live, nil
+		}
+
+		rev, err := s.repo.GetRevision(ctx, repo.GetRevisionParams{
+			TargetType: targetType,
+			TargetID:   targetID,
+			Revision:   revision,
+		})
+		if err != nil {
+			return snapshot{}, err
+		}
+		return snapshot{title: rev.Title.String, content: rev.Content}, nil
+	}
+
+	older, err := at(from)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	newer, err := at(to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	diff := RevisionDiff{
+		TargetType: targetType,
+		TargetID:   targetID,
+		From:       from,
+		To:         to,
+		Content:    diffLines(older.content, newer.content),
+	}
+	if targetType == TargetPost {
+		diff.Title = diffLines(older.title, newer.title)
+	}
+
+	return diff, nil
+}
+
+// PurgeDeleted removes the history of posts and comments that no longer exist
+// Revisions have no foreign key to cascade from, so this runs after the posts and comments are purged
+func (s *svc) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return s.repo.PurgeOrphanedRevisions(ctx)
+}
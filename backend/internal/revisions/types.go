@@ -0,0 +1,54 @@
+package revisions
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+// values stored in revisions.target_type
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+)
+
+var (
+	ErrInvalidTarget = errors.New("target_type must be either post or comment")
+	ErrForbidden     = errors.New("only the author and moderators can view the edit history")
+
+	ErrRevisionNotFound = errors.New("revision does not exist")
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+// DiffLine is a single line of a diff, Op is one of "equal", "insert" or "delete"
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	TargetType string     `json:"target_type"`
+	TargetID   int64      `json:"target_id"`
+	From       int32      `json:"from"`
+	To         int32      `json:"to"`
+	Title      []DiffLine `json:"title,omitempty"` // only for posts
+	Content    []DiffLine `json:"content"`
+}
+
+type Service interface {
+	ListRevisions(ctx context.Context, targetType string, targetID int64, viewerID int64, viewerRole string) ([]repo.Revision, error)
+	DiffRevisions(ctx context.Context, targetType string, targetID int64, from int32, to int32, viewerID int64, viewerRole string) (RevisionDiff, error)
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+}
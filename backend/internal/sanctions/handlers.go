@@ -0,0 +1,222 @@
+package sanctions
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// writeError maps service errors onto the matching status code
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrInvalidKind), errors.Is(err, ErrReasonRequired), errors.Is(err, ErrExpiryRequired),
+		errors.Is(err, ErrExpiryInPast), errors.Is(err, ErrCannotSelfTarget):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// expiry converts an optional expiry from the request body, nil means it never expires
+func expiry(t *time.Time) pgtype.Timestamp {
+	if t == nil {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}
+}
+
+// Function that handles the IssueSanction API
+func (h *handler) IssueSanction(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UserID    int64      `json:"user_id"`
+		Kind      string     `json:"kind"`
+		Reason    string     `json:"reason"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	sanction, err := h.service.IssueSanction(r.Context(), repo.CreateSanctionParams{
+		UserID:    data.UserID,
+		Kind:      data.Kind,
+		Reason:    data.Reason,
+		IssuedBy:  pgtype.Int8{Int64: userID, Valid: true},
+		ExpiresAt: expiry(data.ExpiresAt),
+	}, role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, sanction)
+}
+
+// Function that handles the RevokeSanction API
+func (h *handler) RevokeSanction(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	sanction, err := h.service.RevokeSanction(r.Context(), data.ID, userID, role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, sanction)
+}
+
+// Function that handles the ListSanctions API
+func (h *handler) ListSanctions(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UserID int64 `json:"user_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	sanctions, err := h.service.ListSanctions(r.Context(), data.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, sanctions)
+}
+
+// Function that handles the MuteUser API
+func (h *handler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TopicID   int64      `json:"topic_id"`
+		UserID    int64      `json:"user_id"`
+		Reason    string     `json:"reason"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	mute, err := h.service.MuteUser(r.Context(), repo.UpsertTopicMuteParams{
+		TopicID:   data.TopicID,
+		UserID:    data.UserID,
+		Reason:    data.Reason,
+		MutedBy:   pgtype.Int8{Int64: userID, Valid: true},
+		ExpiresAt: expiry(data.ExpiresAt),
+	}, role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, mute)
+}
+
+// Function that handles the UnmuteUser API
+func (h *handler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TopicID int64 `json:"topic_id"`
+		UserID  int64 `json:"user_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	mute, err := h.service.UnmuteUser(r.Context(), data.TopicID, data.UserID, userID, role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, mute)
+}
+
+// Function that handles the ListTopicMutes API
+func (h *handler) ListTopicMutes(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		TopicID int64 `json:"topic_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	mutes, err := h.service.ListTopicMutes(r.Context(), data.TopicID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, mutes)
+}
@@ -0,0 +1,231 @@
+package sanctions
+
+import (
+	"context"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+// checkTarget makes sure moderators only act on regular users, admins can act on anyone but themselves
+func checkTarget(ctx context.Context, q *repo.Queries, userID int64, moderatorID int64, moderatorRole string) error {
+	if userID == moderatorID {
+		return ErrCannotSelfTarget
+	}
+
+	target, err := q.FetchUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if users.IsModerator(target.Role) && moderatorRole != users.RoleAdmin {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+func checkExpiry(expiresAt pgtype.Timestamp) error {
+	if expiresAt.Valid && !expiresAt.Time.After(time.Now().UTC()) {
+		return ErrExpiryInPast
+	}
+	return nil
+}
+
+func (s *svc) IssueSanction(ctx context.Context, params repo.CreateSanctionParams, moderatorRole string) (repo.UserSanction, error) {
+	// validate the params
+	switch params.Kind {
+	case KindBan:
+		// bans lock the account out completely so only admins can hand them out
+		if moderatorRole != users.RoleAdmin {
+			return repo.UserSanction{}, ErrForbidden
+		}
+	case KindSuspension:
+		if !params.ExpiresAt.Valid {
+			return repo.UserSanction{}, ErrExpiryRequired
+		}
+	default:
+		return repo.UserSanction{}, ErrInvalidKind
+	}
+
+	if params.Reason == "" {
+		return repo.UserSanction{}, ErrReasonRequired
+	}
+
+	if err := checkExpiry(params.ExpiresAt); err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.UserSanction{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	if err := checkTarget(ctx, qtx, params.UserID, params.IssuedBy.Int64, moderatorRole); err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	sanction, err := qtx.CreateSanction(ctx, params)
+	if err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "user." + sanction.Kind,
+		TargetType: "user",
+		TargetID:   sanction.UserID,
+		After:      sanction,
+	})
+	if err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	return sanction, nil
+}
+
+// RevokeSanction lifts a sanction, following the same rules as issuing it
+func (s *svc) RevokeSanction(ctx context.Context, id int64, moderatorID int64, moderatorRole string) (repo.UserSanction, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.UserSanction{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	current, err := qtx.GetSanctionForUpdate(ctx, id)
+	if err != nil {
+		return repo.UserSanction{}, err
+	}
+	if current.Kind == KindBan && moderatorRole != users.RoleAdmin {
+		return repo.UserSanction{}, ErrForbidden
+	}
+	if err := checkTarget(ctx, qtx, current.UserID, moderatorID, moderatorRole); err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	sanction, err := qtx.RevokeSanction(ctx, repo.RevokeSanctionParams{
+		ID:        id,
+		RevokedBy: pgtype.Int8{Int64: moderatorID, Valid: true},
+	})
+	if err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "user.revoke_" + sanction.Kind,
+		TargetType: "user",
+		TargetID:   sanction.UserID,
+		After:      sanction,
+	})
+	if err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.UserSanction{}, err
+	}
+
+	return sanction, nil
+}
+
+func (s *svc) ListSanctions(ctx context.Context, userID int64) ([]repo.UserSanction, error) {
+	return s.repo.ListSanctionsForUser(ctx, userID)
+}
+
+func (s *svc) MuteUser(ctx context.Context, params repo.UpsertTopicMuteParams, moderatorRole string) (repo.TopicMute, error) {
+	// validate the params
+	if params.Reason == "" {
+		return repo.TopicMute{}, ErrReasonRequired
+	}
+
+	if err := checkExpiry(params.ExpiresAt); err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.TopicMute{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	if err := checkTarget(ctx, qtx, params.UserID, params.MutedBy.Int64, moderatorRole); err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	mute, err := qtx.UpsertTopicMute(ctx, params)
+	if err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "topic.mute",
+		TargetType: "user",
+		TargetID:   mute.UserID,
+		After:      mute,
+	})
+	if err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	return mute, nil
+}
+
+func (s *svc) UnmuteUser(ctx context.Context, topicID int64, userID int64, moderatorID int64, moderatorRole string) (repo.TopicMute, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.TopicMute{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	if err := checkTarget(ctx, qtx, userID, moderatorID, moderatorRole); err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	mute, err := qtx.DeleteTopicMute(ctx, repo.DeleteTopicMuteParams{
+		TopicID: topicID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "topic.unmute",
+		TargetType: "user",
+		TargetID:   mute.UserID,
+		Before:     mute,
+	})
+	if err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.TopicMute{}, err
+	}
+
+	return mute, nil
+}
+
+func (s *svc) ListTopicMutes(ctx context.Context, topicID int64) ([]repo.TopicMute, error) {
+	return s.repo.ListTopicMutes(ctx, topicID)
+}
@@ -0,0 +1,59 @@
+package sanctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+// values stored in user_sanctions.kind
+const (
+	KindBan        = "ban"
+	KindSuspension = "suspension"
+)
+
+var (
+	ErrInvalidKind      = errors.New("kind must be either ban or suspension")
+	ErrReasonRequired   = errors.New("reason is required")
+	ErrExpiryRequired   = errors.New("suspensions need an expiry")
+	ErrExpiryInPast     = errors.New("expires_at must be in the future")
+	ErrForbidden        = errors.New("you are not allowed to sanction this user")
+	ErrMutedInTopic     = errors.New("you have been muted in this topic")
+	ErrCannotSelfTarget = errors.New("you cannot sanction yourself")
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+type Service interface {
+	IssueSanction(ctx context.Context, params repo.CreateSanctionParams, moderatorRole string) (repo.UserSanction, error)
+	RevokeSanction(ctx context.Context, id int64, moderatorID int64, moderatorRole string) (repo.UserSanction, error)
+	ListSanctions(ctx context.Context, userID int64) ([]repo.UserSanction, error)
+	MuteUser(ctx context.Context, params repo.UpsertTopicMuteParams, moderatorRole string) (repo.TopicMute, error)
+	UnmuteUser(ctx context.Context, topicID int64, userID int64, moderatorID int64, moderatorRole string) (repo.TopicMute, error)
+	ListTopicMutes(ctx context.Context, topicID int64) ([]repo.TopicMute, error)
+}
+
+// Message explains an active sanction to the user it applies to
+func Message(s repo.UserSanction) string {
+	state := "banned"
+	if s.Kind == KindSuspension {
+		state = "suspended"
+	}
+
+	if s.ExpiresAt.Valid {
+		return fmt.Sprintf("your account is %s until %s: %s", state, s.ExpiresAt.Time.Format(time.RFC3339), s.Reason)
+	}
+	return fmt.Sprintf("your account is %s: %s", state, s.Reason)
+}
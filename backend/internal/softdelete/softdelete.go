@@ -0,0 +1,42 @@
+package softdelete
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrNotDeleted   = errors.New("nothing to restore, it has not been deleted")
+	ErrForbidden    = errors.New("only the owner or an admin can restore this")
+	ErrWindowPassed = errors.New("the restore window has passed, ask an admin to restore it")
+)
+
+// CanRestore checks whether the viewer may restore a row owned by ownerID
+// Owners can restore within the grace window, admins can restore any time before the row is purged
+func CanRestore(deletedAt pgtype.Timestamp, ownerID int64, viewerID int64, viewerRole string, window time.Duration) error {
+	if !deletedAt.Valid {
+		return ErrNotDeleted
+	}
+
+	if viewerRole == users.RoleAdmin {
+		return nil
+	}
+
+	if ownerID != viewerID {
+		return ErrForbidden
+	}
+
+	if time.Now().UTC().Sub(deletedAt.Time) > window {
+		return ErrWindowPassed
+	}
+
+	return nil
+}
+
+// Cutoff converts a retention period into the timestamp that the purge queries compare deleted_at against
+func Cutoff(retention time.Duration) time.Time {
+	return time.Now().UTC().Add(-retention)
+}
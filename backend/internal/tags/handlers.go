@@ -0,0 +1,126 @@
+package tags
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// NewHandler
+// function to create a handler instance with the service layer as dependency
+func NewHandler(service Service) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, ErrInvalidTag), errors.Is(err, ErrTooManyTags), errors.Is(err, ErrTagRestricted):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// queryInt32 reads an optional integer query parameter, zero when it is missing
+func queryInt32(r *http.Request, key string) (int32, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil {
+		return 0, errors.New(key + " must be a number")
+	}
+	return int32(n), nil
+}
+
+// Function that handles the Autocomplete API
+func (h *handler) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Prefix  string `json:"prefix"`
+		TopicID *int64 `json:"topic_id"` // include tags restricted to this topic
+		Limit   int32  `json:"limit"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	found, err := h.service.Autocomplete(r.Context(), data.Prefix, data.TopicID, data.Limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, found)
+}
+
+// Function that handles the ListPosts API
+func (h *handler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt32(r, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := queryInt32(r, "offset")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	posts, err := h.service.ListPosts(r.Context(), repo.ListPostsByTagParams{
+		UserID:    userID,
+		Tag:       chi.URLParam(r, "tag"),
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, posts)
+}
+
+// Function that handles the RestrictTag API
+func (h *handler) RestrictTag(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Name    string `json:"name"`
+		TopicID *int64 `json:"topic_id"` // null makes the tag free to use anywhere
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	tag, err := h.service.RestrictTag(r.Context(), data.Name, data.TopicID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, tag)
+}
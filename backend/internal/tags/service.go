@@ -0,0 +1,107 @@
+package tags
+
+import (
+	"context"
+	"strings"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func NewService(repo *repo.Queries, pool db.Pool) Service {
+	return &svc{repo: repo, db: pool}
+}
+
+// Autocomplete suggests existing tags starting with prefix, the most used first
+// Tags restricted to a topic are only suggested when that topic is given
+func (s *svc) Autocomplete(ctx context.Context, prefix string, topicID *int64, limit int32) ([]repo.SearchTagsRow, error) {
+	if limit <= 0 || limit > 25 {
+		limit = 10
+	}
+
+	// normalise the prefix the same way as a full name, but allow a trailing separator while typing
+	prefix = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(prefix), "#"))
+	prefix = separators.ReplaceAllString(prefix, "-")
+	if prefix != "" && !validName.MatchString(prefix) {
+		return []repo.SearchTagsRow{}, nil
+	}
+
+	params := repo.SearchTagsParams{Prefix: prefix, RowLimit: limit}
+	if topicID != nil {
+		params.TopicID = pgtype.Int8{Int64: *topicID, Valid: true}
+	}
+
+	found, err := s.repo.SearchTags(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		found = []repo.SearchTagsRow{}
+	}
+	return found, nil
+}
+
+func (s *svc) ListPosts(ctx context.Context, params repo.ListPostsByTagParams) ([]repo.ListPostsByTagRow, error) {
+	tag, err := Normalize(params.Tag)
+	if err != nil {
+		return nil, err
+	}
+	params.Tag = tag
+
+	if params.RowLimit <= 0 || params.RowLimit > 100 {
+		params.RowLimit = 25
+	}
+	return s.repo.ListPostsByTag(ctx, params)
+}
+
+// RestrictTag limits a tag to one topic, or frees it again when topicID is nil
+// Posts that already carry the tag keep it
+func (s *svc) RestrictTag(ctx context.Context, name string, topicID *int64) (repo.Tag, error) {
+	name, err := Normalize(name)
+	if err != nil {
+		return repo.Tag{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Tag{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	params := repo.RestrictTagParams{Name: name}
+	if topicID != nil {
+		topic, err := qtx.GetTopic(ctx, *topicID)
+		if err != nil {
+			return repo.Tag{}, err
+		}
+		if topic.DeletedAt.Valid {
+			return repo.Tag{}, pgx.ErrNoRows
+		}
+		params.TopicID = pgtype.Int8{Int64: topic.ID, Valid: true}
+	}
+
+	tag, err := qtx.RestrictTag(ctx, params)
+	if err != nil {
+		return repo.Tag{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "tag.restrict",
+		TargetType: "tag",
+		TargetID:   tag.ID,
+		After:      tag,
+	})
+	if err != nil {
+		return repo.Tag{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Tag{}, err
+	}
+
+	return tag, nil
+}
@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+)
+
+var (
+	validName  = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	separators = regexp.MustCompile(`[\s_-]+`)
+)
+
+// Normalize turns user input like "#Go Lang" into the stored form "go-lang"
+func Normalize(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "#")
+	name = separators.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-")
+
+	if name == "" || len(name) > MaxLength || !validName.MatchString(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTag, name)
+	}
+	return name, nil
+}
+
+// NormalizeAll normalises every name and drops duplicates, keeping the order they were given in
+func NormalizeAll(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		tag, err := Normalize(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+
+	if len(out) > MaxPerPost {
+		return nil, ErrTooManyTags
+	}
+	return out, nil
+}
+
+// SetPostTags replaces the tags on a post, creating any free tags that don't exist yet
+// Pass the transaction's queries so the tags are saved together with the post
+func SetPostTags(ctx context.Context, qtx *repo.Queries, post repo.Post, names []string) ([]string, error) {
+	names, err := NormalizeAll(names)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := qtx.DeletePostTags(ctx, post.ID); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		tag, err := qtx.UpsertTag(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if tag.TopicID.Valid && tag.TopicID.Int64 != post.TopicID {
+			return nil, fmt.Errorf("%w: %q", ErrTagRestricted, name)
+		}
+
+		err = qtx.AddPostTag(ctx, repo.AddPostTagParams{PostID: post.ID, TagID: tag.ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
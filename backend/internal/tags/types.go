@@ -0,0 +1,36 @@
+package tags
+
+import (
+	"context"
+	"errors"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+)
+
+const (
+	MaxLength  = 32 // longest tag name, matches the CHECK on tags.name
+	MaxPerPost = 5
+)
+
+var (
+	ErrInvalidTag    = errors.New("tags may only contain letters, digits and dashes and be at most 32 characters long")
+	ErrTooManyTags   = errors.New("a post can have at most 5 tags")
+	ErrTagRestricted = errors.New("tag can only be used in its own topic")
+)
+
+type handler struct {
+	service Service
+}
+
+type svc struct {
+	// database
+	repo *repo.Queries
+	db   db.Pool
+}
+
+type Service interface {
+	Autocomplete(ctx context.Context, prefix string, topicID *int64, limit int32) ([]repo.SearchTagsRow, error)
+	ListPosts(ctx context.Context, params repo.ListPostsByTagParams) ([]repo.ListPostsByTagRow, error)
+	RestrictTag(ctx context.Context, name string, topicID *int64) (repo.Tag, error)
+}
@@ -0,0 +1,114 @@
+package topics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+)
+
+// cachedService serves the topic listing from the cache and invalidates it on every topic write
+type cachedService struct {
+	Service
+	cache *cache.Cache
+
+	// loads the subscriptions and unread counts laid over the shared listing
+	repo *repo.Queries
+}
+
+func NewCachedService(service Service, repo *repo.Queries, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c, repo: repo}
+}
+
+// ListTopics caches one listing for everyone and the user's subscriptions and unread counts apart from it
+func (s *cachedService) ListTopics(ctx context.Context, userID int64) ([]repo.ListTopicsForUserRow, error) {
+	if s.cache == nil {
+		return s.Service.ListTopics(ctx, userID)
+	}
+
+	topics, err := cache.Load(ctx, s.cache, "topics:list", []string{cache.Topics},
+		func(ctx context.Context) ([]repo.ListTopicsForUserRow, error) {
+			return s.Service.ListTopics(ctx, cache.Anyone)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// unread counts go up with every new post, so they follow the listing's scope too
+	states, err := cache.Load(ctx, s.cache, fmt.Sprintf("topics:state:%d", userID), []string{cache.Topics, cache.TopicState(userID)},
+		func(ctx context.Context) ([]repo.ListTopicStatesRow, error) {
+			return s.repo.ListTopicStates(ctx, userID)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	byTopic := make(map[int64]repo.ListTopicStatesRow, len(states))
+	for _, state := range states {
+		byTopic[state.TopicID] = state
+	}
+	for i := range topics {
+		state := byTopic[topics[i].ID]
+		topics[i].Subscribed, topics[i].UnreadCount = state.Subscribed, state.UnreadCount
+	}
+	return topics, nil
+}
+
+func (s *cachedService) ListTopicsVersion(ctx context.Context, userID int64) (httpcache.Version, error) {
+	return cache.Load(ctx, s.cache, fmt.Sprintf("topics:version:%d", userID), []string{cache.Topics, cache.TopicState(userID)},
+		func(ctx context.Context) (httpcache.Version, error) {
+			return s.Service.ListTopicsVersion(ctx, userID)
+		})
+}
+
+func (s *cachedService) CreateTopic(ctx context.Context, params repo.CreateTopicParams) (repo.Topic, error) {
+	topic, err := s.Service.CreateTopic(ctx, params)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Topics)
+	}
+	return topic, err
+}
+
+func (s *cachedService) UpdateTopic(ctx context.Context, req UpdateTopicRequest) (repo.Topic, error) {
+	topic, err := s.Service.UpdateTopic(ctx, req)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Topics, cache.Topic(topic.ID))
+	}
+	return topic, err
+}
+
+func (s *cachedService) DeleteTopic(ctx context.Context, id int64, userID int64) (repo.Topic, error) {
+	topic, err := s.Service.DeleteTopic(ctx, id, userID)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Topics, cache.Topic(id), cache.TopicPosts(id))
+	}
+	return topic, err
+}
+
+func (s *cachedService) RestoreTopic(ctx context.Context, id int64, userID int64, role string) (repo.Topic, error) {
+	topic, err := s.Service.RestoreTopic(ctx, id, userID, role)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Topics, cache.Topic(id), cache.TopicPosts(id))
+	}
+	return topic, err
+}
+
+// purging and repairing touch topics all over, so the whole cache starts over
+func (s *cachedService) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	n, err := s.Service.PurgeDeleted(ctx, cutoff)
+	if n > 0 {
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return n, err
+}
+
+func (s *cachedService) RepairCounters(ctx context.Context) (int64, error) {
+	n, err := s.Service.RepairCounters(ctx)
+	if n > 0 {
+		s.cache.Invalidate(ctx, cache.All)
+	}
+	return n, err
+}
@@ -1,12 +1,17 @@
 package topics
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
 	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/softdelete"
+	"github.com/jackc/pgx/v5"
 )
 
 // NewHandler
@@ -19,8 +24,27 @@ func NewHandler(service Service) *handler {
 
 // Function that handles the ListTopics API
 func (h *handler) ListTopics(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// a client that already has this version of the listing gets a 304 without it being built
+	version, err := h.service.ListTopicsVersion(r.Context(), userID)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if httpcache.NotModified(w, r, version) {
+		return
+	}
+
 	// Call this service -> ListTopics
-	topics, err := h.service.ListTopics(r.Context())
+	topics, err := h.service.ListTopics(r.Context(), userID)
 	if err != nil {
 		log.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -38,7 +62,7 @@ func (h *handler) CreateTopic(w http.ResponseWriter, r *http.Request) {
 	var createTopicsParams repo.CreateTopicParams
 	if err := json.Read(r, &createTopicsParams); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
@@ -73,20 +97,35 @@ func (h *handler) CreateTopic(w http.ResponseWriter, r *http.Request) {
 // Function that handles the UpdateTopic API
 func (h *handler) UpdateTopic(w http.ResponseWriter, r *http.Request) {
 	// get the topic params from the request body
-	var updateTopicParams repo.UpdateTopicParams
+	var updateTopicParams UpdateTopicRequest
 	if err := json.Read(r, &updateTopicParams); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	expected, err := optimistic.FromRequest(r, updateTopicParams.Version)
+	if err != nil {
+		optimistic.WriteError(w, err)
 		return
 	}
+	updateTopicParams.Expected = expected
 
 	updatedTopic, err := h.service.UpdateTopic(r.Context(), updateTopicParams)
 	if err != nil {
 		log.Println(err)
+		if optimistic.WriteError(w, err) {
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "topic not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("ETag", optimistic.ETag(updatedTopic.Version))
 	json.Write(w, http.StatusOK, updatedTopic)
 }
 
@@ -97,16 +136,68 @@ func (h *handler) DeleteTopic(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.Read(r, &data); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
 		return
 	}
 
-	deletedTopic, err := h.service.DeleteTopic(r.Context(), data.ID)
+	// Get user ID from context, recorded as the one who deleted it
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	deletedTopic, err := h.service.DeleteTopic(r.Context(), data.ID, userID)
 	if err != nil {
 		log.Println(err)
+		// also what deleting it a second time gets
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "topic not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	json.Write(w, http.StatusOK, deletedTopic)
 }
+
+// Function that handles the RestoreTopic API
+func (h *handler) RestoreTopic(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID and role from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	role, _ := r.Context().Value(appctx.RoleKey).(string)
+
+	restoredTopic, err := h.service.RestoreTopic(r.Context(), data.ID, userID, role)
+	if err != nil {
+		log.Println(err)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			http.Error(w, "topic not found", http.StatusNotFound)
+		case errors.Is(err, softdelete.ErrForbidden), errors.Is(err, softdelete.ErrWindowPassed):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, softdelete.ErrNotDeleted):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	json.Write(w, http.StatusOK, restoredTopic)
+}
@@ -4,19 +4,35 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/softdelete"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func NewService(repo *repo.Queries, pool db.Pool) Service {
-	return &svc{repo: repo, db: pool}
+func NewService(repo *repo.Queries, pool db.Pool, restoreWindow time.Duration) Service {
+	return &svc{repo: repo, db: pool, restoreWindow: restoreWindow}
 }
 
-func (s *svc) ListTopics(ctx context.Context) ([]repo.Topic, error) {
-	return s.repo.ListTopics(ctx)
+// ListTopics lists every topic along with whether the user subscribed to it and how many posts they have not seen yet
+func (s *svc) ListTopics(ctx context.Context, userID int64) ([]repo.ListTopicsForUserRow, error) {
+	return s.repo.ListTopicsForUser(ctx, userID)
+}
+
+// ListTopicsVersion tells what ListTopics would return for the user without building the listing
+func (s *svc) ListTopicsVersion(ctx context.Context, userID int64) (httpcache.Version, error) {
+	row, err := s.repo.GetTopicsVersion(ctx, userID)
+	if err != nil {
+		return httpcache.Version{}, err
+	}
+	return httpcache.NewVersion(row.LastModified.Time, row.TopicCount, row.PostCount, row.SubscriptionCount), nil
 }
 
 func (s *svc) CreateTopic(ctx context.Context, params repo.CreateTopicParams) (repo.Topic, error) {
@@ -53,7 +69,9 @@ func (s *svc) CreateTopic(ctx context.Context, params repo.CreateTopicParams) (r
 	return topic, nil
 }
 
-func (s *svc) UpdateTopic(ctx context.Context, params repo.UpdateTopicParams) (repo.Topic, error) {
+func (s *svc) UpdateTopic(ctx context.Context, req UpdateTopicRequest) (repo.Topic, error) {
+	params := req.UpdateTopicParams
+
 	// validate the params
 	if params.Name == "" {
 		return repo.Topic{}, fmt.Errorf("name is required")
@@ -66,11 +84,32 @@ func (s *svc) UpdateTopic(ctx context.Context, params repo.UpdateTopicParams) (r
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
+	// the row lock makes the version check and the update one step
+	current, err := qtx.GetTopicForUpdate(ctx, params.ID)
+	if err != nil {
+		return repo.Topic{}, err
+	}
+
+	if err := optimistic.Check(req.Expected, current.Version, current); err != nil {
+		return repo.Topic{}, err
+	}
+
 	topic, err := qtx.UpdateTopic(ctx, params)
 	if err != nil {
 		return repo.Topic{}, err
 	}
 
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "topic.update",
+		TargetType: "topic",
+		TargetID:   topic.ID,
+		Before:     current,
+		After:      topic,
+	})
+	if err != nil {
+		return repo.Topic{}, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return repo.Topic{}, err
 	}
@@ -78,7 +117,7 @@ func (s *svc) UpdateTopic(ctx context.Context, params repo.UpdateTopicParams) (r
 	return topic, nil
 }
 
-func (s *svc) DeleteTopic(ctx context.Context, id int64) (repo.Topic, error) {
+func (s *svc) DeleteTopic(ctx context.Context, id int64, userID int64) (repo.Topic, error) {
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return repo.Topic{}, err
@@ -86,7 +125,26 @@ func (s *svc) DeleteTopic(ctx context.Context, id int64) (repo.Topic, error) {
 	defer tx.Rollback(ctx)
 	qtx := s.repo.WithTx(tx)
 
-	topic, err := qtx.DeleteTopic(ctx, id)
+	current, err := qtx.GetTopic(ctx, id)
+	if err != nil {
+		return repo.Topic{}, err
+	}
+
+	topic, err := qtx.DeleteTopic(ctx, repo.DeleteTopicParams{
+		ID:        id,
+		DeletedBy: pgtype.Int8{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		return repo.Topic{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "topic.delete",
+		TargetType: "topic",
+		TargetID:   topic.ID,
+		Before:     current,
+		After:      topic,
+	})
 	if err != nil {
 		return repo.Topic{}, err
 	}
@@ -97,3 +155,52 @@ func (s *svc) DeleteTopic(ctx context.Context, id int64) (repo.Topic, error) {
 
 	return topic, nil
 }
+
+func (s *svc) RestoreTopic(ctx context.Context, id int64, userID int64, role string) (repo.Topic, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Topic{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	existing, err := qtx.GetTopic(ctx, id)
+	if err != nil {
+		return repo.Topic{}, err
+	}
+
+	if err := softdelete.CanRestore(existing.DeletedAt, existing.UserID, userID, role, s.restoreWindow); err != nil {
+		return repo.Topic{}, err
+	}
+
+	topic, err := qtx.RestoreTopic(ctx, id)
+	if err != nil {
+		return repo.Topic{}, err
+	}
+
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "topic.restore",
+		TargetType: "topic",
+		TargetID:   topic.ID,
+		Before:     existing,
+		After:      topic,
+	})
+	if err != nil {
+		return repo.Topic{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Topic{}, err
+	}
+
+	return topic, nil
+}
+
+func (s *svc) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return s.repo.PurgeDeletedTopics(ctx, pgtype.Timestamp{Time: cutoff, Valid: true})
+}
+
+// RepairCounters recounts the posts and latest post of every topic, returning how many had drifted
+func (s *svc) RepairCounters(ctx context.Context) (int64, error) {
+	return s.repo.RepairTopicCounters(ctx)
+}
@@ -2,9 +2,12 @@ package topics
 
 import (
 	"context"
+	"time"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/httpcache"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/optimistic"
 )
 
 type handler struct {
@@ -15,11 +18,26 @@ type svc struct {
 	// database
 	repo *repo.Queries
 	db   db.Pool
+
+	// how long the owner has to restore something they deleted
+	restoreWindow time.Duration
+}
+
+// UpdateTopicRequest is the body of the UpdateTopic API
+// Version is the version being edited, an If-Match header can be sent instead
+type UpdateTopicRequest struct {
+	repo.UpdateTopicParams
+	Version  *int32              `json:"version"`
+	Expected optimistic.Expected `json:"-"`
 }
 
 type Service interface {
-	ListTopics(ctx context.Context) ([]repo.Topic, error)
+	ListTopics(ctx context.Context, userID int64) ([]repo.ListTopicsForUserRow, error)
+	ListTopicsVersion(ctx context.Context, userID int64) (httpcache.Version, error)
 	CreateTopic(ctx context.Context, params repo.CreateTopicParams) (repo.Topic, error)
-	UpdateTopic(ctx context.Context, params repo.UpdateTopicParams) (repo.Topic, error)
-	DeleteTopic(ctx context.Context, id int64) (repo.Topic, error)
+	UpdateTopic(ctx context.Context, req UpdateTopicRequest) (repo.Topic, error)
+	DeleteTopic(ctx context.Context, id int64, userID int64) (repo.Topic, error)
+	RestoreTopic(ctx context.Context, id int64, userID int64, role string) (repo.Topic, error)
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	RepairCounters(ctx context.Context) (int64, error)
 }
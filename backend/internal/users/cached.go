@@ -0,0 +1,43 @@
+package users
+
+import (
+	"context"
+
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/cache"
+)
+
+// cachedService invalidates the cached post pages of a user, which show their role and followers
+type cachedService struct {
+	Service
+	cache *cache.Cache
+}
+
+func NewCachedService(service Service, c *cache.Cache) Service {
+	return &cachedService{Service: service, cache: c}
+}
+
+func (s *cachedService) UpdateUserRole(ctx context.Context, userID int64, role string, adminID int64) (repo.User, error) {
+	user, err := s.Service.UpdateUserRole(ctx, userID, role, adminID)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Author(userID))
+	}
+	return user, err
+}
+
+func (s *cachedService) Follow(ctx context.Context, followerID int64, followeeID int64) (repo.UserFollow, error) {
+	follow, err := s.Service.Follow(ctx, followerID, followeeID)
+	if err == nil {
+		// the followee's card holds both the follower count and whether the viewer follows them
+		s.cache.Invalidate(ctx, cache.Author(followeeID))
+	}
+	return follow, err
+}
+
+func (s *cachedService) Unfollow(ctx context.Context, followerID int64, followeeID int64) (repo.UserFollow, error) {
+	follow, err := s.Service.Unfollow(ctx, followerID, followeeID)
+	if err == nil {
+		s.cache.Invalidate(ctx, cache.Author(followeeID))
+	}
+	return follow, err
+}
@@ -1,10 +1,14 @@
 package users
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	appctx "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/context"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/json"
+	"github.com/jackc/pgx/v5"
 )
 
 // NewHandler
@@ -15,22 +19,187 @@ func NewHandler(service Service) *handler {
 	}
 }
 
+// writeError maps service errors onto the matching status code
+func writeError(w http.ResponseWriter, err error) {
+	log.Println(err)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "user not found", http.StatusNotFound)
+	case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrCannotChangeOwnRole), errors.Is(err, ErrCannotFollowSelf):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrAlreadyFollowing):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Function that handles the FetchUserByUsername API
 func (h *handler) FetchUserByUsername(w http.ResponseWriter, r *http.Request) {
+	// the username is taken from ?username=, older clients still send it in the body
 	var data struct {
 		Username string `json:"username"`
 	}
+	data.Username = r.URL.Query().Get("username")
+	if data.Username == "" {
+		if err := json.Read(r, &data); err != nil {
+			log.Println(err)
+			http.Error(w, err.Error(), json.StatusCode(err))
+			return
+		}
+	}
+
+	// Get user ID from context
+	viewerID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	profile, err := h.service.FetchUserByUsername(r.Context(), data.Username, viewerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, profile)
+}
+
+// Function that handles the UpdateUserRole API
+func (h *handler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UserID int64  `json:"user_id"`
+		Role   string `json:"role"`
+	}
 	if err := json.Read(r, &data); err != nil {
 		log.Println(err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	adminID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	user, err := h.service.FetchUserByUsername(r.Context(), data.Username)
+	user, err := h.service.UpdateUserRole(r.Context(), data.UserID, data.Role, adminID)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	json.Write(w, http.StatusOK, user)
 }
+
+// Function that handles the Follow API
+func (h *handler) Follow(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UserID int64 `json:"user_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	follow, err := h.service.Follow(r.Context(), userID, data.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, follow)
+}
+
+// Function that handles the Unfollow API
+func (h *handler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UserID int64 `json:"user_id"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := r.Context().Value(appctx.UserIDKey).(int64)
+	if !ok {
+		log.Println("userID not found in context")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	follow, err := h.service.Unfollow(r.Context(), userID, data.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, follow)
+}
+
+// Function that handles the ListFollowers API
+func (h *handler) ListFollowers(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UserID int64 `json:"user_id"`
+		Limit  int32 `json:"limit"`
+		Offset int32 `json:"offset"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	users, err := h.service.ListFollowers(r.Context(), repo.ListFollowersParams{
+		FolloweeID: data.UserID,
+		Limit:      data.Limit,
+		Offset:     data.Offset,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, users)
+}
+
+// Function that handles the ListFollowing API
+func (h *handler) ListFollowing(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		UserID int64 `json:"user_id"`
+		Limit  int32 `json:"limit"`
+		Offset int32 `json:"offset"`
+	}
+	if err := json.Read(r, &data); err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), json.StatusCode(err))
+		return
+	}
+
+	users, err := h.service.ListFollowing(r.Context(), repo.ListFollowingParams{
+		FollowerID: data.UserID,
+		Limit:      data.Limit,
+		Offset:     data.Offset,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.Write(w, http.StatusOK, users)
+}
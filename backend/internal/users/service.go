@@ -2,19 +2,124 @@ package users
 
 import (
 	"context"
+	"errors"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
+	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/audit"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func NewService(repo *repo.Queries, pool db.Pool) Service {
 	return &svc{repo: repo, db: pool}
 }
 
-func (s *svc) FetchUserByUsername(ctx context.Context, username string) (repo.User, error) {
-	user, err := s.repo.FetchUserByUsername(ctx, username)
+// FetchUserByUsername returns the public profile of a user, with follow counts and whether the viewer follows them
+func (s *svc) FetchUserByUsername(ctx context.Context, username string, viewerID int64) (repo.GetUserProfileRow, error) {
+	profile, err := s.repo.GetUserProfile(ctx, repo.GetUserProfileParams{
+		ViewerID: viewerID,
+		Username: username,
+	})
+	if err != nil {
+		return repo.GetUserProfileRow{}, err
+	}
+	return profile, nil
+}
+
+func (s *svc) UpdateUserRole(ctx context.Context, userID int64, role string, adminID int64) (repo.User, error) {
+	// validate the params
+	if role != RoleUser && role != RoleModerator && role != RoleAdmin {
+		return repo.User{}, ErrInvalidRole
+	}
+
+	// stops the last admin from locking everyone out by demoting themselves
+	if userID == adminID {
+		return repo.User{}, ErrCannotChangeOwnRole
+	}
+
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return repo.User{}, err
 	}
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	current, err := qtx.FetchUserByID(ctx, userID)
+	if err != nil {
+		return repo.User{}, err
+	}
+
+	user, err := qtx.UpdateUserRole(ctx, repo.UpdateUserRoleParams{
+		ID:   userID,
+		Role: role,
+	})
+	if err != nil {
+		return repo.User{}, err
+	}
+
+	// only the role is snapshotted so that password hashes never end up in the audit log
+	err = audit.Record(ctx, qtx, audit.Entry{
+		Action:     "user.role_change",
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     map[string]string{"role": current.Role},
+		After:      map[string]string{"role": user.Role},
+	})
+	if err != nil {
+		return repo.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.User{}, err
+	}
+
+	user.Password = ""
 	return user, nil
 }
+
+// Follow relies on the user_follows constraints to reject self follows and duplicates
+func (s *svc) Follow(ctx context.Context, followerID int64, followeeID int64) (repo.UserFollow, error) {
+	follow, err := s.repo.CreateUserFollow(ctx, repo.CreateUserFollowParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.CheckViolation:
+				return repo.UserFollow{}, ErrCannotFollowSelf
+			case pgerrcode.UniqueViolation:
+				return repo.UserFollow{}, ErrAlreadyFollowing
+			case pgerrcode.ForeignKeyViolation:
+				return repo.UserFollow{}, pgx.ErrNoRows
+			}
+		}
+		return repo.UserFollow{}, err
+	}
+
+	return follow, nil
+}
+
+func (s *svc) Unfollow(ctx context.Context, followerID int64, followeeID int64) (repo.UserFollow, error) {
+	return s.repo.DeleteUserFollow(ctx, repo.DeleteUserFollowParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+}
+
+func (s *svc) ListFollowers(ctx context.Context, params repo.ListFollowersParams) ([]repo.ListFollowersRow, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 50
+	}
+	return s.repo.ListFollowers(ctx, params)
+}
+
+func (s *svc) ListFollowing(ctx context.Context, params repo.ListFollowingParams) ([]repo.ListFollowingRow, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 50
+	}
+	return s.repo.ListFollowing(ctx, params)
+}
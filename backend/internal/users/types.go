@@ -2,11 +2,26 @@ package users
 
 import (
 	"context"
+	"errors"
 
 	repo "github.com/Sakthi-dev-tech/Gossip-With-Go/internal/adapters/postgresql/sqlc"
 	"github.com/Sakthi-dev-tech/Gossip-With-Go/internal/db"
 )
 
+// roles stored in users.role
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var (
+	ErrInvalidRole         = errors.New("role must be one of user, moderator or admin")
+	ErrCannotChangeOwnRole = errors.New("you cannot change your own role")
+	ErrCannotFollowSelf    = errors.New("you cannot follow yourself")
+	ErrAlreadyFollowing    = errors.New("you are already following this user")
+)
+
 type handler struct {
 	service Service
 }
@@ -18,5 +33,15 @@ type svc struct {
 }
 
 type Service interface {
-	FetchUserByUsername(ctx context.Context, params string) (repo.User, error)
+	FetchUserByUsername(ctx context.Context, username string, viewerID int64) (repo.GetUserProfileRow, error)
+	UpdateUserRole(ctx context.Context, userID int64, role string, adminID int64) (repo.User, error)
+	Follow(ctx context.Context, followerID int64, followeeID int64) (repo.UserFollow, error)
+	Unfollow(ctx context.Context, followerID int64, followeeID int64) (repo.UserFollow, error)
+	ListFollowers(ctx context.Context, params repo.ListFollowersParams) ([]repo.ListFollowersRow, error)
+	ListFollowing(ctx context.Context, params repo.ListFollowingParams) ([]repo.ListFollowingRow, error)
+}
+
+// IsModerator reports whether the role is allowed to moderate content, admins included
+func IsModerator(role string) bool {
+	return role == RoleModerator || role == RoleAdmin
 }
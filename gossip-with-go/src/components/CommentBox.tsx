@@ -6,6 +6,8 @@ import UpdateCommentModal from "./UpdateCommentModal";
 import DeleteCommentModal from "./DeleteCommentModal";
 import { getRelativeTime } from "../functions/TimeFormatter";
 import { authenticatedFetch } from "../functions/AuthenticatedFetch";
+import { putWithVersion } from "../functions/EditConflict";
+import { Comment } from "../types/Comments";
 
 interface CommentBoxProps {
   commentId: number;
@@ -13,6 +15,7 @@ interface CommentBoxProps {
   content: string;
   user_id: number;
   created_at: string;
+  version: number;
   isOwner?: boolean;
   refreshComments: () => void;
 }
@@ -22,6 +25,7 @@ export default function CommentBox({
   username,
   content,
   created_at,
+  version,
   isOwner = false,
   refreshComments,
 }: CommentBoxProps) {
@@ -29,16 +33,15 @@ export default function CommentBox({
   const [openDeleteModal, setOpenDeleteModal] = useState(false);
 
   const handleUpdate = async (id: number, updatedContent: string) => {
-    await authenticatedFetch(`${process.env.REACT_APP_API_URL}/updateComment`, {
-      method: "PUT",
-      headers: {
-        "Content-Type": "application/json",
-      },
-      body: JSON.stringify({
+    await putWithVersion<Comment>(
+      `${process.env.REACT_APP_API_URL}/updateComment`,
+      {
         "id": id,
         "content": updatedContent,
-      }),
-    });
+      },
+      version,
+      (current) => current.content
+    );
     refreshComments();
   };
 
@@ -10,6 +10,7 @@ import EditIcon from "@mui/icons-material/Edit";
 import DeleteIcon from "@mui/icons-material/Delete";
 import AccessTimeIcon from "@mui/icons-material/AccessTime";
 import ArrowForwardIcon from "@mui/icons-material/ArrowForward";
+import ChatBubbleOutlineIcon from "@mui/icons-material/ChatBubbleOutline";
 import { useNavigate } from "react-router";
 import { useState } from "react";
 import { jwtDecode } from "jwt-decode";
@@ -19,7 +20,9 @@ import CustomSnackbar from "./CustomSnackbar";
 import { getRelativeTime } from "../functions/TimeFormatter";
 import { getCookie } from "../functions/Cookies";
 import { authenticatedFetch } from "../functions/AuthenticatedFetch";
+import { putWithVersion } from "../functions/EditConflict";
 import { capitaliseWords } from "../functions/TextFormatter";
+import { Post } from "../types/Posts";
 
 // Interface for JWT payload
 interface JWTPayload {
@@ -35,6 +38,8 @@ interface PostCardProps {
   username: string;
   user_id: number;
   created_at: string;
+  comment_count: number;
+  version: number;
   topic_title?: string;
   topic_description?: string;
   onPostChanged?: () => void;
@@ -47,6 +52,8 @@ export default function PostCard({
   username,
   user_id,
   created_at,
+  comment_count,
+  version,
   topic_title,
   topic_description,
   onPostChanged,
@@ -83,22 +90,21 @@ export default function PostCard({
     updatedContent: string
   ) => {
     try {
-      const response = await authenticatedFetch(
+      const response = await putWithVersion<Post>(
         `${process.env.REACT_APP_API_URL}/updatePost`,
         {
-          method: "PUT",
-          headers: {
-            "Content-Type": "application/json",
-          },
-          body: JSON.stringify({
-            title: updatedTitle,
-            content: updatedContent,
-            id: id,
-          }),
-        }
+          title: updatedTitle,
+          content: updatedContent,
+          id: id,
+        },
+        version,
+        (current) => `${current.title}\n\n${current.content}`
       );
 
-      if (response.ok) {
+      if (response === null) {
+        // kept the other edit, show it instead of ours
+        onPostChanged?.();
+      } else if (response.ok) {
         setSnackbarSeverity("success");
         setSnackbarMessage("Post Updated Successfully!");
         onPostChanged?.();
@@ -280,6 +286,7 @@ export default function PostCard({
             sx={{
               display: "flex",
               alignItems: "center",
+              justifyContent: "space-between",
               pt: 2,
               borderTop: "1px solid rgba(255, 255, 255, 0.08)",
             }}
@@ -314,6 +321,17 @@ export default function PostCard({
             >
               Read full post
             </Button>
+            <Box sx={{ display: "flex", alignItems: "center", gap: 0.5 }}>
+              <ChatBubbleOutlineIcon
+                sx={{ fontSize: "0.95rem", color: "text.secondary" }}
+              />
+              <Typography
+                variant="caption"
+                sx={{ color: "text.secondary", fontWeight: 500 }}
+              >
+                {comment_count} {comment_count === 1 ? "comment" : "comments"}
+              </Typography>
+            </Box>
           </Box>
         </CardContent>
       </Card>
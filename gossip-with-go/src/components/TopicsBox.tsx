@@ -6,6 +6,8 @@ import { getCookie } from "../functions/Cookies";
 import { getRelativeTime } from "../functions/TimeFormatter";
 import { authenticatedFetch } from "../functions/AuthenticatedFetch";
 import { capitaliseWords } from "../functions/TextFormatter";
+import { putWithVersion } from "../functions/EditConflict";
+import { Topic } from "../types/Topics";
 import EditIcon from "@mui/icons-material/Edit";
 import DeleteIcon from "@mui/icons-material/Delete";
 import UpdateTopicModal from "./UpdateTopicModal";
@@ -16,9 +18,12 @@ interface TopicsBoxProps {
   title?: string;
   description?: string;
   createdAt?: string;
+  postCount?: number;
+  lastPostAt?: string | null;
   user_id: number;
   username: string;
   topicId?: number;
+  version?: number;
   onTopicChanged?: () => void;
 }
 
@@ -33,9 +38,12 @@ export default function TopicsBox({
   title,
   description,
   createdAt,
+  postCount = 0,
+  lastPostAt,
   user_id,
   username,
   topicId,
+  version = 1,
   onTopicChanged,
 }: TopicsBoxProps) {
   const navigate = useNavigate();
@@ -77,22 +85,23 @@ export default function TopicsBox({
     updatedDescription: string
   ) => {
     try {
-      const response = await authenticatedFetch(
+      const response = await putWithVersion<Topic>(
         `${process.env.REACT_APP_API_URL}/updateTopic`,
         {
-          method: "PUT",
-          body: JSON.stringify({
-            name: updatedTitle,
-            description: updatedDescription,
-            id: id,
-          }),
-          headers: {
-            "Content-Type": "application/json",
-          },
-        }
+          name: updatedTitle,
+          description: updatedDescription,
+          id: id,
+        },
+        version,
+        (current) => `${current.name}\n\n${current.description}`
       );
 
-      if (response.ok) {
+      if (response === null) {
+        // kept the other edit, show it instead of ours
+        if (onTopicChanged) {
+          onTopicChanged();
+        }
+      } else if (response.ok) {
         setSnackbarSeverity("success");
         setSnackbarMessage("Topic Updated Successfully!");
         if (onTopicChanged) {
@@ -273,6 +282,13 @@ export default function TopicsBox({
             Created by {username} •{" "}
             {createdAt ? getRelativeTime(createdAt) : "recently"}
           </Typography>
+          <Typography
+            variant="caption"
+            sx={{ color: "text.secondary", fontWeight: 500 }}
+          >
+            {postCount} {postCount === 1 ? "post" : "posts"}
+            {lastPostAt && ` • last post ${getRelativeTime(lastPostAt)}`}
+          </Typography>
         </CardContent>
       </Card>
 
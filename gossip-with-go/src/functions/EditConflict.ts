@@ -0,0 +1,44 @@
+import { authenticatedFetch } from "./AuthenticatedFetch";
+
+// Body of a 409, sent when someone else saved the same item first
+interface EditConflict<T> {
+  error: string;
+  version: number;
+  current: T;
+}
+
+// Sends an edit along with the version it was based on
+// On a conflict the user is shown the other copy and picks which one to keep,
+// null is returned when they keep the other copy and nothing was saved
+export async function putWithVersion<T>(
+  url: string,
+  body: Record<string, unknown>,
+  version: number,
+  describe: (current: T) => string
+): Promise<Response | null> {
+  const send = (version: number) =>
+    authenticatedFetch(url, {
+      method: "PUT",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify({ ...body, version: version }),
+    });
+
+  const response = await send(version);
+  if (response.status !== 409) {
+    return response;
+  }
+
+  const conflict: EditConflict<T> = await response.json();
+  const overwrite = window.confirm(
+    "Someone else edited this while you were working on it.\n\n" +
+      `Their version:\n${describe(conflict.current)}\n\n` +
+      "Press OK to replace it with yours, or Cancel to keep theirs."
+  );
+  if (!overwrite) {
+    return null;
+  }
+
+  return send(conflict.version);
+}
@@ -12,6 +12,7 @@ import CommentBox from "../components/CommentBox";
 import { useLocation, useNavigate } from "react-router-dom";
 import { getRelativeTime } from "../functions/TimeFormatter";
 import { Comment } from "../types/Comments";
+import { PostDetail } from "../types/Posts";
 import { useEffect, useState } from "react";
 import { getCookie } from "../functions/Cookies";
 import { jwtDecode } from "jwt-decode";
@@ -28,18 +29,19 @@ interface JWTPayload {
 
 export default function CommentsPage() {
   const [comments, setComments] = useState<Comment[]>([]);
+  const [detail, setDetail] = useState<PostDetail | null>(null);
   const location = useLocation();
   const navigate = useNavigate();
-  const {
-    title,
-    content,
-    username,
-    user_id,
-    created_at,
-    post_id,
-    topic_title,
-    topic_description,
-  } = location.state;
+  const { post_id } = location.state;
+
+  // what the previous page passed along is shown until the post itself has loaded
+  const title = detail?.post.title ?? location.state.title;
+  const content = detail?.post.content ?? location.state.content;
+  const username = detail?.post.username ?? location.state.username;
+  const created_at = detail?.post.created_at ?? location.state.created_at;
+  const topic_title = detail?.topic.name ?? location.state.topic_title;
+  const topic_description =
+    detail?.topic.description ?? location.state.topic_description;
 
   const [comment, setComment] = useState("");
   const [errorMessage, setErrorMessage] = useState("");
@@ -90,7 +92,7 @@ export default function CommentsPage() {
       if (response.ok) {
         setComment("");
         setSuccessMessage("Comment Posted Successfully!");
-        fetchComments();
+        fetchPost();
       } else {
         const errorData = await response.text();
         setErrorMessage(capitaliseWords(errorData || "Failed To Post Comment"));
@@ -103,17 +105,37 @@ export default function CommentsPage() {
     }
   };
 
-  const fetchComments = async () => {
+  // the post, its topic and the first comments come back in one response
+  // the browser revalidates it with the ETag, so reloading an unchanged post costs an empty 304
+  const fetchPost = async () => {
     try {
       const response = await authenticatedFetch(
-        `${process.env.REACT_APP_API_URL}/fetchComments`,
-        {
-          method: "POST",
-          headers: {
-            "Content-Type": "application/json",
-          },
-          body: JSON.stringify({ post_id: post_id }),
+        `${process.env.REACT_APP_API_URL}/posts/${post_id}`
+      );
+
+      if (response.ok) {
+        const data: PostDetail = await response.json();
+        setDetail(data);
+        setComments(data.comments);
+        if (data.has_more_comments) {
+          fetchComments();
         }
+      } else {
+        const errorData = await response.text();
+        setErrorMessage(capitaliseWords(errorData || "Failed To Fetch Post"));
+      }
+    } catch (error) {
+      const errMsg =
+        error instanceof Error ? error.message : "An Unexpected Error Occurred";
+      setErrorMessage(capitaliseWords(errMsg));
+      console.error("Error fetching post:", error);
+    }
+  };
+
+  const fetchComments = async () => {
+    try {
+      const response = await authenticatedFetch(
+        `${process.env.REACT_APP_API_URL}/posts/${post_id}/comments`
       );
 
       if (response.ok) {
@@ -138,7 +160,7 @@ export default function CommentsPage() {
   };
 
   useEffect(() => {
-    fetchComments();
+    fetchPost();
   }, [post_id]);
 
   return (
@@ -329,8 +351,9 @@ export default function CommentsPage() {
                 content={comment.content}
                 user_id={comment.user_id}
                 created_at={comment.created_at}
+                version={comment.version}
                 isOwner={comment.user_id === currentUserId}
-                refreshComments={fetchComments}
+                refreshComments={fetchPost}
               />
             ))}
         </Box>
@@ -26,21 +26,24 @@ export default function PostsPage() {
   const navigate = useNavigate();
   const { topicId, title, description } = location.state;
 
+  // a plain GET, so the browser revalidates it with the ETag and reuses its copy on a 304
   const fetchPosts = async () => {
     const response = await authenticatedFetch(
-      `${process.env.REACT_APP_API_URL}/fetchPosts`,
-      {
-        method: "POST",
-        headers: {
-          "Content-Type": "application/json",
-        },
-        body: JSON.stringify({ topic_id: topicId }),
-      }
+      `${process.env.REACT_APP_API_URL}/topics/${topicId}/posts`
     );
     const data = await response.json();
     if (data !== null) {
       setPosts(data);
     }
+
+    // the listing never writes, opening the topic is recorded separately for the unread counts
+    await authenticatedFetch(`${process.env.REACT_APP_API_URL}/markTopicRead`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify({ topic_id: topicId }),
+    });
   };
 
   useEffect(() => {
@@ -161,8 +164,10 @@ export default function PostsPage() {
             const filteredPosts = posts
               .sort(
                 (a, b) =>
+                  // pinned posts stay on top, the rest newest first
+                  Number(b.pinned) - Number(a.pinned) ||
                   new Date(b.created_at).getTime() -
-                  new Date(a.created_at).getTime()
+                    new Date(a.created_at).getTime()
               )
               .filter((post) => {
                 if (!searchQuery) return true;
@@ -196,6 +201,8 @@ export default function PostsPage() {
                       username={post.username}
                       user_id={post.user_id}
                       created_at={post.created_at}
+                      comment_count={post.comment_count}
+                      version={post.version}
                       onPostChanged={fetchPosts}
                       topic_title={title}
                       topic_description={description}
@@ -175,6 +175,9 @@ export default function TopicsPage() {
                       user_id={topic.user_id}
                       username={topic.username}
                       createdAt={topic.created_at}
+                      postCount={topic.post_count}
+                      lastPostAt={topic.last_post_at}
+                      version={topic.version}
                       onTopicChanged={fetchTopics}
                     />
                   </Grid>
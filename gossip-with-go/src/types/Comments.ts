@@ -4,4 +4,5 @@ export interface Comment {
     username: string;
     user_id: number;
     created_at: string;
+    version: number;
 }
\ No newline at end of file
@@ -1,3 +1,5 @@
+import { Comment } from "./Comments";
+
 export interface Post {
     id: number;
     title: string;
@@ -5,4 +7,29 @@ export interface Post {
     user_id: number;
     username: string;
     created_at: string;
-}
\ No newline at end of file
+    pinned: boolean;
+    comment_count: number;
+    last_activity_at: string;
+    version: number;
+}
+
+export interface PostDetail {
+    post: Post;
+    topic: {
+        id: number;
+        name: string;
+        description: string;
+        post_count: number;
+    };
+    author: {
+        id: number;
+        username: string;
+        role: string;
+        created_at: string;
+        post_count: number;
+        follower_count: number;
+        followed_by_viewer: boolean;
+    };
+    comments: Comment[];
+    has_more_comments: boolean;
+}
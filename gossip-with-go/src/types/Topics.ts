@@ -5,4 +5,7 @@ export interface Topic {
   user_id: number;
   username: string;
   created_at: string;
+  post_count: number;
+  last_post_at: string | null;
+  version: number;
 }